@@ -31,7 +31,7 @@ Optional:
 		p := mustPaths()
 
 		return withStateLock(p, func() error {
-			st, err := app.LoadState(p.State)
+			st, err := loadState(p)
 			if err != nil {
 				return err
 			}
@@ -83,9 +83,11 @@ Optional:
 						}
 						abs := filepath.Join(p.Downloads, e.Name())
 						if _, ok := ref[abs]; !ok {
-							actions = append(actions, fmt.Sprintf("remove orphan: %s", abs))
+							actions = append(actions, fmt.Sprintf("trash orphan: %s", abs))
 							if !cleanDryRun {
-								_ = os.Remove(abs)
+								if _, err := trashPath(p, abs, app.TrashDownload, "", commandLine(cmd, args)); err != nil {
+									return err
+								}
 							}
 						}
 					}
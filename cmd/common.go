@@ -1,17 +1,19 @@
 package cmd
 
 import (
-	"errors"
+	"bufio"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
-	"sort"
-	"strconv"
 	"strings"
 
 	"nmsmods/internal/app"
+	"nmsmods/internal/discover"
+	"nmsmods/internal/mods"
 	"nmsmods/internal/nms"
-	"nmsmods/internal/steam"
+	"nmsmods/internal/tui"
+	"nmsmods/pkg/nmsmods"
 )
 
 func mustPaths() *app.Paths {
@@ -25,36 +27,171 @@ func mustPaths() *app.Paths {
 	return p
 }
 
+// newManager returns the library Manager for this invocation: --profile/NMSMODS_PROFILE,
+// --game-target, progress on stdout, warnings on stderr and a terminal prompt for loose mod
+// name matches.
+func newManager(p *app.Paths, opts ...nmsmods.Option) *nmsmods.Manager {
+	base := []nmsmods.Option{
+		nmsmods.WithProfile(profileOverride),
+		nmsmods.WithGameTarget(gameTargetOverride),
+		nmsmods.WithProgress(func(s string) { fmt.Println(s) }),
+		nmsmods.WithWarnings(func(s string) { fmt.Fprintln(os.Stderr, "Warning: "+s) }),
+		nmsmods.WithConfirm(confirmModMatch),
+	}
+	return nmsmods.New(p, append(base, opts...)...)
+}
+
 func loadConfig(p *app.Paths) (app.Config, error) {
 	return app.LoadConfig(p.Config)
 }
 
+// loadState reads the full state from the active backend (state.json or state.db).
+func loadState(p *app.Paths) (app.State, error) {
+	return newManager(p).LoadState()
+}
+
+// saveState writes st to the active backend in one transaction.
+func saveState(p *app.Paths, st app.State) error {
+	return newManager(p).SaveState(st)
+}
+
 func detectGamePaths() ([]string, error) {
-	return steam.GuessNMSPaths()
+	cands, err := discover.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, 0, len(cands))
+	for _, c := range cands {
+		out = append(out, c.Path)
+	}
+	return out, nil
 }
 
-func requireGame(p *app.Paths) (*app.Config, *nms.Game, error) {
-	cfg, err := loadConfig(p)
+// validGameCandidates runs every discovery provider and keeps installs that pass
+// nms.ValidateGamePath, de-duplicated by validated path.
+func validGameCandidates() ([]discover.Candidate, error) {
+	cands, err := discover.FindAll()
 	if err != nil {
-		return nil, nil, err
+		return nil, err
+	}
+	out := []discover.Candidate{}
+	seen := map[string]struct{}{}
+	for _, c := range cands {
+		g, err := nms.ValidateGamePath(c.Path)
+		if err != nil {
+			continue
+		}
+		if _, ok := seen[g.Path]; ok {
+			continue
+		}
+		seen[g.Path] = struct{}{}
+		out = append(out, discover.Candidate{Path: g.Path, Source: c.Source})
 	}
-	if cfg.GamePath == "" {
-		return &cfg, nil, errors.New("game path not set. Use: nmsmods set-path <path> (or run: nmsmods where)")
+	return out, nil
+}
+
+// formatCandidates renders candidates as " - [source] path" lines for error messages.
+func formatCandidates(cands []discover.Candidate) string {
+	lines := make([]string, 0, len(cands))
+	for _, c := range cands {
+		lines = append(lines, fmt.Sprintf(" - [%s] %s", c.Source, c.Path))
 	}
-	game, err := nms.ValidateGamePath(cfg.GamePath)
+	return strings.Join(lines, "\n")
+}
+
+func requireGame(p *app.Paths) (*app.Config, *nms.Game, error) {
+	return newManager(p).RequireGame()
+}
+
+// gameForConfig resolves the game target and makes sure GAMEDATA/MODS exists. Call it again
+// after switching profiles: the new profile may be bound to another target.
+func gameForConfig(p *app.Paths, cfg *app.Config) (*nms.Game, error) {
+	return newManager(p).PrepareGame(*cfg)
+}
+
+// resolveGame validates the game target for the active profile (see app.ResolveTarget)
+// without creating GAMEDATA/MODS.
+func resolveGame(p *app.Paths, cfg *app.Config) (*nms.Game, error) {
+	return newManager(p).ResolveGame(*cfg)
+}
+
+// profileOverride selects the profile for this invocation only (the global --profile flag;
+// NMSMODS_PROFILE carries it into child processes). It never changes active_profile in config.
+var profileOverride = strings.TrimSpace(os.Getenv("NMSMODS_PROFILE"))
+
+// checkProfileOverride refuses a --profile or NMSMODS_PROFILE that is not a valid name or not
+// an existing profile, so a typo fails instead of installing into a new, empty profile.
+// Profiles are created with 'profile use' or 'profile set <name>'.
+func checkProfileOverride() error {
+	if profileOverride == "" {
+		return nil
+	}
+	if err := app.ValidateProfileName(profileOverride); err != nil {
+		return &usageError{err: err}
+	}
+	p, err := app.DefaultPathsWithOverride(homeOverride)
 	if err != nil {
-		return &cfg, nil, fmt.Errorf("invalid game path: %w", err)
+		return err
+	}
+	cfg, err := app.LoadConfig(p.Config)
+	if err != nil {
+		return err
 	}
-	if err := nms.EnsureModsDir(game); err != nil {
-		return &cfg, nil, err
+	if !app.ProfileExists(p, cfg, profileOverride) {
+		return usageErrorf("unknown profile %q (see: nmsmods profile list; create it with: nmsmods profile set %s)", profileOverride, profileOverride)
 	}
-	return &cfg, game, nil
+	return nil
 }
 
+// profileDeploys reports whether this invocation operates on the active profile. Only then do
+// changes reach GAMEDATA/MODS; other profiles (--profile) only change their store and state.
+func profileDeploys(cfg *app.Config) bool {
+	return activeProfile(cfg) == app.ActiveProfile(*cfg)
+}
+
+// printNotDeployed explains why a change to a non-active profile left the game alone.
+func printNotDeployed(profile string) {
+	fmt.Printf("Not deployed: profile %s is not active (it deploys on: nmsmods profile use %s)\n", profile, profile)
+}
+
+// activeProfile is the profile this invocation operates on.
 func activeProfile(cfg *app.Config) string {
+	if profileOverride != "" {
+		return profileOverride
+	}
 	return app.ActiveProfile(*cfg)
 }
 
+// resolveProfile returns the profile this invocation operates on, with its profile.json settings.
+func resolveProfile(p *app.Paths, cfg *app.Config) (app.ResolvedProfile, error) {
+	return app.ResolveProfile(p, *cfg, profileOverride)
+}
+
+// deployOptions applies the game target and the profile's deploy strategy.
+func deployOptions(p *app.Paths, profile string, game *nms.Game) mods.DeployOptions {
+	return newManager(p).DeployOptions(profile, game)
+}
+
+// deployInstalled deploys a freshly installed store folder unless the profile installs new
+// mods disabled (profile set --new-mods disabled) or is not the active profile. Reinstalls of a
+// mod the profile already had stay enabled. It returns the deployed path ("" when skipped) and
+// the enabled flag.
+func deployInstalled(p *app.Paths, cfg *app.Config, game *nms.Game, storePath, folder, id, profile string, prev app.ProfileInstall) (string, bool, error) {
+	return newManager(p).DeployInstalled(*cfg, game, storePath, folder, id, profile, prev)
+}
+
+// printDeployed reports where an install went, or why it was not deployed.
+func printDeployed(id, profile, deployed string, enabled bool) {
+	switch {
+	case deployed != "":
+		fmt.Println("Deployed to:", deployed)
+	case !enabled:
+		fmt.Printf("Not deployed: this profile installs new mods disabled (run: nmsmods enable %s)\n", id)
+	default:
+		printNotDeployed(profile)
+	}
+}
+
 func ensureActiveProfileDirs(p *app.Paths, cfg *app.Config) (string, error) {
 	prof := activeProfile(cfg)
 	if err := app.EnsureProfileDirs(p, prof); err != nil {
@@ -62,6 +199,35 @@ func ensureActiveProfileDirs(p *app.Paths, cfg *app.Config) (string, error) {
 	}
 	return prof, nil
 }
+
+// selfCommand runs nmsmods again with this invocation's profile override carried in NMSMODS_PROFILE.
+func selfCommand(self string, args ...string) *exec.Cmd {
+	c := exec.Command(self, args...)
+	c.Env = os.Environ()
+	if profileOverride != "" {
+		c.Env = append(c.Env, "NMSMODS_PROFILE="+profileOverride)
+	}
+	return c
+}
+
+// nmsmodsCommand is selfCommand for this executable, passing on --home and --game-target. Front
+// ends (tui, serve) run every action this way so it takes the state lock like any CLI call.
+func nmsmodsCommand(args ...string) *exec.Cmd {
+	exe, _ := os.Executable()
+	if exe == "" {
+		exe = os.Args[0]
+	}
+	// Global flags go first: args may end in "--" and positional arguments.
+	var global []string
+	if homeOverride != "" {
+		global = append(global, "--home="+homeOverride)
+	}
+	if gameTargetOverride != "" {
+		global = append(global, "--game-target="+gameTargetOverride)
+	}
+	return selfCommand(exe, append(global, args...)...)
+}
+
 func isZipFile(name string) bool {
 	l := strings.ToLower(name)
 	return strings.HasSuffix(l, ".zip")
@@ -72,34 +238,35 @@ func fileExists(p string) bool {
 	return err == nil
 }
 
-// sortedModIDs returns stable ordering for numeric indexes.
+// sortedModIDs returns the stable listing order of ids.
 func sortedModIDs(st app.State) []string {
-	ids := make([]string, 0, len(st.Mods))
-	for id := range st.Mods {
-		ids = append(ids, id)
-	}
-	sort.Strings(ids)
-	return ids
+	return nmsmods.SortedModIDs(st)
 }
 
-// resolveModArg allows commands to accept either an id (slug) or a numeric index from `downloads`.
+// resolveModArg resolves a mod argument (id, handle, nx:<mod id>, id prefix or display name;
+// see nmsmods.ResolveModConfirm), asking before it accepts a loose display-name match.
 func resolveModArg(arg string, st app.State) (string, error) {
-	// Prefer exact ID match (even if the ID is numeric), then fall back to index.
-	// This avoids ambiguous UX when users intentionally use numeric IDs (e.g. Nexus mod ids).
-	if _, ok := st.Mods[arg]; ok {
-		return arg, nil
-	}
-	if n, err := strconv.Atoi(arg); err == nil {
-		if n <= 0 {
-			return "", fmt.Errorf("invalid index: %d", n)
-		}
-		ids := sortedModIDs(st)
-		if n > len(ids) {
-			return "", fmt.Errorf("index out of range: %d (have %d)", n, len(ids))
-		}
-		return ids[n-1], nil
+	return nmsmods.ResolveModConfirm(st, arg, confirmModMatch)
+}
+
+// confirmModMatch asks on the terminal whether a loose display-name match is the mod meant.
+// Without a terminal, or with --output json, it declines and the error lists the match, so
+// scripts never act on a guess.
+func confirmModMatch(arg, id, name string) bool {
+	if outputFormat != outputText || !tui.IsTerminal(os.Stdin) || !tui.IsTerminal(os.Stderr) {
+		return false
+	}
+	label := id
+	if name != "" && name != id {
+		label = fmt.Sprintf("%s (%s)", id, name)
 	}
-	return "", fmt.Errorf("unknown id: %s (run: nmsmods downloads)", arg)
+	fmt.Fprintf(os.Stderr, "No mod %q; did you mean %s? [y/N] ", arg, label)
+	line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return true
+	}
+	return false
 }
 
 func joinPathFromState(root, rel string) string {
@@ -109,10 +276,5 @@ func joinPathFromState(root, rel string) string {
 // withStateLock ensures we don't corrupt config/state when multiple nmsmods processes run.
 // Wrap any command that writes config/state or deletes managed download assets.
 func withStateLock(p *app.Paths, fn func() error) error {
-	l, err := app.AcquireLock(p.Root)
-	if err != nil {
-		return err
-	}
-	defer l.Release()
-	return fn()
+	return newManager(p).Locked(fn)
 }
@@ -0,0 +1,244 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"nmsmods/internal/app"
+	"nmsmods/internal/nms"
+	"nmsmods/pkg/nmsmods"
+
+	"github.com/spf13/cobra"
+)
+
+// Dynamic shell completion. Completion functions run on every <TAB>: they read state without
+// the lock (app.PeekState), never write, and complete nothing when state is busy or unreadable.
+
+// completionEnv is what completion functions read: the data directory, the profile --profile
+// (or NMSMODS_PROFILE, or active_profile) names, and the state.
+type completionEnv struct {
+	paths   *app.Paths
+	cfg     app.Config
+	profile string
+	st      app.State
+}
+
+// loadCompletionEnv reads the data directory without creating it. Cobra has parsed the flags
+// of the command being completed (--home, --profile) but not run PersistentPreRunE. withState
+// also peeks at the state; completers that only need config and profiles skip it.
+func loadCompletionEnv(withState bool) (completionEnv, bool) {
+	p, err := app.DefaultPathsWithOverride(homeOverride)
+	if err != nil {
+		return completionEnv{}, false
+	}
+	cfg, err := app.LoadConfig(p.Config)
+	if err != nil {
+		return completionEnv{}, false
+	}
+	env := completionEnv{paths: p, cfg: cfg, profile: profileOverride}
+	if name := strings.TrimSpace(profileFlag); name != "" {
+		env.profile = name
+	}
+	if env.profile == "" {
+		env.profile = app.ActiveProfile(cfg)
+	}
+	if withState {
+		if env.st, err = app.PeekState(p); err != nil {
+			return completionEnv{}, false
+		}
+	}
+	return env, true
+}
+
+// modStatus describes a mod's state in profile for completion descriptions.
+func modStatus(me app.ModEntry, profile string) string {
+	pi := me.Installations[profile]
+	switch {
+	case pi.Installed && pi.Enabled:
+		return "enabled"
+	case pi.Installed:
+		return "installed, disabled"
+	case me.ZIP != "":
+		return "downloaded"
+	}
+	return "not installed"
+}
+
+// completeMods completes mod ids, and display names starting with what was typed, of the mods
+// keep accepts in the completion profile. multi allows several mod arguments; otherwise only
+// the first argument is completed.
+func completeMods(keep func(me app.ModEntry, pi app.ProfileInstall) bool, multi bool) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		if len(args) > 0 && !multi {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		env, ok := loadCompletionEnv(true)
+		if !ok {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return modCompletions(env, keep, args, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+func modCompletions(env completionEnv, keep func(me app.ModEntry, pi app.ProfileInstall) bool, args []string, toComplete string) []cobra.Completion {
+	out := []cobra.Completion{}
+	lower := strings.ToLower(toComplete)
+	for _, id := range nmsmods.SortedModIDs(env.st) {
+		if slices.Contains(args, id) {
+			continue
+		}
+		me := env.st.Mods[id]
+		if keep != nil && !keep(me, me.Installations[env.profile]) {
+			continue
+		}
+		desc := fmt.Sprintf("#%d %s", me.Handle, modStatus(me, env.profile))
+		name := me.DisplayName
+		if name != "" && name != id {
+			desc = name + " (" + desc + ")"
+		}
+		out = append(out, cobra.CompletionWithDesc(id, desc))
+		if name != "" && name != id && lower != "" && strings.HasPrefix(strings.ToLower(name), lower) && !slices.Contains(args, name) {
+			out = append(out, cobra.CompletionWithDesc(name, id+" ("+modStatus(me, env.profile)+")"))
+		}
+	}
+	return out
+}
+
+func installedIn(_ app.ModEntry, pi app.ProfileInstall) bool { return pi.Installed }
+
+// completeUninstall completes installed mods and folder names in GAMEDATA/MODS, tracked or not.
+func completeUninstall(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	env, ok := loadCompletionEnv(true)
+	if !ok {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var gameFolders []string
+	m := nmsmods.New(env.paths, nmsmods.WithProfile(env.profile), nmsmods.WithGameTarget(gameTargetOverride))
+	if game, err := m.ResolveGame(env.cfg); err == nil {
+		gameFolders, _ = nms.ListInstalledModFolders(game)
+	}
+	return uninstallCompletions(env, gameFolders, args, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// uninstallCompletions lists installed mods, then the folders of installed mods and the other
+// folders found in GAMEDATA/MODS (gameFolders).
+func uninstallCompletions(env completionEnv, gameFolders, args []string, toComplete string) []cobra.Completion {
+	out := modCompletions(env, installedIn, args, toComplete)
+	folders := map[string]string{} // folder -> description
+	for _, id := range nmsmods.SortedModIDs(env.st) {
+		if pi := env.st.Mods[id].Installations[env.profile]; pi.Installed && pi.Folder != "" {
+			folders[pi.Folder] = "folder of " + id
+		}
+	}
+	for _, f := range gameFolders {
+		if _, ok := folders[f]; !ok {
+			folders[f] = "untracked folder in GAMEDATA/MODS"
+		}
+	}
+	names := make([]string, 0, len(folders))
+	for f := range folders {
+		if !slices.Contains(args, f) {
+			names = append(names, f)
+		}
+	}
+	sort.Strings(names)
+	for _, f := range names {
+		out = append(out, cobra.CompletionWithDesc(f, folders[f]))
+	}
+	return out
+}
+
+// completeProfiles completes profile names, marking the active one.
+func completeProfiles(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeProfileFlag(cmd, args, toComplete)
+}
+
+func completeProfileFlag(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	env, ok := loadCompletionEnv(false)
+	if !ok {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return profileCompletions(env), cobra.ShellCompDirectiveNoFileComp
+}
+
+// profileCompletions lists the profiles with their descriptions. The profile active in config
+// is marked, whatever --profile says.
+func profileCompletions(env completionEnv) []cobra.Completion {
+	names, err := listProfiles(env.paths)
+	if err != nil {
+		return nil
+	}
+	out := make([]cobra.Completion, 0, len(names))
+	for _, n := range names {
+		desc := "profile"
+		if n == app.ActiveProfile(env.cfg) {
+			desc = "active profile"
+		}
+		if s, err := app.LoadProfileSettings(env.paths, n); err == nil && s.Description != "" {
+			desc += ": " + s.Description
+		}
+		out = append(out, cobra.CompletionWithDesc(n, desc))
+	}
+	return out
+}
+
+// completeGameDomains completes Nexus game domains: No Man's Sky's plus any in state.
+func completeGameDomains(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	env, _ := loadCompletionEnv(true)
+	return gameDomainCompletions(env), cobra.ShellCompDirectiveNoFileComp
+}
+
+func gameDomainCompletions(env completionEnv) []cobra.Completion {
+	counts := map[string]int{"nomanssky": 0}
+	for _, me := range env.st.Mods {
+		if me.Nexus != nil && me.Nexus.GameDomain != "" {
+			counts[me.Nexus.GameDomain]++
+		}
+	}
+	domains := make([]string, 0, len(counts))
+	for d := range counts {
+		domains = append(domains, d)
+	}
+	sort.Strings(domains)
+	out := make([]cobra.Completion, 0, len(domains))
+	for _, d := range domains {
+		out = append(out, cobra.CompletionWithDesc(d, fmt.Sprintf("%d tracked mod(s)", counts[d])))
+	}
+	return out
+}
+
+// isCompletionRequest reports whether cmd is cobra's hidden command behind <TAB>.
+func isCompletionRequest(cmd *cobra.Command) bool {
+	return cmd.Name() == cobra.ShellCompRequestCmd || cmd.Name() == cobra.ShellCompNoDescRequestCmd
+}
+
+func init() {
+	all := completeMods(nil, false)
+	installed := completeMods(installedIn, false)
+	installCmd.ValidArgsFunction = completeMods(func(me app.ModEntry, _ app.ProfileInstall) bool { return me.ZIP != "" }, false)
+	enableCmd.ValidArgsFunction = completeMods(func(_ app.ModEntry, pi app.ProfileInstall) bool { return pi.Installed && !pi.Enabled }, true)
+	disableCmd.ValidArgsFunction = completeMods(func(_ app.ModEntry, pi app.ProfileInstall) bool { return pi.Installed && pi.Enabled }, true)
+	reinstallCmd.ValidArgsFunction = completeMods(installedIn, true)
+	uninstallCmd.ValidArgsFunction = completeUninstall
+	verifyCmd.ValidArgsFunction = installed
+	infoCmd.ValidArgsFunction = all
+	noteCmd.ValidArgsFunction = all
+	tagAddCmd.ValidArgsFunction = all
+	tagRemoveCmd.ValidArgsFunction = all
+	tagListCmd.ValidArgsFunction = all
+
+	profileUseCmd.ValidArgsFunction = completeProfiles
+	profileSetCmd.ValidArgsFunction = completeProfiles
+}
+
+// registerFlagCompletions runs once every command and flag is defined (from registerCommands):
+// flags declared in other files' init functions do not exist yet when this file's init runs.
+func registerFlagCompletions(root *cobra.Command) {
+	_ = root.RegisterFlagCompletionFunc("profile", completeProfileFlag)
+	_ = nexusCmd.RegisterFlagCompletionFunc("game", completeGameDomains)
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"nmsmods/internal/app"
+
+	"github.com/spf13/cobra"
+)
+
+func completionTestEnv() completionEnv {
+	return completionEnv{profile: "default", st: app.State{Mods: map[string]app.ModEntry{
+		"better-flight": {Handle: 1, DisplayName: "Better Flight", ZIP: "bf.zip", Nexus: &app.NexusInfo{GameDomain: "nomanssky"},
+			Installations: map[string]app.ProfileInstall{"default": {Installed: true, Enabled: true, Folder: "BetterFlight"}}},
+		"fog": {Handle: 2, DisplayName: "fog", Nexus: &app.NexusInfo{GameDomain: "skyrim"},
+			Installations: map[string]app.ProfileInstall{"default": {Installed: true}, "vr": {Installed: true, Enabled: true}}},
+		"zipped": {Handle: 3, ZIP: "z.zip"},
+	}}}
+}
+
+func TestModCompletions(t *testing.T) {
+	env := completionTestEnv()
+	vr := env
+	vr.profile = "vr"
+	for _, tc := range []struct {
+		name       string
+		env        completionEnv
+		keep       func(app.ModEntry, app.ProfileInstall) bool
+		args       []string
+		toComplete string
+		want       []cobra.Completion
+	}{
+		{"all", env, nil, nil, "", []cobra.Completion{
+			"better-flight\tBetter Flight (#1 enabled)",
+			"fog\t#2 installed, disabled",
+			"zipped\t#3 downloaded",
+		}},
+		{"display names once typing", env, nil, nil, "bet", []cobra.Completion{
+			"better-flight\tBetter Flight (#1 enabled)",
+			"Better Flight\tbetter-flight (enabled)",
+			"fog\t#2 installed, disabled",
+			"zipped\t#3 downloaded",
+		}},
+		{"installed, skipping given args", env, installedIn, []string{"fog"}, "", []cobra.Completion{
+			"better-flight\tBetter Flight (#1 enabled)",
+		}},
+		{"status follows the profile", vr, installedIn, nil, "", []cobra.Completion{
+			"fog\t#2 enabled",
+		}},
+	} {
+		got := modCompletions(tc.env, tc.keep, tc.args, tc.toComplete)
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s:\n got %q\nwant %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestUninstallCompletions(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		gameFolders []string
+		args        []string
+		want        []cobra.Completion
+	}{
+		{"tracked only", nil, nil, []cobra.Completion{
+			"better-flight\tBetter Flight (#1 enabled)",
+			"fog\t#2 installed, disabled",
+			"BetterFlight\tfolder of better-flight",
+		}},
+		{"untracked game folders", []string{"BetterFlight", "Manual"}, []string{"fog"}, []cobra.Completion{
+			"better-flight\tBetter Flight (#1 enabled)",
+			"BetterFlight\tfolder of better-flight",
+			"Manual\tuntracked folder in GAMEDATA/MODS",
+		}},
+	} {
+		got := uninstallCompletions(completionTestEnv(), tc.gameFolders, tc.args, "")
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s:\n got %q\nwant %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestProfileAndGameDomainCompletions(t *testing.T) {
+	p := app.PathsFromRoot(t.TempDir())
+	for _, name := range []string{"default", "vr"} {
+		if err := app.EnsureProfileDirs(p, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := app.SaveProfileSettings(p, "vr", app.ProfileSettings{Description: "headset"}); err != nil {
+		t.Fatal(err)
+	}
+	env := completionEnv{paths: p, cfg: app.Config{ActiveProfile: "vr"}, profile: "default"}
+	want := []cobra.Completion{"default\tprofile", "vr\tactive profile: headset"}
+	if got := profileCompletions(env); !reflect.DeepEqual(got, want) {
+		t.Errorf("profiles:\n got %q\nwant %q", got, want)
+	}
+
+	want = []cobra.Completion{"nomanssky\t1 tracked mod(s)", "skyrim\t1 tracked mod(s)"}
+	if got := gameDomainCompletions(completionTestEnv()); !reflect.DeepEqual(got, want) {
+		t.Errorf("game domains:\n got %q\nwant %q", got, want)
+	}
+	want = []cobra.Completion{"nomanssky\t0 tracked mod(s)"}
+	if got := gameDomainCompletions(completionEnv{}); !reflect.DeepEqual(got, want) {
+		t.Errorf("game domains without state:\n got %q\nwant %q", got, want)
+	}
+}
@@ -7,9 +7,10 @@ import (
 )
 
 var completionCmd = &cobra.Command{
-	Use:   "completion [bash|zsh|fish|powershell]",
-	Short: "Generate shell completion scripts",
-	Args:  cobra.ExactArgs(1),
+	Use:         "completion [bash|zsh|fish|powershell]",
+	Short:       "Generate shell completion scripts",
+	Annotations: map[string]string{textOnlyAnnotation: "true"},
+	Args:        cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		shell := args[0]
 		switch shell {
@@ -2,70 +2,79 @@ package cmd
 
 import (
 	"fmt"
-	"path/filepath"
 
-	"nmsmods/internal/app"
-	"nmsmods/internal/mods"
+	"nmsmods/pkg/nmsmods"
 
 	"github.com/spf13/cobra"
 )
 
+var disableSel modSelectorFlags
+
 var disableCmd = &cobra.Command{
-	Use:   "disable <id-or-index>",
+	Use:   "disable <mod-or-glob>... | --all | --source/--health/--tag/--category/--outdated",
 	Short: "Disable a mod in the active profile (keeps it in the profile store, removes it from the game)",
-	Args:  cobra.ExactArgs(1),
+	Long: `Disable mods in the active profile (or --profile): they stay in the profile store and leave
+the game. Several mods and the selectors (see: nmsmods enable --help) run as one operation.`,
+	Args: cobra.ArbitraryArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		p := mustPaths()
-
-		return withStateLock(p, func() error {
-			cfg, game, err := requireGame(p)
-			if err != nil {
-				return err
-			}
-			profile, err := ensureActiveProfileDirs(p, cfg)
-			if err != nil {
-				return err
-			}
-
-			st, err := app.LoadState(p.State)
+		m := newManager(mustPaths(), commandLineOption(cmd, args))
+		if disableSel.batch(args) {
+			results, err := m.DisableMods(cmd.Context(), disableSel.selector(args))
 			if err != nil {
 				return err
 			}
-
-			id, err := resolveModArg(args[0], st)
-			if err != nil {
-				return err
-			}
-
-			me := st.Mods[id]
-			pi, ok := me.Installations[profile]
-			if !ok || !pi.Installed || pi.Folder == "" {
-				return fmt.Errorf("mod %s is not installed in profile %q", id, profile)
-			}
-			if !pi.Enabled {
-				fmt.Println("Already disabled:", id)
-				return nil
-			}
-
-			if err := mods.Undeploy(game.ModsDir, pi.Folder, id, profile); err != nil {
-				return err
-			}
-			pi.Enabled = false
-			pi.DeployedPath = ""
-			me.Installations[profile] = pi
-
-			// Legacy: mark not installed in game, but keep store in profile.
-			me.Installed = false
-			me.InstalledPath = ""
-
-			st.Mods[id] = me
-			if err := app.SaveState(p.State, st); err != nil {
-				return err
-			}
-
-			fmt.Println("Disabled:", id)
-			fmt.Println("Store:", filepath.Join(p.Root, filepath.FromSlash(pi.Store)))
+			setResult(results)
+			return printToggleBatch(cmd, results, false)
+		}
+		res, err := m.Disable(cmd.Context(), args[0])
+		if err != nil {
+			return err
+		}
+		setResult(res)
+		if res.Unchanged {
+			fmt.Println("Already disabled:", res.ID)
 			return nil
-		})
+		}
+		fmt.Println("Disabled:", res.ID)
+		fmt.Println("Store:", res.StorePath)
+		return nil
 	},
 }
+
+// printToggleBatch reports a batch enable or disable, one line per mod.
+func printToggleBatch(cmd *cobra.Command, results []nmsmods.ToggleResult, enable bool) error {
+	if len(results) == 0 {
+		fmt.Println("No mods matched.")
+		return nil
+	}
+	verb, already := "Disabled:", "Already disabled:"
+	if enable {
+		verb, already = "Enabled:", "Already enabled:"
+	}
+	var changed []nmsmods.ToggleResult
+	for _, r := range results {
+		if r.Unchanged {
+			fmt.Println(already, r.ID)
+			continue
+		}
+		changed = append(changed, r)
+		if r.DeployedPath != "" {
+			fmt.Println(verb, r.ID, "->", r.DeployedPath)
+		} else {
+			fmt.Println(verb, r.ID)
+		}
+	}
+	fmt.Printf("%d of %d mod(s) changed (profile: %s)\n", len(changed), len(results), results[0].Profile)
+	if enable && len(changed) > 0 {
+		if changed[0].DeployedPath == "" {
+			printNotDeployed(changed[0].Profile)
+		} else {
+			warnIfModsDisabled(cmd, changed[0].Game)
+		}
+	}
+	return nil
+}
+
+func init() {
+	disableSel.register(disableCmd)
+}
@@ -24,6 +24,7 @@ type doctorReport struct {
 	Staging             string   `json:"staging"`
 	ConfiguredGamePath  string   `json:"game_path,omitempty"`
 	ModsDir             string   `json:"mods_dir,omitempty"`
+	ModsDisabled        bool     `json:"mods_disabled,omitempty"`
 	DetectedGamePaths   []string `json:"detected_game_paths,omitempty"`
 	InstalledModFolders []string `json:"installed_mod_folders,omitempty"`
 	ManagedModFolders   []string `json:"managed_mod_folders,omitempty"`
@@ -123,6 +124,11 @@ var doctorCmd = &cobra.Command{
 				} else {
 					// Use Game.ModsDir from validator (no Root field in your Game struct)
 					rep.ModsDir = game.ModsDir
+					if game.ModsDisabled {
+						rep.ModsDisabled = true
+						rep.OK = false
+						rep.Issues = append(rep.Issues, nms.DisableModsFileName+" exists in GAMEDATA/MODS: the game ignores all mods (run: nmsmods game mods on)")
+					}
 
 					if err := nms.EnsureModsDir(game); err != nil {
 						rep.OK = false
@@ -141,7 +147,7 @@ var doctorCmd = &cobra.Command{
 			}
 
 			// State read
-			st, serr := app.LoadState(p.State)
+			st, serr := loadState(p)
 			if serr != nil {
 				rep.OK = false
 				rep.Issues = append(rep.Issues, fmt.Sprintf("failed to read state: %v", serr))
@@ -158,6 +164,9 @@ var doctorCmd = &cobra.Command{
 				fmt.Println("Staging:", rep.Staging)
 				fmt.Println("Game path:", rep.ConfiguredGamePath)
 				fmt.Println("Mods dir:", rep.ModsDir)
+				if rep.ModsDisabled {
+					fmt.Println("Mod loading: off (" + nms.DisableModsFileName + " present)")
+				}
 				if len(rep.DetectedGamePaths) > 0 {
 					fmt.Println("Detected game paths:")
 					for _, gp := range rep.DetectedGamePaths {
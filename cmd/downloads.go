@@ -3,21 +3,25 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
-	"path/filepath"
 
 	"nmsmods/internal/app"
 
 	"github.com/spf13/cobra"
 )
 
-var downloadsJSON bool
+var (
+	downloadsJSON   bool
+	downloadsFilter listFilterFlags
+)
 
 // downloadRow is the stable JSON shape for `nmsmods downloads --json`.
 type downloadRow struct {
-	Index int    `json:"index"`
-	ID    string `json:"id"`
+	Index  int    `json:"index"` // same as handle (kept for older scripts)
+	Handle int    `json:"handle"`
+	ID     string `json:"id"`
 
-	// Backward-compatible fields
+	// Backward-compatible fields. installed/folder/installed_at/installed_path describe the
+	// active profile's installation.
 	Installed    bool   `json:"installed"`
 	ZipPath      string `json:"zip_path"`
 	URL          string `json:"url,omitempty"`
@@ -28,10 +32,15 @@ type downloadRow struct {
 	Source        string         `json:"source,omitempty"`
 	DisplayName   string         `json:"display_name,omitempty"`
 	Nexus         *app.NexusInfo `json:"nexus,omitempty"`
+	Profile       string         `json:"profile,omitempty"`
+	Enabled       bool           `json:"enabled,omitempty"`
 	InstalledAt   string         `json:"installed_at,omitempty"`
 	InstalledPath string         `json:"installed_path,omitempty"`
 	Health        string         `json:"health,omitempty"`
 	SHA256        string         `json:"sha256,omitempty"`
+	Tags          []string       `json:"tags,omitempty"`
+	Notes         string         `json:"notes,omitempty"`
+	Category      string         `json:"category,omitempty"` // Nexus mod category
 
 	// Convenience
 	ZipRel string `json:"zip_rel,omitempty"`
@@ -39,15 +48,29 @@ type downloadRow struct {
 
 var downloadsCmd = &cobra.Command{
 	Use:   "downloads",
-	Short: "List downloaded mods tracked in state.json (with numeric indices)",
+	Short: "List downloaded mods tracked in state.json (with numeric handles)",
+	Long: `List downloaded mods tracked in state.json with their handles: permanent numbers, given
+when a mod is first recorded and never reused, accepted wherever a mod id is.
+
+--tag and --category keep only matching mods; --group-by tag|category groups the text
+listing. JSON rows carry tags and category.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := downloadsFilter.validate(); err != nil {
+			return err
+		}
 		p := mustPaths()
-		st, err := app.LoadState(p.State)
+		all, err := newManager(p).ListMods(cmd.Context())
 		if err != nil {
 			return err
 		}
+		list := all[:0:0]
+		for _, ms := range all {
+			if downloadsFilter.keep(ms.Entry) {
+				list = append(list, ms)
+			}
+		}
 
-		if len(st.Mods) == 0 {
+		if len(list) == 0 {
 			if downloadsJSON {
 				fmt.Fprintln(cmd.OutOrStdout(), "[]")
 				return nil
@@ -56,32 +79,32 @@ var downloadsCmd = &cobra.Command{
 			return nil
 		}
 
-		ids := sortedModIDs(st)
-
 		if downloadsJSON {
-			out := make([]downloadRow, 0, len(ids))
-			for i, id := range ids {
-				me := st.Mods[id]
-				zipAbs := ""
-				if me.ZIP != "" {
-					zipAbs = filepath.Join(p.Root, filepath.FromSlash(me.ZIP))
-				}
+			out := make([]downloadRow, 0, len(list))
+			for _, ms := range list {
+				me, pi := ms.Entry, ms.Install
 				out = append(out, downloadRow{
-					Index:        i + 1,
-					ID:           id,
-					Installed:    me.Installed,
-					ZipPath:      zipAbs,
+					Index:        ms.Handle,
+					Handle:       ms.Handle,
+					ID:           ms.ID,
+					Installed:    pi.Installed,
+					ZipPath:      ms.ZIPPath,
 					URL:          me.URL,
-					Folder:       me.Folder,
+					Folder:       pi.Folder,
 					DownloadedAt: me.DownloadedAt,
 
 					Source:        me.Source,
 					DisplayName:   me.DisplayName,
 					Nexus:         me.Nexus,
-					InstalledAt:   me.InstalledAt,
-					InstalledPath: me.InstalledPath,
+					Profile:       ms.Profile,
+					Enabled:       pi.Enabled,
+					InstalledAt:   pi.InstalledAt,
+					InstalledPath: pi.DeployedPath,
 					Health:        me.Health,
 					SHA256:        me.SHA256,
+					Tags:          me.Tags,
+					Notes:         me.Notes,
+					Category:      me.Category(),
 
 					ZipRel: me.ZIP,
 				})
@@ -91,26 +114,22 @@ var downloadsCmd = &cobra.Command{
 			return nil
 		}
 
-		for i, id := range ids {
-			me := st.Mods[id]
-			zipAbs := ""
-			if me.ZIP != "" {
-				zipAbs = filepath.Join(p.Root, filepath.FromSlash(me.ZIP))
-			}
-
+		lines := make([]listLine, 0, len(list))
+		for _, ms := range list {
 			// Keep old single-line format, but add a tiny hint when available.
 			// Example: [1] foo installed=false zip=/... source=nexus
-			if me.Source != "" {
-				fmt.Fprintf(cmd.OutOrStdout(), "[%d] %s\tinstalled=%v\tzip=%s\tsource=%s\n", i+1, id, me.Installed, zipAbs, me.Source)
-			} else {
-				fmt.Fprintf(cmd.OutOrStdout(), "[%d] %s\tinstalled=%v\tzip=%s\n", i+1, id, me.Installed, zipAbs)
+			text := fmt.Sprintf("[%d] %s\tinstalled=%v\tzip=%s", ms.Handle, ms.ID, ms.Install.Installed, ms.ZIPPath)
+			if ms.Entry.Source != "" {
+				text += "\tsource=" + ms.Entry.Source
 			}
+			lines = append(lines, listLine{groups: downloadsFilter.groups(ms.Entry), text: text})
 		}
-
+		printListLines(cmd.OutOrStdout(), downloadsFilter.groupBy, lines)
 		return nil
 	},
 }
 
 func init() {
 	downloadsCmd.Flags().BoolVar(&downloadsJSON, "json", false, "Output in JSON format")
+	downloadsFilter.register(downloadsCmd)
 }
@@ -2,80 +2,49 @@ package cmd
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
-
-	"nmsmods/internal/app"
-	"nmsmods/internal/mods"
 
 	"github.com/spf13/cobra"
 )
 
+var enableSel modSelectorFlags
+
 var enableCmd = &cobra.Command{
-	Use:   "enable <id-or-index>",
+	Use:   "enable <mod-or-glob>... | --all | --source/--health/--tag/--category/--outdated",
 	Short: "Enable a mod in the active profile (deploys it to the game MODS directory)",
-	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		p := mustPaths()
-
-		return withStateLock(p, func() error {
-			cfg, game, err := requireGame(p)
-			if err != nil {
-				return err
-			}
-			profile, err := ensureActiveProfileDirs(p, cfg)
-			if err != nil {
-				return err
-			}
-
-			st, err := app.LoadState(p.State)
-			if err != nil {
-				return err
-			}
-
-			id, err := resolveModArg(args[0], st)
-			if err != nil {
-				return err
-			}
-
-			me := st.Mods[id]
-			pi, ok := me.Installations[profile]
-			if !ok || !pi.Installed || pi.Folder == "" {
-				return fmt.Errorf("mod %s is not installed in profile %q", id, profile)
-			}
-			if pi.Enabled {
-				fmt.Println("Already enabled:", id)
-				return nil
-			}
-
-			storeAbs := filepath.Join(p.Root, filepath.FromSlash(pi.Store))
-			if _, err := os.Stat(storeAbs); err != nil {
-				return fmt.Errorf("stored mod folder not found: %s", storeAbs)
-			}
+	Long: `Enable mods in the active profile (or --profile) and deploy them to the game.
 
-			deployed, err := mods.Deploy(storeAbs, game.ModsDir, pi.Folder, id, profile)
+Several ids, handles or globs on ids ('better-*') and the selectors --all, --source, --health,
+--tag, --category and --outdated run as one operation: one lock, one history entry (one undo)
+and one redeploy of the profile at the end.`,
+	Args: cobra.ArbitraryArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		m := newManager(mustPaths(), commandLineOption(cmd, args))
+		if enableSel.batch(args) {
+			results, err := m.EnableMods(cmd.Context(), enableSel.selector(args))
 			if err != nil {
 				return err
 			}
-
-			pi.Enabled = true
-			pi.DeployedPath = deployed
-			me.Installations[profile] = pi
-
-			// Legacy best-effort.
-			me.Installed = true
-			me.InstalledPath = deployed
-			me.InstalledAt = pi.InstalledAt
-			me.Folder = pi.Folder
-
-			st.Mods[id] = me
-			if err := app.SaveState(p.State, st); err != nil {
-				return err
-			}
-
-			fmt.Println("Enabled:", id)
-			fmt.Println("Deployed to:", deployed)
+			setResult(results)
+			return printToggleBatch(cmd, results, true)
+		}
+		res, err := m.Enable(cmd.Context(), args[0])
+		if err != nil {
+			return err
+		}
+		setResult(res)
+		if res.Unchanged {
+			fmt.Println("Already enabled:", res.ID)
 			return nil
-		})
+		}
+		fmt.Println("Enabled:", res.ID)
+		printDeployed(res.ID, res.Profile, res.DeployedPath, true)
+		if res.DeployedPath != "" {
+			warnIfModsDisabled(cmd, res.Game)
+		}
+		return nil
 	},
 }
+
+func init() {
+	enableSel.register(enableCmd)
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"nmsmods/pkg/nmsmods"
+
+	"github.com/spf13/cobra"
+)
+
+var fsckFix bool
+var fsckJSON bool
+
+var fsckCmd = &cobra.Command{
+	Use:   "fsck",
+	Short: "Cross-check state, downloads, profile stores and GAMEDATA/MODS (optionally repair)",
+	Long: `fsck compares state.json with downloads/, the profile stores and the managed markers in
+GAMEDATA/MODS and reports every inconsistency it finds.
+
+With --fix, safe repairs are applied: dangling references are cleared, stores are rebuilt from a
+matching deployed copy, missing deployments are redeployed, and orphan files go to the trash.
+Folders nmsmods does not own are never touched. Repairs are recorded in history (nmsmods undo).`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// fsck checks the whole state against the configured active profile; --profile does not apply.
+		rep, err := newManager(mustPaths(), commandLineOption(cmd, args)).Fsck(cmd.Context(), fsckFix)
+		if err != nil {
+			return err
+		}
+		if fsckJSON {
+			// stable JSON shape: nmsmods.FsckReport
+			b, _ := json.MarshalIndent(rep, "", "  ")
+			fmt.Fprintln(cmd.OutOrStdout(), string(b))
+		} else {
+			printFsckReport(cmd, rep)
+		}
+		if !rep.OK {
+			return fmt.Errorf("fsck found issues")
+		}
+		return nil
+	},
+}
+
+func printFsckReport(cmd *cobra.Command, rep nmsmods.FsckReport) {
+	out := cmd.OutOrStdout()
+	if len(rep.Issues) == 0 {
+		fmt.Fprintln(out, "No inconsistencies found.")
+		return
+	}
+	for _, is := range rep.Issues {
+		who := ""
+		if is.ModID != "" {
+			who = " " + is.ModID
+			if is.Profile != "" {
+				who += " (" + is.Profile + ")"
+			}
+		}
+		fmt.Fprintf(out, "%-7s %s%s: %s\n", is.Severity, is.Kind, who, is.Message)
+		switch {
+		case is.Fixed:
+			fmt.Fprintln(out, "        fixed:", is.Repair)
+		case is.FixError != "":
+			fmt.Fprintln(out, "        repair failed:", is.FixError)
+		case is.Repair != "" && !rep.Fix:
+			fmt.Fprintln(out, "        --fix would:", is.Repair)
+		}
+	}
+	if !rep.Fix {
+		fmt.Fprintln(out, "Run 'nmsmods fsck --fix' to apply the repairs listed above.")
+	}
+}
+
+func init() {
+	fsckCmd.Flags().BoolVar(&fsckFix, "fix", false, "Apply safe automatic repairs")
+	fsckCmd.Flags().BoolVar(&fsckJSON, "json", false, "Output in JSON format")
+}
@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"nmsmods/internal/nms"
+	"nmsmods/internal/nms/psarc"
+
+	"github.com/spf13/cobra"
+)
+
+var gameCmd = &cobra.Command{
+	Use:   "game",
+	Short: "Inspect the game installation (PCBANKS archives, base-game files, mod loading switch)",
+}
+
+var gameModsCmd = &cobra.Command{
+	Use:   "mods [on|off]",
+	Short: "Show or toggle global mod loading (DISABLEMODS.TXT) without undeploying anything",
+	Long: `The game ignores everything in GAMEDATA/MODS while GAMEDATA/MODS/DISABLEMODS.TXT exists.
+
+- nmsmods game mods       show the current state
+- nmsmods game mods off   create DISABLEMODS.TXT (quickly test vanilla)
+- nmsmods game mods on    remove DISABLEMODS.TXT
+
+Deployed folders and state.json are not touched.`,
+	Args:      cobra.MaximumNArgs(1),
+	ValidArgs: []string{"on", "off"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p := mustPaths()
+		if len(args) == 0 {
+			_, game, err := requireGame(p)
+			if err != nil {
+				return err
+			}
+			if game.ModsDisabled {
+				fmt.Fprintln(cmd.OutOrStdout(), "Mod loading: off ("+nms.DisableModsFileName+" present)")
+			} else {
+				fmt.Fprintln(cmd.OutOrStdout(), "Mod loading: on")
+			}
+			return nil
+		}
+
+		var enabled bool
+		switch strings.ToLower(args[0]) {
+		case "on":
+			enabled = true
+		case "off":
+			enabled = false
+		default:
+			return fmt.Errorf("expected on or off, got %q", args[0])
+		}
+
+		return withStateLock(p, func() error {
+			_, game, err := requireGame(p)
+			if err != nil {
+				return err
+			}
+			changed, err := nms.SetModsEnabled(game, enabled)
+			if err != nil {
+				return err
+			}
+			state := "off"
+			if enabled {
+				state = "on"
+			}
+			if !changed {
+				fmt.Fprintln(cmd.OutOrStdout(), "Mod loading already "+state)
+				return nil
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), "Mod loading: "+state)
+			return nil
+		})
+	},
+}
+
+// warnIfModsDisabled reminds users that deployed mods won't load while DISABLEMODS.TXT exists.
+func warnIfModsDisabled(cmd *cobra.Command, game *nms.Game) {
+	if game != nil && game.ModsDisabled {
+		fmt.Fprintln(cmd.ErrOrStderr(), "Warning: "+nms.DisableModsFileName+" exists in GAMEDATA/MODS; the game will ignore all mods (run: nmsmods game mods on)")
+	}
+}
+
+var gameBanksJSON bool
+
+type gameBankRow struct {
+	Archive     string `json:"archive"`
+	Compression string `json:"compression,omitempty"`
+	Files       int    `json:"files"`
+	Error       string `json:"error,omitempty"`
+}
+
+var gameBanksCmd = &cobra.Command{
+	Use:   "banks",
+	Short: "List the PCBANKS .pak archives and how many files each contains",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p := mustPaths()
+		_, game, err := requireGame(p)
+		if err != nil {
+			return err
+		}
+		paks, err := nms.ListBankArchives(game)
+		if err != nil {
+			return err
+		}
+		out := make([]gameBankRow, 0, len(paks))
+		for _, pk := range paks {
+			row := gameBankRow{Archive: filepath.Base(pk)}
+			a, err := psarc.Open(pk)
+			if err != nil {
+				row.Error = err.Error()
+			} else {
+				row.Compression = a.Compression()
+				row.Files = len(a.Files())
+				_ = a.Close()
+			}
+			out = append(out, row)
+		}
+
+		if gameBanksJSON {
+			b, _ := json.MarshalIndent(out, "", "  ")
+			fmt.Fprintln(cmd.OutOrStdout(), string(b))
+			return nil
+		}
+		for _, r := range out {
+			if r.Error != "" {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s\terror: %s\n", r.Archive, r.Error)
+				continue
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s\t%d files\t%s\n", r.Archive, r.Files, r.Compression)
+		}
+		return nil
+	},
+}
+
+var gameFilesBank string
+
+var gameFilesCmd = &cobra.Command{
+	Use:   "files [filter]",
+	Short: "List base-game files inside the PCBANKS archives (optionally filtered by substring)",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p := mustPaths()
+		_, game, err := requireGame(p)
+		if err != nil {
+			return err
+		}
+		filter := ""
+		if len(args) == 1 {
+			filter = strings.ToUpper(args[0])
+		}
+		paks, err := nms.ListBankArchives(game)
+		if err != nil {
+			return err
+		}
+		for _, pk := range paks {
+			if gameFilesBank != "" && !strings.EqualFold(filepath.Base(pk), gameFilesBank) {
+				continue
+			}
+			a, err := psarc.Open(pk)
+			if err != nil {
+				fmt.Fprintln(cmd.ErrOrStderr(), "skip:", err)
+				continue
+			}
+			for _, f := range a.Files() {
+				if filter != "" && !strings.Contains(strings.ToUpper(f), filter) {
+					continue
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\n", filepath.Base(pk), f)
+			}
+			_ = a.Close()
+		}
+		return nil
+	},
+}
+
+func init() {
+	gameBanksCmd.Flags().BoolVar(&gameBanksJSON, "json", false, "Output in JSON format")
+	gameFilesCmd.Flags().StringVar(&gameFilesBank, "bank", "", "Only list files from this archive (e.g. NMSARC.globals.pak)")
+	gameCmd.AddCommand(gameModsCmd)
+	gameCmd.AddCommand(gameBanksCmd)
+	gameCmd.AddCommand(gameFilesCmd)
+}
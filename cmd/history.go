@@ -0,0 +1,347 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"nmsmods/internal/app"
+	"nmsmods/internal/mods"
+	"nmsmods/pkg/nmsmods"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// historyRecorder is the library transaction: TrashStore records store folders an operation
+// moves to the trash so undo can restore them.
+type historyRecorder = nmsmods.Tx
+
+// withHistory runs fn under the state lock and appends an operation to history.jsonl with
+// before/after snapshots of every mod entry fn changed.
+func withHistory(p *app.Paths, cmd *cobra.Command, args []string, fn func(h *historyRecorder) error) error {
+	return newManager(p).Update(commandLine(cmd, args), fn)
+}
+
+// commandLineOption makes library operations record this invocation in history.
+func commandLineOption(cmd *cobra.Command, args []string) nmsmods.Option {
+	return nmsmods.WithCommandLine(commandLine(cmd, args))
+}
+
+// commandLine renders the invocation (path, args and explicitly set flags) for history.
+func commandLine(cmd *cobra.Command, args []string) string {
+	parts := append([]string{cmd.CommandPath()}, args...)
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		switch f.Name {
+		case "output", "verbose", "log-format":
+			return // presentation only
+		}
+		parts = append(parts, "--"+f.Name+"="+f.Value.String())
+	})
+	return strings.Join(parts, " ")
+}
+
+// replayHistory undoes (undo=true) or redoes one operation: it restores mod entries and store
+// folders, switches the active profile back if needed and redeploys.
+// It returns the store changes to record on the undo/redo entry.
+func replayHistory(p *app.Paths, op app.HistoryEntry, undo bool, displaced map[string]string) ([]app.StoreChange, error) {
+	// Undo/redo restore the configured profile and its deployment, never a --profile override.
+	profileOverride = ""
+	cfg, game, err := requireGame(p)
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureGameNotRunning(); err != nil {
+		return nil, err
+	}
+	st, err := loadState(p)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the trash and the entries before touching anything so a half-applied replay
+	// cannot happen. Entries changed since by commands history does not record (nexus pin,
+	// rmdownload, Nexus download metadata) would be lost by undo or revived by redo.
+	for _, sc := range op.Stores {
+		from := displaced[sc.Store]
+		if undo {
+			from = sc.Trash
+		}
+		if from != "" && !fileExists(joinPathFromState(p.Root, from)) {
+			return nil, fmt.Errorf("store %s is no longer in the trash (was it emptied?)", sc.Store)
+		}
+	}
+	for _, c := range op.Changes {
+		want, verb := c.After, "undo"
+		if !undo {
+			want, verb = c.Before, "redo"
+		}
+		var cur *app.ModEntry
+		if me, ok := st.Mods[c.ID]; ok {
+			cur = &me
+		}
+		if !app.SameModEntry(cur, want) {
+			return nil, fmt.Errorf("%s has changed since this operation by a command not recorded in history (e.g. nexus pin, rm-download or nexus download-nxm); refusing to %s it", c.ID, verb)
+		}
+	}
+
+	// Undeploy what the current entries have in the game; DeployProfile only knows
+	// about entries that remain enabled after the restore.
+	for _, c := range op.Changes {
+		for prof, pi := range st.Mods[c.ID].Installations {
+			if pi.Enabled && pi.Folder != "" {
+				if err := mods.Undeploy(game.ModsDir, pi.Folder, c.ID, prof); err != nil {
+					return nil, fmt.Errorf("failed to undeploy %s (%s): %w", c.ID, prof, err)
+				}
+			}
+		}
+	}
+
+	for _, c := range op.Changes {
+		target := c.After
+		if undo {
+			target = c.Before
+		}
+		if target == nil {
+			delete(st.Mods, c.ID)
+			continue
+		}
+		me := *target
+		if cur, ok := st.Mods[c.ID]; ok && cur.Handle != 0 {
+			me.Handle = cur.Handle // handles are permanent, even across snapshots taken before v5
+		}
+		st.Mods[c.ID] = me
+	}
+
+	recorded := []app.StoreChange{}
+	if undo {
+		for i := len(op.Stores) - 1; i >= 0; i-- {
+			sc := op.Stores[i]
+			storeAbs := joinPathFromState(p.Root, sc.Store)
+			if fileExists(storeAbs) {
+				it, err := trashPath(p, storeAbs, app.TrashStore, "", "undo "+op.Command)
+				if err != nil {
+					return nil, err
+				}
+				sc.Displaced = app.RelToRoot(p, it.PayloadPath(p))
+			}
+			if sc.Trash != "" {
+				if err := app.RestoreTrashAt(p, joinPathFromState(p.Root, sc.Trash), storeAbs); err != nil {
+					return nil, fmt.Errorf("failed to restore %s from trash: %w", sc.Store, err)
+				}
+			}
+			recorded = append(recorded, sc)
+		}
+	} else {
+		for _, sc := range op.Stores {
+			storeAbs := joinPathFromState(p.Root, sc.Store)
+			if fileExists(storeAbs) {
+				// Back to where the operation trashed it, so undoing it again finds it.
+				meta := app.TrashItem{Kind: app.TrashStore, Command: "redo " + op.Command}
+				if sc.Trash != "" {
+					if _, err := app.ReturnToTrash(p, storeAbs, joinPathFromState(p.Root, sc.Trash), meta); err != nil {
+						return nil, err
+					}
+				} else if _, err := trashPath(p, storeAbs, app.TrashStore, "", meta.Command); err != nil {
+					return nil, err
+				}
+			}
+			if d := displaced[sc.Store]; d != "" {
+				if err := app.RestoreTrashAt(p, joinPathFromState(p.Root, d), storeAbs); err != nil {
+					return nil, fmt.Errorf("failed to restore %s from trash: %w", sc.Store, err)
+				}
+			}
+			recorded = append(recorded, app.StoreChange{Store: sc.Store, Trash: sc.Trash})
+		}
+	}
+
+	profile := op.ProfileAfter
+	if undo {
+		profile = op.ProfileBefore
+	}
+	if profile != "" && profile != app.ActiveProfile(*cfg) {
+		cfg.ActiveProfile = profile
+		if err := app.SaveConfig(p.Config, *cfg); err != nil {
+			return nil, err
+		}
+		if err := app.EnsureProfileDirs(p, profile); err != nil {
+			return nil, err
+		}
+		// The restored profile may be bound to another game target.
+		if game, err = gameForConfig(p, cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := saveState(p, st); err != nil {
+		return nil, err
+	}
+	if err := newManager(p).DeployProfile(*cfg, game); err != nil {
+		return nil, err
+	}
+	return recorded, nil
+}
+
+var historyJSON bool
+var historyLimit int
+
+// historyRow is the stable JSON shape for `nmsmods history --json`.
+type historyRow struct {
+	Seq     int      `json:"seq"`
+	Time    string   `json:"time"`
+	Command string   `json:"command"`
+	Profile string   `json:"profile"`
+	Mods    []string `json:"mods"`
+	Undone  bool     `json:"undone"`
+}
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show recorded operations (newest first); undone ones can be redone",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p := mustPaths()
+		entries, err := app.ReadHistory(p)
+		if err != nil {
+			return err
+		}
+		_, redo := app.HistoryStacks(entries)
+		undone := map[int]bool{}
+		for _, e := range redo {
+			undone[e.Seq] = true
+		}
+
+		rows := []historyRow{}
+		for i := len(entries) - 1; i >= 0; i-- {
+			e := entries[i]
+			if e.Kind != app.HistoryOp {
+				continue
+			}
+			if historyLimit > 0 && len(rows) >= historyLimit {
+				break
+			}
+			r := historyRow{Seq: e.Seq, Time: e.Time, Command: e.Command, Profile: e.ProfileAfter, Mods: []string{}, Undone: undone[e.Seq]}
+			for _, c := range e.Changes {
+				r.Mods = append(r.Mods, c.ID)
+			}
+			rows = append(rows, r)
+		}
+
+		if historyJSON {
+			b, _ := json.MarshalIndent(rows, "", "  ")
+			fmt.Fprintln(cmd.OutOrStdout(), string(b))
+			return nil
+		}
+		if len(rows) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "No recorded operations.")
+			return nil
+		}
+		for _, r := range rows {
+			mark := ""
+			if r.Undone {
+				mark = "  (undone)"
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%4d  %s  %s  [%s]%s\n", r.Seq, r.Time, r.Command, strings.Join(r.Mods, ", "), mark)
+		}
+		return nil
+	},
+}
+
+func parseCountArg(args []string) (int, error) {
+	if len(args) == 0 {
+		return 1, nil
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 1 {
+		return 0, usageErrorf("expected a positive count, got %q", args[0])
+	}
+	return n, nil
+}
+
+var undoCmd = &cobra.Command{
+	Use:   "undo [n]",
+	Short: "Undo the last n recorded operations (default 1) and redeploy",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		n, err := parseCountArg(args)
+		if err != nil {
+			return err
+		}
+		p := mustPaths()
+		return withStateLock(p, func() error {
+			for i := 0; i < n; i++ {
+				entries, err := app.ReadHistory(p)
+				if err != nil {
+					return err
+				}
+				undo, _ := app.HistoryStacks(entries)
+				if len(undo) == 0 {
+					if i == 0 {
+						return fmt.Errorf("nothing to undo")
+					}
+					return nil
+				}
+				op := undo[len(undo)-1]
+				stores, err := replayHistory(p, op, true, nil)
+				if err != nil {
+					return fmt.Errorf("undo #%d (%s): %w", op.Seq, op.Command, err)
+				}
+				if _, err := app.AppendHistory(p, app.HistoryEntry{Kind: app.HistoryUndo, Target: op.Seq, Command: op.Command, Stores: stores}); err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Undone #%d: %s\n", op.Seq, op.Command)
+			}
+			return nil
+		})
+	},
+}
+
+var redoCmd = &cobra.Command{
+	Use:   "redo [n]",
+	Short: "Redo the last n undone operations (default 1) and redeploy",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		n, err := parseCountArg(args)
+		if err != nil {
+			return err
+		}
+		p := mustPaths()
+		return withStateLock(p, func() error {
+			for i := 0; i < n; i++ {
+				entries, err := app.ReadHistory(p)
+				if err != nil {
+					return err
+				}
+				_, redo := app.HistoryStacks(entries)
+				if len(redo) == 0 {
+					if i == 0 {
+						return fmt.Errorf("nothing to redo")
+					}
+					return nil
+				}
+				op := redo[len(redo)-1]
+				displaced := map[string]string{}
+				if u, ok := app.LastUndo(entries, op.Seq); ok {
+					for _, sc := range u.Stores {
+						displaced[sc.Store] = sc.Displaced
+					}
+				}
+				stores, err := replayHistory(p, op, false, displaced)
+				if err != nil {
+					return fmt.Errorf("redo #%d (%s): %w", op.Seq, op.Command, err)
+				}
+				if _, err := app.AppendHistory(p, app.HistoryEntry{Kind: app.HistoryRedo, Target: op.Seq, Command: op.Command, Stores: stores}); err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Redone #%d: %s\n", op.Seq, op.Command)
+			}
+			return nil
+		})
+	},
+}
+
+func init() {
+	historyCmd.Flags().BoolVar(&historyJSON, "json", false, "Output in JSON format")
+	historyCmd.Flags().IntVar(&historyLimit, "limit", 20, "Show at most this many operations (0 = all)")
+}
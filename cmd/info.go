@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"path/filepath"
+	"strings"
 
 	"nmsmods/internal/app"
 
@@ -15,9 +16,11 @@ var infoJSON bool
 // infoOut is the stable JSON shape for `nmsmods info --json`.
 // Keep the original keys for backward compatibility and add new fields as needed.
 type infoOut struct {
-	ID string `json:"id"`
+	ID     string `json:"id"`
+	Handle int    `json:"handle,omitempty"`
 
-	// Backward-compatible fields
+	// Backward-compatible fields. folder/installed/installed_at/installed_path describe the
+	// active profile's installation.
 	URL          string `json:"url,omitempty"`
 	ZipPath      string `json:"zip_path,omitempty"` // absolute path to zip under downloads/
 	Folder       string `json:"folder,omitempty"`
@@ -28,22 +31,28 @@ type infoOut struct {
 	Source        string         `json:"source,omitempty"` // local|url|nexus
 	DisplayName   string         `json:"display_name,omitempty"`
 	Nexus         *app.NexusInfo `json:"nexus,omitempty"`
+	Profile       string         `json:"profile,omitempty"`
+	Enabled       bool           `json:"enabled,omitempty"`
 	InstalledAt   string         `json:"installed_at,omitempty"`
 	InstalledPath string         `json:"installed_path,omitempty"`
+	Store         string         `json:"store,omitempty"`
 	Health        string         `json:"health,omitempty"` // ok|warning
 	SHA256        string         `json:"sha256,omitempty"`
+	Tags          []string       `json:"tags,omitempty"`
+	Notes         string         `json:"notes,omitempty"`
+	Category      string         `json:"category,omitempty"` // Nexus mod category
 
 	// Convenience: relative zip path as stored in state (debugging)
 	ZipRel string `json:"zip_rel,omitempty"`
 }
 
 var infoCmd = &cobra.Command{
-	Use:   "info <id-or-index>",
+	Use:   "info <mod>",
 	Short: "Show detailed info about a tracked mod",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		p := mustPaths()
-		st, err := app.LoadState(p.State)
+		st, err := loadState(p)
 		if err != nil {
 			return err
 		}
@@ -52,6 +61,12 @@ var infoCmd = &cobra.Command{
 			return err
 		}
 		me := st.Mods[id]
+		cfg, err := loadConfig(p)
+		if err != nil {
+			return err
+		}
+		profile := activeProfile(&cfg)
+		pi := me.Installations[profile]
 
 		zipAbs := ""
 		if me.ZIP != "" {
@@ -60,19 +75,26 @@ var infoCmd = &cobra.Command{
 
 		out := infoOut{
 			ID:           id,
+			Handle:       me.Handle,
 			URL:          me.URL,
 			ZipPath:      zipAbs,
-			Folder:       me.Folder,
-			Installed:    me.Installed,
+			Folder:       pi.Folder,
+			Installed:    pi.Installed,
 			DownloadedAt: me.DownloadedAt,
 
 			Source:        me.Source,
 			DisplayName:   me.DisplayName,
 			Nexus:         me.Nexus,
-			InstalledAt:   me.InstalledAt,
-			InstalledPath: me.InstalledPath,
+			Profile:       profile,
+			Enabled:       pi.Enabled,
+			InstalledAt:   pi.InstalledAt,
+			InstalledPath: pi.DeployedPath,
+			Store:         pi.Store,
 			Health:        me.Health,
 			SHA256:        me.SHA256,
+			Tags:          me.Tags,
+			Notes:         me.Notes,
+			Category:      me.Category(),
 
 			ZipRel: me.ZIP,
 		}
@@ -84,6 +106,9 @@ var infoCmd = &cobra.Command{
 		}
 
 		fmt.Println("id:        ", out.ID)
+		if out.Handle != 0 {
+			fmt.Println("handle:    ", out.Handle)
+		}
 		if out.DisplayName != "" && out.DisplayName != out.ID {
 			fmt.Println("name:      ", out.DisplayName)
 		}
@@ -99,12 +124,15 @@ var infoCmd = &cobra.Command{
 		if out.Folder != "" {
 			fmt.Println("folder:    ", out.Folder)
 		}
-		fmt.Println("installed: ", out.Installed)
+		fmt.Printf("installed:  %v (profile %s, enabled=%v)\n", out.Installed, out.Profile, out.Enabled)
 		if out.InstalledAt != "" {
 			fmt.Println("installed:", out.InstalledAt)
 		}
+		if out.Store != "" {
+			fmt.Println("store:     ", out.Store)
+		}
 		if out.InstalledPath != "" {
-			fmt.Println("path:     ", out.InstalledPath)
+			fmt.Println("path:      ", out.InstalledPath)
 		}
 		if out.Health != "" {
 			fmt.Println("health:   ", out.Health)
@@ -115,6 +143,15 @@ var infoCmd = &cobra.Command{
 		if out.DownloadedAt != "" {
 			fmt.Println("downloaded:", out.DownloadedAt)
 		}
+		if out.Category != "" {
+			fmt.Println("category: ", out.Category)
+		}
+		if len(out.Tags) > 0 {
+			fmt.Println("tags:     ", strings.Join(out.Tags, ", "))
+		}
+		if out.Notes != "" {
+			fmt.Println("notes:    ", out.Notes)
+		}
 		// Nexus details (best-effort, only if present)
 		if out.Nexus != nil {
 			if out.Nexus.GameDomain != "" {
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"nmsmods/internal/mods"
+	"nmsmods/pkg/nmsmods"
+
+	"github.com/spf13/cobra"
+)
+
+var inspectJSON bool
+
+type inspectFile struct {
+	Path   string `json:"path"`   // normalized game path
+	Source string `json:"source"` // loose path or Archive.pak:PATH
+}
+
+// inspectOut is the stable JSON shape for `nmsmods inspect --json`.
+type inspectOut struct {
+	ID        string          `json:"id"`
+	Profile   string          `json:"profile"`
+	Store     string          `json:"store"`
+	Files     []inspectFile   `json:"files"`
+	Conflicts []mods.Conflict `json:"conflicts"`
+}
+
+var inspectCmd = &cobra.Command{
+	Use:   "inspect <mod>",
+	Short: "List the game files a mod provides (including files packed in .pak archives) and conflicts",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p := mustPaths()
+		cfg, err := loadConfig(p)
+		if err != nil {
+			return err
+		}
+		profile := activeProfile(&cfg)
+
+		st, err := loadState(p)
+		if err != nil {
+			return err
+		}
+		id, err := resolveModArg(args[0], st)
+		if err != nil {
+			return err
+		}
+		pi, ok := st.Mods[id].Installations[profile]
+		if !ok || !pi.Installed || pi.Store == "" {
+			return &nmsmods.NotInstalledError{ID: id, Profile: profile}
+		}
+		storeAbs := joinPathFromState(p.Root, pi.Store)
+		if _, err := os.Stat(storeAbs); err != nil {
+			return fmt.Errorf("stored mod folder not found: %s", storeAbs)
+		}
+
+		set, err := mods.ModFileSet(storeAbs)
+		if err != nil {
+			return err
+		}
+		// Compare against every other mod enabled in the same profile.
+		conflicts, err := mods.FileConflicts(p.Root, st, profile, id, storeAbs)
+		if err != nil {
+			return err
+		}
+
+		out := inspectOut{ID: id, Profile: profile, Store: storeAbs, Files: []inspectFile{}, Conflicts: conflicts}
+		for k, src := range set {
+			out.Files = append(out.Files, inspectFile{Path: k, Source: src})
+		}
+		sort.Slice(out.Files, func(i, j int) bool { return out.Files[i].Path < out.Files[j].Path })
+
+		if inspectJSON {
+			b, _ := json.MarshalIndent(out, "", "  ")
+			fmt.Fprintln(cmd.OutOrStdout(), string(b))
+			return nil
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "%s (profile: %s)\n", id, profile)
+		fmt.Fprintln(cmd.OutOrStdout(), "store:", storeAbs)
+		fmt.Fprintf(cmd.OutOrStdout(), "files: %d\n", len(out.Files))
+		for _, f := range out.Files {
+			if f.Source != f.Path {
+				fmt.Fprintf(cmd.OutOrStdout(), "  %s  [%s]\n", f.Path, f.Source)
+			} else {
+				fmt.Fprintf(cmd.OutOrStdout(), "  %s\n", f.Path)
+			}
+		}
+		if len(out.Conflicts) > 0 {
+			fmt.Fprintf(cmd.OutOrStdout(), "conflicts: %d\n", len(out.Conflicts))
+			for _, c := range out.Conflicts {
+				fmt.Fprintf(cmd.OutOrStdout(), "  %s: %v\n", c.Path, c.Mods)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	inspectCmd.Flags().BoolVar(&inspectJSON, "json", false, "Output in JSON format")
+}
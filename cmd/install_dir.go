@@ -7,6 +7,7 @@ import (
 
 	"nmsmods/internal/app"
 	"nmsmods/internal/mods"
+	"nmsmods/pkg/nmsmods"
 
 	"github.com/spf13/cobra"
 )
@@ -23,11 +24,14 @@ var installDirCmd = &cobra.Command{
 		p := mustPaths()
 		src := args[0]
 
-		return withStateLock(p, func() error {
+		return withHistory(p, cmd, args, func(h *historyRecorder) error {
 			cfg, game, err := requireGame(p)
 			if err != nil {
 				return err
 			}
+			if err := ensureGameNotRunning(); err != nil {
+				return err
+			}
 			profile, err := ensureActiveProfileDirs(p, cfg)
 			if err != nil {
 				return err
@@ -45,8 +49,11 @@ var installDirCmd = &cobra.Command{
 			if id == "" {
 				id = mods.SlugFromURL(filepath.Base(src))
 			}
+			if err := app.ValidateModID(id); err != nil {
+				return &usageError{err: err}
+			}
 
-			state, err := app.LoadState(p.State)
+			state, err := loadState(p)
 			if err != nil {
 				return err
 			}
@@ -61,6 +68,14 @@ var installDirCmd = &cobra.Command{
 			storePath := filepath.Join(storeDir, folder)
 			deployPath := filepath.Join(game.ModsDir, folder)
 
+			res := nmsmods.InstallResult{
+				ID: id, Profile: profile, Folder: folder, StorePath: storePath, Collided: collided,
+				StoreExisted: fileExists(storePath), DryRun: installDirDryRun,
+			}
+			if profileDeploys(cfg) {
+				res.DeployPath = deployPath
+			}
+			setResult(res)
 			if installDirDryRun {
 				fmt.Println("[dry-run] Would install directory:")
 				fmt.Println("  profile:", profile)
@@ -90,7 +105,7 @@ var installDirCmd = &cobra.Command{
 					return fmt.Errorf("destination exists in profile store: %s (run without --no-overwrite to replace it)", storePath)
 				}
 				fmt.Println("Replacing existing profile install:", storePath)
-				if err := os.RemoveAll(storePath); err != nil {
+				if err := h.TrashStore(storePath, id); err != nil {
 					return err
 				}
 			}
@@ -100,18 +115,18 @@ var installDirCmd = &cobra.Command{
 				return err
 			}
 
-			deployed, err := mods.Deploy(storePath, game.ModsDir, folder, id, profile)
-			if err != nil {
-				return err
-			}
-
 			me := state.Mods[id]
 			if me.Installations == nil {
 				me.Installations = map[string]app.ProfileInstall{}
 			}
 			pi := me.Installations[profile]
+			deployed, enabled, err := deployInstalled(p, cfg, game, storePath, folder, id, profile, pi)
+			if err != nil {
+				return err
+			}
+
 			pi.Installed = true
-			pi.Enabled = true
+			pi.Enabled = enabled
 			pi.Folder = folder
 			pi.Store = filepath.ToSlash(filepath.Join("profiles", profile, "mods", folder))
 			pi.DeployedPath = deployed
@@ -136,20 +151,21 @@ var installDirCmd = &cobra.Command{
 			} else {
 				me.Health = "ok"
 			}
-
-			// Legacy best-effort.
-			me.Folder = folder
-			me.Installed = true
-			me.InstalledPath = deployed
-			me.InstalledAt = pi.InstalledAt
+			if conflicts, err := mods.FileConflicts(p.Root, state, profile, id, storePath); err == nil && len(conflicts) > 0 {
+				res.Conflicts = conflicts
+				fmt.Fprintf(os.Stderr, "Warning: %s shares %d game file(s) with other enabled mods (see nmsmods inspect %s)\n", id, len(conflicts), id)
+			}
 
 			state.Mods[id] = me
-			if err := app.SaveState(p.State, state); err != nil {
+			if err := saveState(p, state); err != nil {
 				return err
 			}
 
+			res.DeployedPath, res.Enabled, res.Health = deployed, enabled, me.Health
+			setResult(res)
 			fmt.Println("Installed in profile:", profile)
-			fmt.Println("Deployed to:", deployed)
+			printDeployed(id, profile, deployed, enabled)
+			warnIfModsDisabled(cmd, game)
 			return nil
 		})
 	},
@@ -3,36 +3,101 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
 
+	"nmsmods/internal/app"
+	"nmsmods/internal/mods"
 	"nmsmods/internal/nms"
 
 	"github.com/spf13/cobra"
 )
 
-var installedJSON bool
+var (
+	installedJSON   bool
+	installedFilter listFilterFlags
+)
 
 type installedRow struct {
-	Folder string `json:"folder"`
+	Folder     string   `json:"folder"`
+	Managed    bool     `json:"managed"`
+	ModID      string   `json:"mod_id,omitempty"`
+	Profile    string   `json:"profile,omitempty"`
+	Enabled    bool     `json:"enabled,omitempty"` // state records this folder as enabled for the marker's profile
+	StaleMBINs []string `json:"stale_mbins,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+	Category   string   `json:"category,omitempty"` // Nexus mod category
 }
 
 var installedCmd = &cobra.Command{
 	Use:   "installed",
-	Short: "List installed mod folders under <NMS>/GAMEDATA/MODS",
+	Short: "List installed mod folders under <NMS>/GAMEDATA/MODS (or a --profile's store)",
+	Long: `List installed mod folders under <NMS>/GAMEDATA/MODS, or a non-active --profile's store.
+
+--tag and --category keep only folders of matching tracked mods; --group-by tag|category
+groups the text listing.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := installedFilter.validate(); err != nil {
+			return err
+		}
 		p := mustPaths()
-		_, game, err := requireGame(p)
+		cfg, game, err := requireGame(p)
 		if err != nil {
 			return err
 		}
+		if !profileDeploys(cfg) {
+			return listProfileStore(activeProfile(cfg))
+		}
 		modsList, err := nms.ListInstalledModFolders(game)
 		if err != nil {
 			return err
 		}
 
+		st, err := loadState(p)
+		if err != nil {
+			return err
+		}
+		// enabledIn reports whether state deploys folder for modID in profile.
+		enabledIn := func(modID, profile, folder string) bool {
+			pi := st.Mods[modID].Installations[profile]
+			return pi.Installed && pi.Enabled && pi.Folder == folder
+		}
+		// entryOf returns the tracked mod a folder's marker names (ok false for unmanaged folders).
+		entryOf := func(folder string) (mods.ManagedMarker, app.ModEntry, bool) {
+			mk, err := mods.ReadManagedMarker(filepath.Join(game.ModsDir, folder))
+			if err != nil {
+				return mk, app.ModEntry{}, false
+			}
+			me, ok := st.Mods[mk.ModID]
+			return mk, me, ok
+		}
+		if installedFilter.filtered() {
+			kept := modsList[:0]
+			for _, m := range modsList {
+				if _, me, ok := entryOf(m); ok && installedFilter.keep(me) {
+					kept = append(kept, m)
+				}
+			}
+			modsList = kept
+		}
+
 		if installedJSON {
+			ref, closeRef, _ := gameMBINReference(game, "")
+			defer closeRef()
 			out := make([]installedRow, 0, len(modsList))
 			for _, m := range modsList {
-				out = append(out, installedRow{Folder: m})
+				row := installedRow{Folder: m}
+				dir := filepath.Join(game.ModsDir, m)
+				if mk, err := mods.ReadManagedMarker(dir); err == nil {
+					row.Managed = true
+					row.ModID = mk.ModID
+					row.Profile = mk.Profile
+					row.Enabled = enabledIn(mk.ModID, mk.Profile, m)
+					me := st.Mods[mk.ModID]
+					row.Tags, row.Category = me.Tags, me.Category()
+				}
+				row.StaleMBINs = staleMBINPaths(dir, ref)
+				out = append(out, row)
 			}
 			b, _ := json.MarshalIndent(out, "", "  ")
 			fmt.Println(string(b))
@@ -43,13 +108,73 @@ var installedCmd = &cobra.Command{
 			fmt.Println("(none)")
 			return nil
 		}
+		lines := make([]listLine, 0, len(modsList))
 		for _, m := range modsList {
-			fmt.Println(m)
+			mk, me, tracked := entryOf(m)
+			l := listLine{groups: []string{"(unmanaged)"}}
+			if tracked {
+				l.groups = installedFilter.groups(me)
+			}
+			switch {
+			case mk.ModID == "":
+				l.text = m
+			case enabledIn(mk.ModID, mk.Profile, m):
+				l.text = fmt.Sprintf("%s\t%s (%s)", m, mk.ModID, mk.Profile)
+			default:
+				l.text = fmt.Sprintf("%s\t%s (%s, not in state; run: nmsmods fsck)", m, mk.ModID, mk.Profile)
+			}
+			lines = append(lines, l)
 		}
+		printListLines(os.Stdout, installedFilter.groupBy, lines)
 		return nil
 	},
 }
 
+// listProfileStore lists a non-active profile's installs from state; nothing of it is deployed.
+func listProfileStore(profile string) error {
+	p := mustPaths()
+	st, err := loadState(p)
+	if err != nil {
+		return err
+	}
+	out := []installedRow{}
+	for _, id := range sortedModIDs(st) {
+		pi, ok := st.Mods[id].Installations[profile]
+		me := st.Mods[id]
+		if !ok || !pi.Installed || !installedFilter.keep(me) {
+			continue
+		}
+		out = append(out, installedRow{
+			Folder: pi.Folder, Managed: true, ModID: id, Profile: profile, Enabled: pi.Enabled,
+			Tags: me.Tags, Category: me.Category(),
+		})
+	}
+	if installedJSON {
+		b, _ := json.MarshalIndent(out, "", "  ")
+		fmt.Println(string(b))
+		return nil
+	}
+	fmt.Printf("Profile %s is not active; listing its store (nothing is deployed)\n", profile)
+	if len(out) == 0 {
+		fmt.Println("(none)")
+		return nil
+	}
+	lines := make([]listLine, 0, len(out))
+	for _, r := range out {
+		state := "enabled"
+		if !r.Enabled {
+			state = "disabled"
+		}
+		lines = append(lines, listLine{
+			groups: installedFilter.groups(st.Mods[r.ModID]),
+			text:   fmt.Sprintf("%s\t%s (%s, %s)", r.Folder, r.ModID, r.Profile, state),
+		})
+	}
+	printListLines(os.Stdout, installedFilter.groupBy, lines)
+	return nil
+}
+
 func init() {
 	installedCmd.Flags().BoolVar(&installedJSON, "json", false, "Output in JSON format")
+	installedFilter.register(installedCmd)
 }
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"nmsmods/internal/app"
+	"nmsmods/pkg/nmsmods"
+
+	"github.com/spf13/cobra"
+)
+
+// listFilterFlags filter and group the downloads and installed listings.
+type listFilterFlags struct {
+	tags     []string
+	category string
+	groupBy  string
+}
+
+func (f *listFilterFlags) register(c *cobra.Command) {
+	c.Flags().StringSliceVar(&f.tags, "tag", nil, "Only mods with this tag (repeatable; any of them)")
+	c.Flags().StringVar(&f.category, "category", "", "Only mods in this Nexus category (e.g. Visuals)")
+	c.Flags().StringVar(&f.groupBy, "group-by", "", "Group the text listing by tag or category")
+}
+
+func (f *listFilterFlags) validate() error {
+	switch f.groupBy {
+	case "", "tag", "category":
+		return nil
+	}
+	return usageErrorf("invalid --group-by %q (use tag or category)", f.groupBy)
+}
+
+// filtered reports whether any filter is set; listings then drop folders of untracked mods.
+func (f *listFilterFlags) filtered() bool { return len(f.tags) > 0 || f.category != "" }
+
+func (f *listFilterFlags) keep(me app.ModEntry) bool {
+	return nmsmods.Selector{Tags: f.tags, Category: f.category}.Match(me)
+}
+
+// groups returns the --group-by groups a mod is listed under (a mod with several tags appears
+// under each).
+func (f *listFilterFlags) groups(me app.ModEntry) []string {
+	switch f.groupBy {
+	case "tag":
+		if len(me.Tags) > 0 {
+			return me.Tags
+		}
+		return []string{"(untagged)"}
+	case "category":
+		if c := me.Category(); c != "" {
+			return []string{c}
+		}
+		return []string{"(no category)"}
+	}
+	return nil
+}
+
+// listLine is one line of a text listing and the groups it belongs to.
+type listLine struct {
+	groups []string
+	text   string
+}
+
+// printListLines prints lines as they are, or under a "<group> (<n>)" header per group when
+// grouping. Groups are sorted by name; the "(...)" catch-all groups come last.
+func printListLines(w io.Writer, groupBy string, lines []listLine) {
+	if groupBy == "" {
+		for _, l := range lines {
+			fmt.Fprintln(w, l.text)
+		}
+		return
+	}
+	byGroup := map[string][]string{}
+	for _, l := range lines {
+		for _, g := range l.groups {
+			byGroup[g] = append(byGroup[g], l.text)
+		}
+	}
+	names := make([]string, 0, len(byGroup))
+	for g := range byGroup {
+		names = append(names, g)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		ci, cj := names[i][0] == '(', names[j][0] == '('
+		if ci != cj {
+			return cj
+		}
+		return names[i] < names[j]
+	})
+	for i, g := range names {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "%s (%d)\n", g, len(byGroup[g]))
+		for _, t := range byGroup[g] {
+			fmt.Fprintln(w, "  "+t)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"nmsmods/internal/app"
+	"nmsmods/internal/logging"
+
+	"github.com/spf13/cobra"
+)
+
+// Global logging flags: -v/-vv log to stderr, --log-format picks its format. The log file
+// under the state dir always records debug level.
+var (
+	verbosity int
+	logFormat = logging.FormatText
+)
+
+var (
+	closeLog     = func() error { return nil }
+	commandStart time.Time
+)
+
+// setupLogging installs the slog default for this invocation and logs the command.
+func setupLogging(cmd *cobra.Command, args []string) error {
+	if logFormat != logging.FormatText && logFormat != logging.FormatJSON {
+		return usageErrorf("invalid --log-format %q (use text or json)", logFormat)
+	}
+	file := ""
+	if p, err := app.DefaultPathsWithOverride(homeOverride); err == nil {
+		file = logging.Path(p.Root)
+	}
+	closeFn, err := logging.Setup(logging.Options{File: file, Verbosity: verbosity, Format: logFormat, Stderr: os.Stderr})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Warning: "+err.Error())
+	}
+	if closeFn != nil {
+		closeLog = closeFn
+	}
+	commandStart = time.Now()
+	slog.Info("command", "command", cmd.CommandPath(), "args", redactArgs(args), "pid", os.Getpid(), "version", app.Version)
+	return nil
+}
+
+// finishLogging records how the invocation ended and closes the log file.
+func finishLogging(err error) {
+	if !commandStart.IsZero() {
+		d := time.Since(commandStart).Milliseconds()
+		if err != nil {
+			code, exit := errorCode(err)
+			slog.Error("command failed", "error", err, "code", code, "exit_code", exit, "duration_ms", d)
+		} else {
+			slog.Info("command finished", "duration_ms", d)
+		}
+	}
+	_ = closeLog()
+	closeLog = func() error { return nil }
+	commandStart = time.Time{}
+}
+
+// redactArgs hides secrets in URL arguments (nxm:// keys, signed download links).
+func redactArgs(args []string) []string {
+	out := make([]string, len(args))
+	for i, a := range args {
+		if strings.Contains(a, "://") {
+			a = logging.RedactURL(a)
+		}
+		out[i] = a
+	}
+	return out
+}
@@ -2,11 +2,11 @@ package cmd
 
 import (
 	"context"
-	"errors"
 	"time"
 
 	"nmsmods/internal/app"
 	"nmsmods/internal/nexus"
+	"nmsmods/pkg/nmsmods"
 
 	"github.com/spf13/cobra"
 )
@@ -37,7 +37,7 @@ func nexusPathsConfig() (*app.Paths, app.Config, error) {
 // requireNexusAPIKey returns config with a non-empty Nexus API key.
 func requireNexusAPIKey(cfg app.Config) (string, error) {
 	if cfg.Nexus.APIKey == "" {
-		return "", errors.New("nexus api key not set (run: nmsmods nexus login)")
+		return "", nmsmods.ErrNoAPIKey
 	}
 	return cfg.Nexus.APIKey, nil
 }
@@ -53,3 +53,14 @@ func newNexusClientFromConfig(cfg app.Config) (*nexus.Client, error) {
 func nexusCtx() (context.Context, context.CancelFunc) {
 	return context.WithTimeout(context.Background(), 30*time.Second)
 }
+
+// setModCategory records mod's Nexus category in ni (best-effort: unknown on API errors).
+func setModCategory(ctx context.Context, client *nexus.Client, ni *app.NexusInfo, mod *nexus.ModInfo) {
+	if mod == nil || mod.CategoryID == 0 {
+		return
+	}
+	ni.ModCategoryID = mod.CategoryID
+	if name, err := client.ModCategory(ctx, ni.GameDomain, mod.CategoryID); err == nil {
+		ni.ModCategory = name
+	}
+}
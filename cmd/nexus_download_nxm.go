@@ -66,7 +66,7 @@ var nexusDownloadNXMCmd = &cobra.Command{
 		}
 
 		// Enrich state with Nexus metadata (best-effort).
-		st, err := app.LoadState(p.State)
+		st, err := loadState(p)
 		if err != nil {
 			return nil // best-effort
 		}
@@ -106,6 +106,7 @@ var nexusDownloadNXMCmd = &cobra.Command{
 		if mod != nil {
 			ni.ModName = mod.Name
 			ni.ModUpdatedTime = mod.UpdatedTime
+			setModCategory(ctx, client, ni, mod)
 			if ni.Version == "" {
 				ni.Version = mod.Version
 			}
@@ -122,7 +123,7 @@ var nexusDownloadNXMCmd = &cobra.Command{
 		me.Nexus = ni
 
 		st.Mods[id] = me
-		_ = app.SaveState(p.State, st)
+		_ = saveState(p, st)
 
 		return nil
 	},
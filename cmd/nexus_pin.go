@@ -3,22 +3,20 @@ package cmd
 import (
 	"fmt"
 
-	"nmsmods/internal/app"
-
 	"github.com/spf13/cobra"
 )
 
 // Phase 4: pin/unpin Nexus-tracked mods to avoid update prompts.
 
 var nexusPinCmd = &cobra.Command{
-	Use:   "pin <id-or-index>",
+	Use:   "pin <mod>",
 	Short: "Pin a Nexus-tracked mod (prevent check-updates from flagging it)",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		p := mustPaths()
 
 		return withStateLock(p, func() error {
-			st, err := app.LoadState(p.State)
+			st, err := loadState(p)
 			if err != nil {
 				return err
 			}
@@ -48,7 +46,7 @@ var nexusPinCmd = &cobra.Command{
 
 			me.Nexus.Pinned = desired
 			st.Mods[id] = me
-			if err := app.SaveState(p.State, st); err != nil {
+			if err := saveState(p, st); err != nil {
 				return err
 			}
 
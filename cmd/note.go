@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var noteClear bool
+
+var noteCmd = &cobra.Command{
+	Use:   "note <mod> [text...]",
+	Short: "Show or set free-text notes on a mod",
+	Long: `Show a mod's notes, or replace them with text (the remaining arguments, joined by spaces).
+--clear removes them. Notes are shown by info and recorded in history.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p := mustPaths()
+		w := cmd.OutOrStdout()
+		if len(args) == 1 && !noteClear {
+			st, err := loadState(p)
+			if err != nil {
+				return err
+			}
+			id, err := resolveModArg(args[0], st)
+			if err != nil {
+				return err
+			}
+			notes := st.Mods[id].Notes
+			setResult(map[string]string{"id": id, "notes": notes})
+			if notes == "" {
+				fmt.Fprintln(w, "(no notes)")
+				return nil
+			}
+			fmt.Fprintln(w, notes)
+			return nil
+		}
+		if noteClear && len(args) > 1 {
+			return usageErrorf("use either note text or --clear, not both")
+		}
+		text := strings.Join(args[1:], " ")
+		res, err := newManager(p, commandLineOption(cmd, args)).SetNote(cmd.Context(), args[0], text)
+		if err != nil {
+			return err
+		}
+		setResult(res)
+		switch {
+		case res.Unchanged:
+			fmt.Fprintln(w, "Unchanged:", res.ID)
+		case res.Notes == "":
+			fmt.Fprintln(w, "Cleared notes:", res.ID)
+		default:
+			fmt.Fprintln(w, "Saved notes:", res.ID)
+		}
+		return nil
+	},
+}
+
+func init() {
+	noteCmd.Flags().BoolVar(&noteClear, "clear", false, "Remove the mod's notes")
+}
@@ -3,16 +3,15 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"os"
 	"os/exec"
-	"path/filepath"
 	"strings"
 	"time"
 
 	"nmsmods/internal/app"
 	"nmsmods/internal/nexus"
 	"nmsmods/internal/nms"
-	"nmsmods/internal/steam"
 
 	"github.com/spf13/cobra"
 )
@@ -31,32 +30,19 @@ var nxmHandleCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		p := mustPaths()
 		quiet, _ := cmd.Flags().GetBool("quiet")
-		profileFlag, _ := cmd.Flags().GetString("profile")
 		rawURL := strings.TrimSpace(args[0])
 
 		// Log + (best-effort) desktop notification because this is typically launched from a browser.
 		return withStateLock(p, func() error {
-			// Prefer the state root (XDG state dir) for logs.
-			logPath := filepath.Join(p.Root, "nxm-handler.log")
-			logf := func(format string, a ...any) {
-				_ = os.MkdirAll(filepath.Dir(logPath), 0o755)
-				f, err := os.OpenFile(logPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
-				if err != nil {
-					return
-				}
-				defer f.Close()
-				ts := time.Now().Format(time.RFC3339)
-				_, _ = fmt.Fprintf(f, "%s "+format+"\n", append([]any{ts}, a...)...)
-			}
 			finish := func(title, msg string, err error) error {
 				if err != nil {
-					logf("ERROR: %s: %v", msg, err)
+					slog.Error("nxm handle failed", "step", msg, "error", err)
 					if !quiet {
 						_ = notify(title, msg+": "+err.Error())
 					}
 					return err
 				}
-				logf("OK: %s", msg)
+				slog.Info("nxm handle done", "result", msg)
 				if !quiet {
 					_ = notify(title, msg)
 				}
@@ -68,16 +54,8 @@ var nxmHandleCmd = &cobra.Command{
 				return finish("nmsmods", "failed to load config", err)
 			}
 
-			// Optional: override profile by setting it active (persistent).
-			if pf := strings.TrimSpace(profileFlag); pf != "" {
-				cfg.ActiveProfile = pf
-				if err := app.SaveConfig(p.Config, cfg); err != nil {
-					return finish("nmsmods", "failed to save config", err)
-				}
-			}
-
 			// Ensure game path is configured; try auto-detect if missing.
-			if strings.TrimSpace(cfg.GamePath) == "" {
+			if strings.TrimSpace(cfg.GamePath) == "" && len(cfg.Targets) == 0 {
 				cand, derr := autoDetectSingleGamePath()
 				if derr != nil {
 					return finish("nmsmods", "game path not set", derr)
@@ -88,7 +66,7 @@ var nxmHandleCmd = &cobra.Command{
 				}
 			}
 
-			game, err := nms.ValidateGamePath(cfg.GamePath)
+			game, err := resolveGame(p, &cfg)
 			if err != nil {
 				return finish("nmsmods", "invalid game path", fmt.Errorf("run: nmsmods set-path <path> (or set-path --auto): %w", err))
 			}
@@ -115,7 +93,7 @@ var nxmHandleCmd = &cobra.Command{
 			id := fmt.Sprintf("nx-%d", nxm.ModID)
 
 			// Load state to decide install/update/no-op.
-			st, err := app.LoadState(p.State)
+			st, err := loadState(p)
 			if err != nil {
 				return finish("nmsmods", "failed to load state", err)
 			}
@@ -147,12 +125,10 @@ var nxmHandleCmd = &cobra.Command{
 			if strings.TrimSpace(self) == "" {
 				self = os.Args[0]
 			}
-			dl := exec.Command(self, "download", bestURL, "--id", id)
-			dl.Env = os.Environ()
-			_ = dl.Run() // output is not visible in handler contexts
+			_ = selfCommand(self, "download", "--id="+id, "--", bestURL).Run() // output is not visible in handler contexts
 
 			// Enrich state with Nexus metadata (best-effort).
-			st, err = app.LoadState(p.State)
+			st, err = loadState(p)
 			if err == nil {
 				me = st.Mods[id]
 				me.Source = "nexus"
@@ -179,6 +155,7 @@ var nxmHandleCmd = &cobra.Command{
 				if modInfo != nil {
 					ni.ModName = modInfo.Name
 					ni.ModUpdatedTime = modInfo.UpdatedTime
+					setModCategory(ctx, client, ni, modInfo)
 					if ni.Version == "" {
 						ni.Version = modInfo.Version
 					}
@@ -194,7 +171,7 @@ var nxmHandleCmd = &cobra.Command{
 				}
 				me.Nexus = ni
 				st.Mods[id] = me
-				_ = app.SaveState(p.State, st)
+				_ = saveState(p, st)
 			}
 
 			// Decide action.
@@ -210,16 +187,20 @@ var nxmHandleCmd = &cobra.Command{
 			var runErr error
 			switch action {
 			case "enable":
-				runErr = exec.Command(self, "enable", id).Run()
+				runErr = selfCommand(self, "enable", "--", id).Run()
 			case "reinstall":
-				runErr = exec.Command(self, "reinstall", id).Run()
+				runErr = selfCommand(self, "reinstall", "--", id).Run()
 			default:
-				runErr = exec.Command(self, "install", id).Run()
+				runErr = selfCommand(self, "install", "--", id).Run()
 			}
 			if runErr != nil {
 				return finish("nmsmods", "auto-install failed", runErr)
 			}
-			_ = exec.Command(self, "profile", "deploy").Run()
+			if !profileDeploys(&cfg) {
+				// --profile <other>: the mod is in that profile's store; the game is untouched.
+				return finish("nmsmods", fmt.Sprintf("Stored in profile %s (not active, not deployed): %s", profile, id), nil)
+			}
+			_ = selfCommand(self, "profile", "deploy").Run()
 
 			switch action {
 			case "enable":
@@ -234,43 +215,22 @@ var nxmHandleCmd = &cobra.Command{
 }
 
 func init() {
-	nxmHandleCmd.Flags().String("profile", "", "Override active profile (persists in config)")
-	nxmHandleCmd.Flags().Bool("quiet", false, "Do not show desktop notifications (still logs to nxm-handler.log)")
+	nxmHandleCmd.Flags().Bool("quiet", false, "Do not show desktop notifications (the result is still logged to logs/nmsmods.log)")
 	nxmCmd.AddCommand(nxmHandleCmd)
 }
 
 func autoDetectSingleGamePath() (string, error) {
-	paths, err := steam.GuessNMSPaths()
+	cands, err := validGameCandidates()
 	if err != nil {
 		return "", err
 	}
-	valid := []string{}
-	for _, cand := range paths {
-		g, err := nms.ValidateGamePath(cand)
-		if err == nil {
-			valid = append(valid, g.Path)
-		}
-	}
-	seen := map[string]struct{}{}
-	uniq := []string{}
-	for _, v := range valid {
-		v = strings.TrimSpace(v)
-		if v == "" {
-			continue
-		}
-		if _, ok := seen[v]; ok {
-			continue
-		}
-		seen[v] = struct{}{}
-		uniq = append(uniq, v)
-	}
-	if len(uniq) == 1 {
-		return uniq[0], nil
+	if len(cands) == 1 {
+		return cands[0].Path, nil
 	}
-	if len(uniq) == 0 {
+	if len(cands) == 0 {
 		return "", fmt.Errorf("no valid No Man's Sky installations found. Run: nmsmods set-path <path>")
 	}
-	return "", fmt.Errorf("multiple No Man's Sky installations detected; set one explicitly with: nmsmods set-path <path>\n- %s", strings.Join(uniq, "\n- "))
+	return "", fmt.Errorf("multiple No Man's Sky installations detected; register them as targets with: nmsmods target detect --save (or pick one: nmsmods set-path --auto --from <source>)\n%s", formatCandidates(cands))
 }
 
 func notify(title, body string) error {
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"nmsmods/internal/app"
+	"nmsmods/pkg/nmsmods"
+
+	"github.com/spf13/cobra"
+)
+
+var orderJSON bool
+
+// orderRow is the stable JSON shape for `nmsmods order --json`.
+type orderRow struct {
+	Position int    `json:"position"`
+	ID       string `json:"id"`
+	Folder   string `json:"folder"`
+	Enabled  bool   `json:"enabled"`
+}
+
+var orderCmd = &cobra.Command{
+	Use:   "order [<mod> <position>]",
+	Short: "Show or change the load order of the profile's mods",
+	Long: `No Man's Sky loads GAMEDATA/MODS folders in name order. Without arguments this prints the
+active profile's mods in that order. With a mod and a 1-based position, the mod is moved there
+and the folders of the mods whose position changed are renamed with a numeric prefix (01-,
+02-, ...) that keeps the new order; the other folders are renamed too only when their names
+would not sort in that order. Each mod is saved as soon as it is renamed, so a failure part way
+leaves the state matching the folders. Renamed store folders go through the trash, so
+'nmsmods undo' reverts them.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 0 && len(args) != 2 {
+			return fmt.Errorf("expected no arguments or <mod> <position>")
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p := mustPaths()
+		if len(args) == 0 {
+			cfg, err := loadConfig(p)
+			if err != nil {
+				return err
+			}
+			st, err := loadState(p)
+			if err != nil {
+				return err
+			}
+			return printLoadOrder(cmd, st, activeProfile(&cfg))
+		}
+
+		pos, err := strconv.Atoi(args[1])
+		if err != nil || pos <= 0 {
+			return fmt.Errorf("invalid position: %s", args[1])
+		}
+		res, err := newManager(p, commandLineOption(cmd, args)).MoveInLoadOrder(cmd.Context(), args[0], pos)
+		if err != nil {
+			return err
+		}
+		st, err := loadState(p)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Moved %s to position %d (profile: %s)\n", res.ID, res.Position, res.Profile)
+		return printLoadOrder(cmd, st, res.Profile)
+	},
+}
+
+func printLoadOrder(cmd *cobra.Command, st app.State, profile string) error {
+	rows := []orderRow{}
+	for i, id := range nmsmods.LoadOrder(st, profile) {
+		pi := st.Mods[id].Installations[profile]
+		rows = append(rows, orderRow{Position: i + 1, ID: id, Folder: pi.Folder, Enabled: pi.Enabled})
+	}
+	if orderJSON {
+		b, _ := json.MarshalIndent(rows, "", "  ")
+		fmt.Fprintln(cmd.OutOrStdout(), string(b))
+		return nil
+	}
+	if len(rows) == 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "No mods installed in profile %s\n", profile)
+		return nil
+	}
+	for _, r := range rows {
+		state := "enabled"
+		if !r.Enabled {
+			state = "disabled"
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "%3d. %s\t%s (%s)\n", r.Position, r.Folder, r.ID, state)
+	}
+	return nil
+}
+
+func init() {
+	orderCmd.Flags().BoolVar(&orderJSON, "json", false, "Output in JSON format")
+}
@@ -0,0 +1,229 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+
+	"nmsmods/internal/app"
+	"nmsmods/internal/nexus"
+	"nmsmods/pkg/nmsmods"
+
+	"github.com/spf13/cobra"
+)
+
+// Output formats for the global --output flag.
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+// outputFormat is the global --output flag.
+var outputFormat = outputText
+
+// textOnlyAnnotation marks commands that own the terminal and have no JSON form.
+const textOnlyAnnotation = "nmsmods/text-only"
+
+// Exit codes. They are part of the CLI contract (see README "Output and exit codes").
+const (
+	exitError        = 1 // anything not listed below
+	exitUsage        = 2 // bad arguments, flags or names
+	exitLockBusy     = 3 // another nmsmods process holds the state lock
+	exitGameNotFound = 4 // game path or target not configured, or not a No Man's Sky install
+	exitNotFound     = 5 // unknown mod or handle, mod not installed in the profile, or no download
+	exitNetwork      = 6 // download or Nexus API failure
+	exitGameRunning  = 7 // No Man's Sky is running; GAMEDATA/MODS left alone
+)
+
+// usageError marks an error in the invocation itself (exit code 2).
+type usageError struct{ err error }
+
+func (e *usageError) Error() string { return e.err.Error() }
+func (e *usageError) Unwrap() error { return e.err }
+
+func usageErrorf(format string, a ...any) error {
+	return &usageError{err: fmt.Errorf(format, a...)}
+}
+
+// errorCode classifies err into the stable error code and exit code of --output json.
+func errorCode(err error) (string, int) {
+	var (
+		usage      *usageError
+		selector   *nmsmods.SelectorError
+		game       *nmsmods.GameNotFoundError
+		running    *nmsmods.GameRunningError
+		unknown    *nmsmods.UnknownModError
+		ambiguous  *nmsmods.AmbiguousModError
+		notInst    *nmsmods.NotInstalledError
+		noDownload *nmsmods.NoDownloadError
+		network    *nmsmods.NetworkError
+		apiErr     *nexus.APIError
+		urlErr     *url.Error
+		netErr     net.Error
+	)
+	switch {
+	case errors.As(err, &usage), errors.As(err, &selector):
+		return "usage", exitUsage
+	case errors.As(err, &ambiguous):
+		return "ambiguous", exitUsage
+	case errors.Is(err, app.ErrLockBusy):
+		return "lock_busy", exitLockBusy
+	case errors.As(err, &game):
+		return "game_not_found", exitGameNotFound
+	case errors.As(err, &running):
+		return "game_running", exitGameRunning
+	case errors.As(err, &unknown), errors.As(err, &noDownload):
+		return "not_found", exitNotFound
+	case errors.As(err, &notInst):
+		return "not_installed", exitNotFound
+	case errors.As(err, &network), errors.As(err, &apiErr), errors.As(err, &urlErr), errors.As(err, &netErr):
+		return "network", exitNetwork
+	}
+	// Cobra reports unknown commands and flags as plain errors.
+	msg := err.Error()
+	if strings.HasPrefix(msg, "unknown command") || strings.HasPrefix(msg, "unknown flag") || strings.HasPrefix(msg, "unknown shorthand flag") {
+		return "usage", exitUsage
+	}
+	return "error", exitError
+}
+
+// scanOutputFlag finds --output before cobra parses flags, so errors from parsing itself are
+// reported in the requested format too.
+func scanOutputFlag(args []string) string {
+	for i, a := range args {
+		if a == "--" {
+			break
+		}
+		if v, ok := strings.CutPrefix(a, "--output="); ok {
+			return v
+		}
+		if a == "--output" && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return outputText
+}
+
+// outputEnvelope is the stable JSON shape printed with --output json by commands without their
+// own --json shape, and by every command on failure.
+type outputEnvelope struct {
+	OK       bool         `json:"ok"`
+	Command  string       `json:"command"`
+	Messages []string     `json:"messages"`
+	Result   any          `json:"result,omitempty"`
+	Error    *outputError `json:"error,omitempty"`
+}
+
+type outputError struct {
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+	ExitCode int    `json:"exit_code"`
+}
+
+// jsonRun collects what one --output json invocation prints.
+type jsonRun struct {
+	command  string
+	result   any
+	messages []string
+	orig     *os.File
+	w        *os.File
+	done     chan struct{}
+}
+
+var currentRun *jsonRun
+
+// setResult attaches a command's structured result to the --output json envelope. In text
+// mode it does nothing.
+func setResult(v any) {
+	if currentRun != nil {
+		currentRun.result = v
+	}
+}
+
+// beginJSONOutput prepares cmd for --output json: commands with a --json flag print their own
+// documented shape; for the others, stdout lines become the envelope's "messages".
+func beginJSONOutput(cmd *cobra.Command) error {
+	if cmd.Annotations[textOnlyAnnotation] != "" {
+		return usageErrorf("%s has no JSON output", cmd.CommandPath())
+	}
+	cmd.SilenceUsage = true
+	currentRun = &jsonRun{command: cmd.CommandPath()}
+	if f := cmd.Flags().Lookup("json"); f != nil {
+		return f.Value.Set("true")
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	run := currentRun
+	run.orig, run.w, run.done = os.Stdout, w, make(chan struct{})
+	os.Stdout = w
+	cmd.Root().SetOut(w)
+	go func() {
+		defer close(run.done)
+		sc := bufio.NewScanner(r)
+		sc.Buffer(make([]byte, 64*1024), 1024*1024)
+		for sc.Scan() {
+			run.messages = append(run.messages, sc.Text())
+		}
+		_, _ = io.Copy(io.Discard, r)
+		r.Close()
+	}()
+	return nil
+}
+
+// finishJSONOutput restores stdout and prints the envelope (always on error; on success only
+// for commands that captured their output).
+func finishJSONOutput(out io.Writer, err error) {
+	run := currentRun
+	currentRun = nil
+	captured := run != nil && run.w != nil
+	if captured {
+		os.Stdout = run.orig
+		rootCmd.SetOut(out)
+		run.w.Close()
+		<-run.done
+	}
+	if err == nil && !captured {
+		return
+	}
+
+	env := outputEnvelope{OK: err == nil, Messages: []string{}}
+	if run != nil {
+		env.Command = run.command
+		env.Result = run.result
+		if run.messages != nil {
+			env.Messages = run.messages
+		}
+	}
+	if err != nil {
+		code, exit := errorCode(err)
+		env.Error = &outputError{Code: code, Message: err.Error(), ExitCode: exit}
+		env.Result = nil
+	}
+	b, _ := json.MarshalIndent(env, "", "  ")
+	fmt.Fprintln(out, string(b))
+}
+
+// wrapArgErrors marks argument validation failures of every command as usage errors.
+func wrapArgErrors(c *cobra.Command) {
+	if c.Args != nil {
+		validate := c.Args
+		c.Args = func(cmd *cobra.Command, args []string) error {
+			if err := validate(cmd, args); err != nil {
+				return &usageError{err: err}
+			}
+			return nil
+		}
+	}
+	for _, sub := range c.Commands() {
+		wrapArgErrors(sub)
+	}
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+
+	"nmsmods/internal/app"
+	"nmsmods/internal/nexus"
+	"nmsmods/pkg/nmsmods"
+
+	"github.com/spf13/cobra"
+)
+
+func TestErrorCode(t *testing.T) {
+	boom := errors.New("boom")
+	for _, tc := range []struct {
+		err  error
+		code string
+		exit int
+	}{
+		{usageErrorf("bad flag"), "usage", exitUsage},
+		{&nmsmods.SelectorError{Err: boom}, "usage", exitUsage},
+		{errors.New(`unknown command "x" for "nmsmods"`), "usage", exitUsage},
+		{errors.New("unknown flag: --x"), "usage", exitUsage},
+		{errors.New("unknown shorthand flag: 'x' in -x"), "usage", exitUsage},
+		{&nmsmods.AmbiguousModError{Arg: "b", Candidates: []string{"b1", "b2"}}, "ambiguous", exitUsage},
+		{fmt.Errorf("install: %w", app.ErrLockBusy), "lock_busy", exitLockBusy},
+		{&nmsmods.GameNotFoundError{Target: "default", Err: boom}, "game_not_found", exitGameNotFound},
+		{&nmsmods.GameRunningError{PIDs: []int{42}}, "game_running", exitGameRunning},
+		{&nmsmods.UnknownModError{ID: "x"}, "not_found", exitNotFound},
+		{&nmsmods.NoDownloadError{ID: "x"}, "not_found", exitNotFound},
+		{fmt.Errorf("x: %w", &nmsmods.NotInstalledError{ID: "x", Profile: "default"}), "not_installed", exitNotFound},
+		{&nmsmods.NetworkError{Err: boom}, "network", exitNetwork},
+		{&nexus.APIError{StatusCode: 500, URL: "https://api.nexusmods.com"}, "network", exitNetwork},
+		{&url.Error{Op: "Get", URL: "https://example.com", Err: boom}, "network", exitNetwork},
+		{boom, "error", exitError},
+	} {
+		code, exit := errorCode(tc.err)
+		if code != tc.code || exit != tc.exit {
+			t.Errorf("errorCode(%T %v) = %s, %d; want %s, %d", tc.err, tc.err, code, exit, tc.code, tc.exit)
+		}
+	}
+}
+
+func TestFinishJSONOutput_Envelope(t *testing.T) {
+	run := func(err error) outputEnvelope {
+		t.Helper()
+		if err := beginJSONOutput(&cobra.Command{Use: "nmsmods"}); err != nil {
+			t.Fatal(err)
+		}
+		fmt.Println("Enabled: my-mod")
+		setResult(map[string]string{"id": "my-mod"})
+		var out bytes.Buffer
+		finishJSONOutput(&out, err)
+		var env outputEnvelope
+		if err := json.Unmarshal(out.Bytes(), &env); err != nil {
+			t.Fatalf("not one JSON object: %v\n%s", err, out.Bytes())
+		}
+		return env
+	}
+
+	env := run(nil)
+	if !env.OK || env.Command != "nmsmods" || env.Error != nil || len(env.Messages) != 1 || env.Messages[0] != "Enabled: my-mod" {
+		t.Fatalf("success envelope: %+v", env)
+	}
+	if res, ok := env.Result.(map[string]any); !ok || res["id"] != "my-mod" {
+		t.Fatalf("success result: %#v", env.Result)
+	}
+
+	env = run(&nmsmods.NotInstalledError{ID: "my-mod", Profile: "default"})
+	if env.OK || env.Result != nil || len(env.Messages) != 1 {
+		t.Fatalf("error envelope: %+v", env)
+	}
+	want := outputError{Code: "not_installed", Message: `mod my-mod is not installed in profile "default"`, ExitCode: exitNotFound}
+	if env.Error == nil || *env.Error != want {
+		t.Fatalf("error = %+v, want %+v", env.Error, want)
+	}
+}
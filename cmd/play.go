@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+
+	"nmsmods/internal/app"
+	"nmsmods/pkg/nmsmods"
+
+	"github.com/spf13/cobra"
+)
+
+var playCommand string
+var playNoDeploy bool
+
+// ensureGameNotRunning refuses changes to GAMEDATA/MODS while No Man's Sky is running:
+// swapping files mid-session corrupts loads.
+func ensureGameNotRunning() error {
+	return nmsmods.CheckGameNotRunning()
+}
+
+var playCmd = &cobra.Command{
+	Use:   "play",
+	Short: "Deploy a profile and launch No Man's Sky",
+	Long: `Deploy the active profile (or --profile) into GAMEDATA/MODS and start the game.
+
+The game is started with "steam -applaunch 275850" unless launch_command is set in config.json
+or --command is passed. The command runs through "sh -c".`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p := mustPaths()
+
+		var launch string
+		err := withStateLock(p, func() error {
+			cfg, game, err := requireGame(p)
+			if err != nil {
+				return err
+			}
+			if err := ensureGameNotRunning(); err != nil {
+				return err
+			}
+
+			if !profileDeploys(cfg) {
+				// play is the one command that deploys a non-active profile (--profile): it is
+				// launched for this run only and active_profile is unchanged.
+				fmt.Println("Using profile for this run:", activeProfile(cfg))
+			}
+			if _, err := ensureActiveProfileDirs(p, cfg); err != nil {
+				return err
+			}
+
+			if !playNoDeploy {
+				if err := newManager(p).DeployProfile(*cfg, game); err != nil {
+					return err
+				}
+				fmt.Println("Deployed profile:", activeProfile(cfg))
+				warnIfModsDisabled(cmd, game)
+			}
+
+			launch = strings.TrimSpace(playCommand)
+			if launch == "" {
+				launch = strings.TrimSpace(cfg.LaunchCommand)
+			}
+			if launch == "" {
+				launch = app.DefaultLaunchCommand
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		// Launch outside the lock; the game may run for hours.
+		c := exec.Command("sh", "-c", launch)
+		c.Stdin = nil
+		c.Stdout = nil
+		c.Stderr = nil
+		c.Env = os.Environ()
+		c.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+		if err := c.Start(); err != nil {
+			return fmt.Errorf("failed to launch game (%s): %w", launch, err)
+		}
+		_ = c.Process.Release()
+		fmt.Println("Launched:", launch)
+		return nil
+	},
+}
+
+func init() {
+	playCmd.Flags().StringVar(&playCommand, "command", "", "Launch command for this run (default: config launch_command or \""+app.DefaultLaunchCommand+"\")")
+	playCmd.Flags().BoolVar(&playNoDeploy, "no-deploy", false, "Launch without redeploying the profile")
+}
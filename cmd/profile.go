@@ -3,11 +3,9 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"path/filepath"
 	"sort"
 
 	"nmsmods/internal/app"
-	"nmsmods/internal/mods"
 
 	"github.com/spf13/cobra"
 )
@@ -26,13 +24,58 @@ var profileStatusCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		prof := app.ActiveProfile(cfg)
-		fmt.Println("Active profile:", prof)
-		fmt.Println("Profile store:", app.ProfileModsDir(p, prof))
+		prof, err := resolveProfile(p, &cfg)
+		if err != nil {
+			return err
+		}
+		if profileOverride != "" && profileOverride != app.ActiveProfile(cfg) {
+			fmt.Printf("Active profile: %s (this invocation only; configured: %s)\n", prof.Name, app.ActiveProfile(cfg))
+		} else {
+			fmt.Println("Active profile:", prof.Name)
+		}
+		fmt.Println("Profile store:", app.ProfileModsDir(p, prof.Name))
+		printProfileSettings(prof.Settings)
 		return nil
 	},
 }
 
+func printProfileSettings(s app.ProfileSettings) {
+	if s.Description != "" {
+		fmt.Println("Description:", s.Description)
+	}
+	target := s.Target
+	if target == "" {
+		target = "(unbound: default_target or default)"
+	}
+	fmt.Println("Game target:", target)
+	strategy := s.DeployStrategy
+	if strategy == "" {
+		strategy = app.DeployCopy
+	}
+	fmt.Println("Deploy strategy:", strategy)
+	if s.NewModsDisabled {
+		fmt.Println("New mods: installed disabled")
+	} else {
+		fmt.Println("New mods: installed enabled")
+	}
+}
+
+// listProfiles returns "default" plus every profile directory, sorted.
+func listProfiles(p *app.Paths) ([]string, error) {
+	entries, err := os.ReadDir(p.Profiles)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	names := []string{"default"}
+	for _, e := range entries {
+		if e.IsDir() && e.Name() != "default" && app.ValidateProfileName(e.Name()) == nil {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
 var profileListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List existing profiles",
@@ -42,33 +85,32 @@ var profileListCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		active := app.ActiveProfile(cfg)
+		active := activeProfile(&cfg)
 
-		entries, err := os.ReadDir(p.Profiles)
-		if err != nil && !os.IsNotExist(err) {
+		names, err := listProfiles(p)
+		if err != nil {
 			return err
 		}
-		names := []string{"default"}
-		for _, e := range entries {
-			if e.IsDir() {
-				n := e.Name()
-				if n != "default" {
-					names = append(names, n)
-				}
-			}
-		}
-		sort.Strings(names)
 		for _, n := range names {
 			mark := ""
 			if n == active {
 				mark = " *"
 			}
+			if s, err := app.LoadProfileSettings(p, n); err == nil && s.Description != "" {
+				mark += "\t" + s.Description
+			}
 			fmt.Println(n + mark)
 		}
 		return nil
 	},
 }
 
+// profileUseResult is the stable --output json result of `nmsmods profile use`.
+type profileUseResult struct {
+	Profile string `json:"profile"`
+	Target  string `json:"target"`
+}
+
 var profileUseCmd = &cobra.Command{
 	Use:   "use <name>",
 	Short: "Switch active profile and deploy its enabled mods into the game",
@@ -77,25 +119,34 @@ var profileUseCmd = &cobra.Command{
 		p := mustPaths()
 		name := args[0]
 		if err := app.ValidateProfileName(name); err != nil {
-			return err
+			return &usageError{err: err}
 		}
 
-		return withStateLock(p, func() error {
+		return withHistory(p, cmd, args, func(h *historyRecorder) error {
 			cfg, game, err := requireGame(p)
 			if err != nil {
 				return err
 			}
+			if err := ensureGameNotRunning(); err != nil {
+				return err
+			}
 			cfg.ActiveProfile = name
 			if err := app.SaveConfig(p.Config, *cfg); err != nil {
 				return err
 			}
+			profileOverride = "" // the persistent switch wins over NMSMODS_PROFILE
 			if err := app.EnsureProfileDirs(p, name); err != nil {
 				return err
 			}
-			if err := deployActiveProfile(p, cfg, game.ModsDir); err != nil {
+			if game, err = gameForConfig(p, cfg); err != nil {
 				return err
 			}
+			if err := newManager(p).DeployProfile(*cfg, game); err != nil {
+				return err
+			}
+			setResult(profileUseResult{Profile: name, Target: game.Target})
 			fmt.Println("Switched to profile:", name)
+			warnIfModsDisabled(cmd, game)
 			return nil
 		})
 	},
@@ -104,75 +155,102 @@ var profileUseCmd = &cobra.Command{
 var profileDeployCmd = &cobra.Command{
 	Use:   "deploy",
 	Short: "Deploy enabled mods from the active profile into the game",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		res, err := newManager(mustPaths()).Deploy(cmd.Context())
+		if err != nil {
+			return err
+		}
+		setResult(res)
+		fmt.Println("Deployed active profile:", res.Profile)
+		warnIfModsDisabled(cmd, res.Game)
+		return nil
+	},
+}
+
+var (
+	profileSetDescription string
+	profileSetTarget      string
+	profileSetStrategy    string
+	profileSetNewMods     string
+)
+
+var profileSetCmd = &cobra.Command{
+	Use:   "set [name]",
+	Short: "Change a profile's settings (profiles/<name>/profile.json)",
+	Long: `Change the settings stored in profiles/<name>/profile.json (default: the active profile):
+
+  --description    free text shown by 'profile list'
+  --target         bound game target ("" to unbind; see: nmsmods target list)
+  --strategy       copy (default) or hardlink; hardlinked files share data with the store,
+                   so editing a deployed file also edits the stored copy
+  --new-mods       enabled (default) or disabled: whether install deploys new mods right away`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		p := mustPaths()
 		return withStateLock(p, func() error {
-			cfg, game, err := requireGame(p)
+			cfg, err := loadConfig(p)
 			if err != nil {
 				return err
 			}
-			_, err = ensureActiveProfileDirs(p, cfg)
+			name := activeProfile(&cfg)
+			if len(args) == 1 {
+				name = args[0]
+			}
+			s, err := app.LoadProfileSettings(p, name)
 			if err != nil {
 				return err
 			}
-			if err := deployActiveProfile(p, cfg, game.ModsDir); err != nil {
+			flags := cmd.Flags()
+			if flags.Changed("description") {
+				s.Description = profileSetDescription
+			}
+			if flags.Changed("target") {
+				if profileSetTarget != "" {
+					if _, ok := app.TargetPath(cfg, profileSetTarget); !ok {
+						return fmt.Errorf("unknown target %q (run: nmsmods target list)", profileSetTarget)
+					}
+				}
+				s.Target = profileSetTarget
+			}
+			if flags.Changed("strategy") {
+				switch profileSetStrategy {
+				case app.DeployCopy:
+					s.DeployStrategy = ""
+				case app.DeployHardlink:
+					s.DeployStrategy = profileSetStrategy
+				default:
+					return usageErrorf("--strategy must be %s or %s, got %q", app.DeployCopy, app.DeployHardlink, profileSetStrategy)
+				}
+			}
+			if flags.Changed("new-mods") {
+				switch profileSetNewMods {
+				case "enabled":
+					s.NewModsDisabled = false
+				case "disabled":
+					s.NewModsDisabled = true
+				default:
+					return usageErrorf("--new-mods must be enabled or disabled, got %q", profileSetNewMods)
+				}
+			}
+			if err := app.EnsureProfileDirs(p, name); err != nil {
+				return err
+			}
+			if err := app.SaveProfileSettings(p, name, s); err != nil {
 				return err
 			}
-			fmt.Println("Deployed active profile:", app.ActiveProfile(*cfg))
+			fmt.Println("Profile:", name)
+			printProfileSettings(s)
 			return nil
 		})
 	},
 }
 
-func deployActiveProfile(p *app.Paths, cfg *app.Config, modsDir string) error {
-	st, err := app.LoadState(p.State)
-	if err != nil {
-		return err
-	}
-	active := app.ActiveProfile(*cfg)
-
-	// 1) Undeploy anything we previously deployed (any profile), but only folders we track.
-	for id, me := range st.Mods {
-		changed := false
-		for prof, pi := range me.Installations {
-			if pi.Enabled && pi.Folder != "" {
-				if err := mods.Undeploy(modsDir, pi.Folder, id, prof); err != nil {
-					return fmt.Errorf("failed to undeploy %s (%s): %w", id, prof, err)
-				}
-				pi.DeployedPath = ""
-				// Keep enabled state; we'll redeploy active ones below.
-				me.Installations[prof] = pi
-				changed = true
-			}
-		}
-		if changed {
-			st.Mods[id] = me
-		}
-	}
-
-	// 2) Deploy active profile enabled mods.
-	for id, me := range st.Mods {
-		pi, ok := me.Installations[active]
-		if !ok || !pi.Installed || !pi.Enabled || pi.Folder == "" || pi.Store == "" {
-			continue
-		}
-		storeAbs := filepath.Join(p.Root, filepath.FromSlash(pi.Store))
-		if _, err := os.Stat(storeAbs); err != nil {
-			continue
-		}
-		deployed, err := mods.Deploy(storeAbs, modsDir, pi.Folder, id, active)
-		if err != nil {
-			return err
-		}
-		pi.DeployedPath = deployed
-		me.Installations[active] = pi
-		st.Mods[id] = me
-	}
-
-	return app.SaveState(p.State, st)
-}
-
 func init() {
+	profileSetCmd.Flags().StringVar(&profileSetDescription, "description", "", "Profile description")
+	profileSetCmd.Flags().StringVar(&profileSetTarget, "target", "", "Bound game target (empty to unbind)")
+	profileSetCmd.Flags().StringVar(&profileSetStrategy, "strategy", "", "Deploy strategy: copy or hardlink")
+	profileSetCmd.Flags().StringVar(&profileSetNewMods, "new-mods", "", "New mods are installed enabled or disabled")
+	profileCmd.AddCommand(profileSetCmd)
 	profileCmd.AddCommand(profileStatusCmd)
 	profileCmd.AddCommand(profileListCmd)
 	profileCmd.AddCommand(profileUseCmd)
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"nmsmods/internal/app"
+	"nmsmods/pkg/nmsmods"
+)
+
+// profileTestHome sets up a data directory with a game, the active profile "default" and a
+// second profile "vr", and points the package globals at it for the test.
+func profileTestHome(t *testing.T) (*app.Paths, string) {
+	t.Helper()
+	root := t.TempDir()
+	game := filepath.Join(root, "game")
+	for _, d := range []string{"Binaries", "GAMEDATA/PCBANKS"} {
+		if err := os.MkdirAll(filepath.Join(game, d), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(game, "GAMEDATA", "PCBANKS", "NMSARC.Base.pak"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := nmsmods.DefaultPaths(filepath.Join(root, "home"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := app.SaveConfig(p.Config, app.Config{GamePath: game}); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.SaveProfileSettings(p, "vr", app.ProfileSettings{Description: "headset"}); err != nil {
+		t.Fatal(err)
+	}
+
+	oldHome, oldOverride, oldFlag := homeOverride, profileOverride, profileFlag
+	homeOverride, profileOverride, profileFlag = p.Root, "", ""
+	t.Cleanup(func() { homeOverride, profileOverride, profileFlag = oldHome, oldOverride, oldFlag })
+	return p, game
+}
+
+func TestProfileOverride_ScopesWithoutDeploying(t *testing.T) {
+	p, _ := profileTestHome(t)
+	cfg := app.Config{}
+
+	if !profileDeploys(&cfg) {
+		t.Fatal("active profile does not deploy")
+	}
+	profileOverride = "vr"
+	if err := checkProfileOverride(); err != nil {
+		t.Fatal(err)
+	}
+	if profileDeploys(&cfg) {
+		t.Fatal("--profile vr deploys")
+	}
+	rp, err := resolveProfile(p, &cfg)
+	if err != nil || rp.Name != "vr" || rp.Settings.Description != "headset" {
+		t.Fatalf("resolveProfile = %+v, %v", rp, err)
+	}
+
+	for _, name := range []string{"vrr", "../x"} {
+		profileOverride = name
+		var usage *usageError
+		if err := checkProfileOverride(); !errors.As(err, &usage) {
+			t.Errorf("override %q: %v, want a usage error", name, err)
+		}
+	}
+	if _, err := os.Stat(app.ProfileRoot(p, "vrr")); !os.IsNotExist(err) {
+		t.Fatalf("rejected profile was created: %v", err)
+	}
+}
+
+func TestInstallUnderProfile_LeavesGameAlone(t *testing.T) {
+	p, game := profileTestHome(t)
+
+	zipPath := filepath.Join(t.TempDir(), "MyMod.zip")
+	f, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	w, err := zw.Create("MyMod/METADATA/A.MBIN")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("x"))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	if _, err := nmsmods.New(p).Download(context.Background(), zipPath, "my-mod"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ExecuteWithArgs([]string{"--profile", "vr", "install", "my-mod"}, io.Discard, io.Discard); err != nil {
+		t.Fatal(err)
+	}
+
+	ents, err := os.ReadDir(filepath.Join(game, "GAMEDATA", "MODS"))
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	if len(ents) != 0 {
+		t.Fatalf("GAMEDATA/MODS touched: %v", ents)
+	}
+	st, err := app.PeekState(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	me := st.Mods["my-mod"]
+	if pi := me.Installations["vr"]; !pi.Installed || !pi.Enabled || pi.DeployedPath != "" {
+		t.Fatalf("vr install: %+v", pi)
+	}
+	if pi := me.Installations["default"]; pi.Installed {
+		t.Fatalf("default profile changed: %+v", pi)
+	}
+}
+
+func TestProfileSet_BadValueIsUsageError(t *testing.T) {
+	p, _ := profileTestHome(t)
+	err := ExecuteWithArgs([]string{"profile", "set", "vr", "--strategy", "symlink"}, io.Discard, io.Discard)
+	if _, exit := errorCode(err); exit != exitUsage {
+		t.Fatalf("profile set --strategy symlink: %v (exit %d), want exit %d", err, exit, exitUsage)
+	}
+	if s, err := app.LoadProfileSettings(p, "vr"); err != nil || s.DeployStrategy != "" {
+		t.Fatalf("settings changed: %+v, %v", s, err)
+	}
+}
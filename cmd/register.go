@@ -8,12 +8,23 @@ func registerCommands(root *cobra.Command) {
 
 	root.AddCommand(whereCmd)
 	root.AddCommand(setPathCmd)
+	root.AddCommand(targetCmd)
 	root.AddCommand(doctorCmd)
+	root.AddCommand(fsckCmd)
+	root.AddCommand(gameCmd)
 
 	root.AddCommand(downloadCmd)
 	root.AddCommand(downloadsCmd)
+	root.AddCommand(watchCmd)
 	root.AddCommand(infoCmd)
+	root.AddCommand(tagCmd)
+	root.AddCommand(noteCmd)
 	root.AddCommand(verifyCmd)
+	root.AddCommand(scanStaleCmd)
+	root.AddCommand(inspectCmd)
+	root.AddCommand(orderCmd)
+	root.AddCommand(tuiCmd)
+	root.AddCommand(serveCmd)
 
 	root.AddCommand(installCmd)
 	root.AddCommand(installDirCmd)
@@ -21,12 +32,20 @@ func registerCommands(root *cobra.Command) {
 	root.AddCommand(enableCmd)
 	root.AddCommand(disableCmd)
 	root.AddCommand(profileCmd)
+	root.AddCommand(playCmd)
 	root.AddCommand(completionCmd)
 
 	root.AddCommand(installedCmd)
 	root.AddCommand(uninstallCmd)
 	root.AddCommand(rmDownloadCmd)
 
+	root.AddCommand(historyCmd)
+	root.AddCommand(undoCmd)
+	root.AddCommand(redoCmd)
+	root.AddCommand(snapshotCmd)
+	root.AddCommand(trashCmd)
+	root.AddCommand(stateCmd)
+
 	root.AddCommand(cleanCmd)
 	root.AddCommand(resetCmd)
 
@@ -35,4 +54,6 @@ func registerCommands(root *cobra.Command) {
 
 	// Nexus Mod Manager (nxm://) one-click handler
 	root.AddCommand(nxmCmd)
+
+	registerFlagCompletions(root)
 }
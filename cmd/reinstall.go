@@ -2,11 +2,9 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
 
-	"nmsmods/internal/app"
-	"nmsmods/internal/mods"
+	"nmsmods/pkg/nmsmods"
 
 	"github.com/spf13/cobra"
 )
@@ -14,144 +12,74 @@ import (
 var reinstallDryRun bool
 var reinstallNoOverwrite bool
 
+var reinstallSel modSelectorFlags
+
 var reinstallCmd = &cobra.Command{
-	Use:   "reinstall <id-or-index>",
+	Use:   "reinstall <mod-or-glob>... | --all | --source/--health/--tag/--category/--outdated",
 	Short: "Reinstall a mod in the active profile (replaces the stored folder and redeploys)",
-	Args:  cobra.ExactArgs(1),
+	Long: `Reinstall mods in the active profile (or --profile) from their downloaded zip: the stored folder
+is replaced (the old one goes to the trash) and enabled mods are redeployed. Several mods and
+the selectors (see: nmsmods enable --help) run as one operation with one redeploy at the end.`,
+	Args: cobra.ArbitraryArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		p := mustPaths()
-
-		return withStateLock(p, func() error {
-			cfg, game, err := requireGame(p)
-			if err != nil {
-				return err
-			}
-			profile, err := ensureActiveProfileDirs(p, cfg)
-			if err != nil {
-				return err
-			}
-
-			st, err := app.LoadState(p.State)
-			if err != nil {
-				return err
-			}
-			id, err := resolveModArg(args[0], st)
-			if err != nil {
-				return err
-			}
-			me := st.Mods[id]
-			if me.ZIP == "" {
-				return fmt.Errorf("no zip recorded for %s", id)
-			}
-			zipAbs := joinPathFromState(p.Root, me.ZIP)
-
-			folderGuess, err := mods.ProposedInstallFolderFromZip(zipAbs, id)
-			if err != nil {
-				return err
-			}
-
-			if me.Installations == nil {
-				me.Installations = map[string]app.ProfileInstall{}
-			}
-			pi := me.Installations[profile]
-			destFolder := pi.Folder
-			if destFolder == "" {
-				destFolder = folderGuess
-			}
-			destFolder, _ = mods.ResolveFolderCollision(id, destFolder, profile, st)
-
-			storeAbs := filepath.Join(app.ProfileModsDir(p, profile), destFolder)
-			deployAbs := filepath.Join(game.ModsDir, destFolder)
-
-			if reinstallDryRun {
-				fmt.Println("[dry-run] Would reinstall:")
-				fmt.Println("  profile:", profile)
-				fmt.Println("  id:     ", id)
-				fmt.Println("  zip:    ", zipAbs)
-				fmt.Println("  folder: ", destFolder)
-				fmt.Println("  store:  ", storeAbs)
-				fmt.Println("  deploy: ", deployAbs)
-				fmt.Println("  action:  REPLACE store + REDEPLOY")
-				return nil
-			}
-
-			if fileExists(storeAbs) {
-				if reinstallNoOverwrite {
-					return fmt.Errorf("store exists: %s (use without --no-overwrite to replace it)", storeAbs)
-				}
-				fmt.Println("Removing existing store:", storeAbs)
-				_ = os.RemoveAll(storeAbs)
-			}
-
-			stageDir := filepath.Join(p.Staging, id)
-			_ = os.RemoveAll(stageDir)
-			if err := os.MkdirAll(stageDir, 0o755); err != nil {
-				return err
-			}
-
-			fmt.Println("Extracting to:", stageDir)
-			if err := mods.ExtractZip(zipAbs, stageDir); err != nil {
-				return err
-			}
-
-			folder, srcPath, err := mods.ChooseInstallFolder(stageDir, id)
+		m := newManager(mustPaths(), commandLineOption(cmd, args))
+		opts := nmsmods.ReinstallOptions{NoOverwrite: reinstallNoOverwrite, DryRun: reinstallDryRun}
+		if !reinstallSel.batch(args) {
+			res, err := m.Reinstall(cmd.Context(), args[0], opts)
 			if err != nil {
 				return err
 			}
-			folder, _ = mods.ResolveFolderCollision(id, folder, profile, st)
-			storeAbs = filepath.Join(app.ProfileModsDir(p, profile), folder)
-
-			fmt.Println("Installing into profile store:", storeAbs)
-			if err := mods.CopyDir(srcPath, storeAbs); err != nil {
-				return err
-			}
-
-			// Redeploy if enabled (default to enabled).
-			enabled := true
-			if pi.Installed {
-				enabled = pi.Enabled
-			}
-			pi.Installed = true
-			pi.Enabled = enabled
-			pi.Folder = folder
-			pi.Store = filepath.ToSlash(filepath.Join("profiles", profile, "mods", folder))
-			pi.InstalledAt = app.NowRFC3339()
-
-			if enabled {
-				deployed, err := mods.Deploy(storeAbs, game.ModsDir, folder, id, profile)
-				if err != nil {
-					return err
-				}
-				pi.DeployedPath = deployed
-			}
-
-			ok, verr := mods.HasRelevantFiles(storeAbs)
-			if verr != nil || !ok {
-				fmt.Println("Warning: installed folder contains no .EXML/.MBIN files (or health check failed)")
-				me.Health = "warning"
-			} else {
-				me.Health = "ok"
+			setResult(res)
+			printReinstalled(res)
+			if !res.DryRun && res.Enabled && res.DeployPath == "" {
+				printNotDeployed(res.Profile)
 			}
-
-			me.Installations[profile] = pi
-			// Legacy best-effort
-			me.Folder = folder
-			me.Installed = enabled
-			me.InstalledAt = pi.InstalledAt
-			me.InstalledPath = pi.DeployedPath
-
-			st.Mods[id] = me
-			if err := app.SaveState(p.State, st); err != nil {
-				return err
-			}
-
-			fmt.Println("Reinstalled:", id, "(profile:", profile+")")
 			return nil
-		})
+		}
+
+		results, err := m.ReinstallMods(cmd.Context(), reinstallSel.selector(args), opts)
+		if err != nil {
+			return err
+		}
+		setResult(results)
+		if len(results) == 0 {
+			fmt.Println("No mods matched.")
+			return nil
+		}
+		for _, r := range results {
+			printReinstalled(r)
+		}
+		if reinstallDryRun || results[0].DeployPath == "" {
+			return nil
+		}
+		fmt.Printf("Redeployed profile %s (%d mod(s) reinstalled)\n", results[0].Profile, len(results))
+		warnIfModsDisabled(cmd, results[0].Game)
+		return nil
 	},
 }
 
+// printReinstalled reports a reinstall, or with --dry-run what it would do.
+func printReinstalled(res nmsmods.InstallResult) {
+	if !res.DryRun {
+		fmt.Println("Reinstalled:", res.ID, "(profile:", res.Profile+")")
+		return
+	}
+	fmt.Println("[dry-run] Would reinstall:")
+	fmt.Println("  profile:", res.Profile)
+	fmt.Println("  id:     ", res.ID)
+	fmt.Println("  zip:    ", res.ZIPPath)
+	fmt.Println("  folder: ", res.Folder)
+	fmt.Println("  store:  ", res.StorePath)
+	fmt.Println("  deploy: ", filepath.Join(res.Game.ModsDir, res.Folder))
+	if res.DeployPath != "" {
+		fmt.Println("  action:  REPLACE store + REDEPLOY")
+	} else {
+		fmt.Println("  action:  REPLACE store (profile not active; nothing is deployed)")
+	}
+}
+
 func init() {
 	reinstallCmd.Flags().BoolVar(&reinstallDryRun, "dry-run", false, "Print what would happen without making changes")
 	reinstallCmd.Flags().BoolVar(&reinstallNoOverwrite, "no-overwrite", false, "Do not overwrite if destination in the profile store already exists")
+	reinstallSel.register(reinstallCmd)
 }
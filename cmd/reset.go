@@ -5,6 +5,8 @@ import (
 	"os"
 	"path/filepath"
 
+	"nmsmods/internal/app"
+
 	"github.com/spf13/cobra"
 )
 
@@ -18,13 +20,16 @@ var resetCmd = &cobra.Command{
 	Long: `Reset removes local nmsmods files under the configured home directory.
 
 Default behavior:
-- remove state.json
+- remove state.json (and state.db)
 - clean staging/
 
 Options:
 - --all: remove the entire nmsmods home directory and recreate it
 - --keep-downloads: keep downloads/ (default true unless --all)
-- --dry-run: show what would be removed without deleting`,
+- --dry-run: show what would be removed without deleting
+
+state.json, downloads/ and the --all home are moved to the trash (see: nmsmods trash list)
+and can be brought back with: nmsmods trash restore <id>`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		p := mustPaths()
 
@@ -32,7 +37,7 @@ Options:
 			actions := []string{}
 
 			if resetAll {
-				actions = append(actions, fmt.Sprintf("remove: %s", p.Root))
+				actions = append(actions, fmt.Sprintf("trash:  %s", p.Root))
 				if resetDryRun {
 					fmt.Println("[dry-run] Would reset:")
 					for _, a := range actions {
@@ -40,18 +45,28 @@ Options:
 					}
 					return nil
 				}
-				if err := os.RemoveAll(p.Root); err != nil {
+				it, err := trashPath(p, p.Root, app.TrashHome, "", commandLine(cmd, args))
+				if err != nil {
+					return err
+				}
+				if err := p.Ensure(); err != nil {
 					return err
 				}
-				return p.Ensure()
+				fmt.Println("Reset completed. Previous home kept in trash:", it.ID)
+				return nil
 			}
 
 			// default: remove state.json and staging/
-			actions = append(actions, fmt.Sprintf("remove: %s", p.State))
+			actions = append(actions, fmt.Sprintf("trash:  %s", p.State))
+			for _, f := range append([]string{p.StateDB}, stateBackups(p)...) {
+				if fileExists(f) {
+					actions = append(actions, fmt.Sprintf("trash:  %s", f))
+				}
+			}
 			actions = append(actions, fmt.Sprintf("clean:  %s", p.Staging))
 
 			if !resetKeepDownloads {
-				actions = append(actions, fmt.Sprintf("remove: %s", p.Downloads))
+				actions = append(actions, fmt.Sprintf("trash:  %s", p.Downloads))
 			}
 
 			if resetDryRun {
@@ -62,12 +77,23 @@ Options:
 				return nil
 			}
 
-			_ = os.Remove(p.State)
+			command := commandLine(cmd, args)
+			for _, f := range append([]string{p.State, p.StateDB}, stateBackups(p)...) {
+				if fileExists(f) {
+					if _, err := trashPath(p, f, app.TrashState, "", command); err != nil {
+						return err
+					}
+				}
+			}
 			_ = os.RemoveAll(p.Staging)
 			_ = os.MkdirAll(p.Staging, 0o755)
 
 			if !resetKeepDownloads {
-				_ = os.RemoveAll(p.Downloads)
+				if fileExists(p.Downloads) {
+					if _, err := trashPath(p, p.Downloads, app.TrashDownload, "", command); err != nil {
+						return err
+					}
+				}
 				_ = os.MkdirAll(p.Downloads, 0o755)
 			}
 
@@ -80,6 +106,17 @@ Options:
 	},
 }
 
+// stateBackups lists the rotated state.json backups (state.json.1, ...) that exist.
+func stateBackups(p *app.Paths) []string {
+	var out []string
+	for n := 1; n <= app.StateBackups; n++ {
+		if f := app.StateBackupPath(p.State, n); fileExists(f) {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
 func init() {
 	resetCmd.Flags().BoolVar(&resetAll, "all", false, "Remove the entire nmsmods home directory and recreate it")
 	resetCmd.Flags().BoolVar(&resetKeepDownloads, "keep-downloads", true, "Keep downloads/ directory (ignored with --all)")
@@ -10,14 +10,14 @@ import (
 )
 
 var rmDownloadCmd = &cobra.Command{
-	Use:   "rm-download <id-or-index>",
-	Short: "Delete a downloaded ZIP from the downloads folder (does not uninstall)",
+	Use:   "rm-download <mod>",
+	Short: "Move a downloaded ZIP to the trash (does not uninstall)",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		p := mustPaths()
 
 		return withStateLock(p, func() error {
-			st, err := app.LoadState(p.State)
+			st, err := loadState(p)
 			if err != nil {
 				return err
 			}
@@ -33,7 +33,7 @@ var rmDownloadCmd = &cobra.Command{
 				installed := app.IsInstalledInAnyProfile(me)
 				if !installed && (me.Source == "local" || me.Source == "url") {
 					delete(st.Mods, id)
-					if err := app.SaveState(p.State, st); err != nil {
+					if err := saveState(p, st); err != nil {
 						return err
 					}
 					fmt.Println("Removed record:", id)
@@ -44,7 +44,7 @@ var rmDownloadCmd = &cobra.Command{
 
 			zipAbs := joinPathFromState(p.Root, me.ZIP)
 			if _, err := os.Stat(zipAbs); err == nil {
-				if err := os.Remove(zipAbs); err != nil {
+				if _, err := trashPath(p, zipAbs, app.TrashDownload, id, commandLine(cmd, args)); err != nil {
 					return err
 				}
 			}
@@ -59,11 +59,11 @@ var rmDownloadCmd = &cobra.Command{
 				st.Mods[id] = me
 			}
 
-			if err := app.SaveState(p.State, st); err != nil {
+			if err := saveState(p, st); err != nil {
 				return err
 			}
 
-			fmt.Println("Removed download:", zipAbs)
+			fmt.Println("Moved download to trash:", zipAbs)
 			return nil
 		})
 	},
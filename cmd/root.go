@@ -4,13 +4,19 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
+	"sync"
 
 	"nmsmods/internal/app"
+	"nmsmods/internal/logging"
 
 	"github.com/spf13/cobra"
 )
 
 var homeOverride string
+var gameTargetOverride string
+var profileFlag string
+var wrapArgsOnce sync.Once
 
 var rootCmd = &cobra.Command{
 	Use:     "nmsmods",
@@ -23,11 +29,13 @@ var rootCmd = &cobra.Command{
 
 func Execute() {
 	if err := ExecuteWithArgs(os.Args[1:], os.Stdout, os.Stderr); err != nil {
-		// Keep stderr stable and avoid Cobra printing twice.
-		fmt.Fprintln(os.Stderr, err.Error())
-		// For now exit with 1 for any command error.
-		// (Later phases may introduce richer exit codes.)
-		os.Exit(1)
+		// With --output json the error was already printed as a JSON object on stdout.
+		if outputFormat != outputJSON {
+			// Keep stderr stable and avoid Cobra printing twice.
+			fmt.Fprintln(os.Stderr, err.Error())
+		}
+		_, code := errorCode(err)
+		os.Exit(code)
 	}
 }
 
@@ -36,20 +44,68 @@ func ExecuteWithArgs(args []string, out io.Writer, errOut io.Writer) error {
 	rootCmd.SetOut(out)
 	rootCmd.SetErr(errOut)
 	rootCmd.SetArgs(args)
-	return rootCmd.Execute()
+	// Subcommands are attached by init functions in other files; wrap them once all exist.
+	wrapArgsOnce.Do(func() { wrapArgErrors(rootCmd) })
+	if scanOutputFlag(args) != outputJSON {
+		err := rootCmd.Execute()
+		finishLogging(err)
+		return err
+	}
+	outputFormat = outputJSON
+	// Usage text would break the single JSON object on stdout.
+	rootCmd.SilenceUsage = true
+	err := rootCmd.Execute()
+	if err != nil && currentRun == nil {
+		// Failed before the command started (flags, arguments): name it anyway.
+		currentRun = &jsonRun{command: rootCmd.CommandPath()}
+		if c, _, ferr := rootCmd.Find(args); ferr == nil {
+			currentRun.command = c.CommandPath()
+		}
+	}
+	finishJSONOutput(out, err)
+	finishLogging(err)
+	return err
 }
 
 func init() {
 	rootCmd.PersistentFlags().StringVar(&homeOverride, "home", "", "Override nmsmods data directory (defaults to $NMSMODS_HOME, ~/.nmsmods, or XDG dirs)")
+	rootCmd.PersistentFlags().StringVar(&gameTargetOverride, "game-target", "", "Game target to deploy to (see: nmsmods target list; default: the profile's binding, then default_target)")
+	rootCmd.PersistentFlags().StringVar(&profileFlag, "profile", "", "Operate on this profile's store and state without switching to it (default: active_profile)")
+	rootCmd.PersistentFlags().StringVar(&outputFormat, "output", outputText, "Output format: text or json (one JSON object per run; see README for exit codes)")
+	rootCmd.PersistentFlags().CountVarP(&verbosity, "verbose", "v", "Log to stderr (-v info, -vv debug); the log file under the state dir always has everything")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", logging.FormatText, "Format of -v logs on stderr: text or json")
 	rootCmd.SetVersionTemplate("nmsmods {{.Version}}\n")
 
 	// If a command supports --json and the flag is set, suppress usage on errors.
 	// This keeps stdout as valid JSON (no usage text appended).
-	rootCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		// <TAB> runs the hidden completion command; it neither logs nor prints JSON.
+		if isCompletionRequest(cmd) {
+			return nil
+		}
+		if err := setupLogging(cmd, args); err != nil {
+			return err
+		}
 		if f := cmd.Flags().Lookup("json"); f != nil && f.Changed {
 			cmd.SilenceUsage = true
 		}
+		if outputFormat != outputText && outputFormat != outputJSON {
+			return usageErrorf("invalid --output %q (use text or json)", outputFormat)
+		}
+		if outputFormat == outputJSON {
+			if err := beginJSONOutput(cmd); err != nil {
+				return err
+			}
+		}
+		// --profile scopes this invocation only; it wins over NMSMODS_PROFILE.
+		if name := strings.TrimSpace(profileFlag); name != "" {
+			profileOverride = name
+		}
+		return checkProfileOverride()
 	}
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return &usageError{err: err}
+	})
 
 	registerCommands(rootCmd)
 }
@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"nmsmods/internal/app"
+	"nmsmods/internal/nms"
+	"nmsmods/internal/nms/mbin"
+	"nmsmods/internal/nms/psarc"
+
+	"github.com/spf13/cobra"
+)
+
+var scanStaleJSON bool
+var scanStaleReference string
+
+// staleRow is the stable JSON shape for `nmsmods scan-stale --json`.
+type staleRow struct {
+	ID      string            `json:"id"`
+	Folder  string            `json:"folder"`
+	Store   string            `json:"store"`
+	Checked int               `json:"checked"`
+	Unknown int               `json:"unknown"`
+	Stale   []mbin.FileReport `json:"stale"`
+	Error   string            `json:"error,omitempty"`
+}
+
+// gameMBINReference returns the reference used to compare mod MBIN headers against the game.
+// An explicit directory (e.g. unpacked PCBANKS content) wins; otherwise the PCBANKS .pak archives
+// are read directly, followed by any loose files under PCBANKS.
+// The returned func closes opened archives and must always be called.
+func gameMBINReference(game *nms.Game, override string) (*mbin.Reference, func(), error) {
+	noop := func() {}
+	if override != "" {
+		fi, err := os.Stat(override)
+		if err != nil {
+			return nil, noop, err
+		}
+		if !fi.IsDir() {
+			return nil, noop, fmt.Errorf("reference is not a directory: %s", override)
+		}
+		return mbin.NewReference(os.DirFS(override)), noop, nil
+	}
+	if game == nil || game.BanksDir == "" {
+		return mbin.NewReference(), noop, nil
+	}
+
+	archives, closeAll := openBankArchives(game)
+	sources := make([]fs.FS, 0, len(archives)+1)
+	for _, a := range archives {
+		sources = append(sources, a)
+	}
+	sources = append(sources, os.DirFS(game.BanksDir))
+	return mbin.NewReference(sources...), closeAll, nil
+}
+
+// openBankArchives opens every readable PCBANKS archive (unreadable ones are skipped).
+// Later archives are searched first, matching how patch banks override earlier ones.
+func openBankArchives(game *nms.Game) ([]*psarc.Archive, func()) {
+	paks, _ := nms.ListBankArchives(game)
+	var out []*psarc.Archive
+	for i := len(paks) - 1; i >= 0; i-- {
+		a, err := psarc.Open(paks[i])
+		if err != nil {
+			continue
+		}
+		out = append(out, a)
+	}
+	return out, func() {
+		for _, a := range out {
+			_ = a.Close()
+		}
+	}
+}
+
+// bestEffortMBINReference is used by read-only commands that should still work without a valid game path.
+func bestEffortMBINReference(p *app.Paths) (*mbin.Reference, func()) {
+	noop := func() {}
+	cfg, err := loadConfig(p)
+	if err != nil {
+		return nil, noop
+	}
+	game, err := resolveGame(p, &cfg)
+	if err != nil {
+		return nil, noop
+	}
+	ref, closeRef, err := gameMBINReference(game, "")
+	if err != nil {
+		return nil, noop
+	}
+	return ref, closeRef
+}
+
+func staleMBINPaths(dir string, ref *mbin.Reference) []string {
+	if ref.Empty() {
+		return nil
+	}
+	reps, err := mbin.CheckDir(dir, ref)
+	if err != nil {
+		return nil
+	}
+	var out []string
+	for _, r := range mbin.Stale(reps) {
+		out = append(out, r.Path)
+	}
+	return out
+}
+
+var scanStaleCmd = &cobra.Command{
+	Use:   "scan-stale [mod]",
+	Short: "Report mods whose MBIN headers differ from the game's own files (likely built for an older release)",
+	Args:  cobra.RangeArgs(0, 1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p := mustPaths()
+		cfg, game, err := requireGame(p)
+		if err != nil {
+			return err
+		}
+		profile := activeProfile(cfg)
+
+		st, err := loadState(p)
+		if err != nil {
+			return err
+		}
+
+		ids := sortedModIDs(st)
+		if len(args) == 1 {
+			id, err := resolveModArg(args[0], st)
+			if err != nil {
+				return err
+			}
+			ids = []string{id}
+		}
+
+		ref, closeRef, err := gameMBINReference(game, scanStaleReference)
+		if err != nil {
+			return err
+		}
+		defer closeRef()
+
+		out := make([]staleRow, 0, len(ids))
+		for _, id := range ids {
+			pi, ok := st.Mods[id].Installations[profile]
+			if !ok || !pi.Installed || pi.Store == "" {
+				continue
+			}
+			row := staleRow{
+				ID:     id,
+				Folder: pi.Folder,
+				Store:  joinPathFromState(p.Root, pi.Store),
+				Stale:  []mbin.FileReport{},
+			}
+			reps, err := mbin.CheckDir(row.Store, ref)
+			if err != nil {
+				row.Error = err.Error()
+				out = append(out, row)
+				continue
+			}
+			row.Checked = len(reps)
+			row.Stale = mbin.Stale(reps)
+			for _, r := range reps {
+				if r.Status == mbin.StatusUnknown {
+					row.Unknown++
+				}
+			}
+			out = append(out, row)
+		}
+
+		if scanStaleJSON {
+			b, _ := json.MarshalIndent(out, "", "  ")
+			fmt.Fprintln(cmd.OutOrStdout(), string(b))
+			return nil
+		}
+
+		if ref.Empty() {
+			fmt.Fprintln(cmd.OutOrStdout(), "Note: no game reference available (PCBANKS unreadable; use --reference <unpacked game dir>)")
+		}
+		if len(out) == 0 {
+			fmt.Fprintf(cmd.OutOrStdout(), "No installed mods in profile %q.\n", profile)
+			return nil
+		}
+		for _, r := range out {
+			switch {
+			case r.Error != "":
+				fmt.Fprintf(cmd.OutOrStdout(), "- %s: error: %s\n", r.ID, r.Error)
+			case r.Checked == 0:
+				fmt.Fprintf(cmd.OutOrStdout(), "- %s: no MBIN files\n", r.ID)
+			case len(r.Stale) == 0:
+				fmt.Fprintf(cmd.OutOrStdout(), "- %s: ok (%d MBIN, %d without game counterpart)\n", r.ID, r.Checked, r.Unknown)
+			default:
+				fmt.Fprintf(cmd.OutOrStdout(), "- %s: %d stale of %d MBIN\n", r.ID, len(r.Stale), r.Checked)
+				for _, f := range r.Stale {
+					if f.Reason != "" {
+						fmt.Fprintf(cmd.OutOrStdout(), "    %s (%s)\n", f.Path, f.Reason)
+					} else {
+						fmt.Fprintf(cmd.OutOrStdout(), "    %s\n", f.Path)
+					}
+				}
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	scanStaleCmd.Flags().BoolVar(&scanStaleJSON, "json", false, "Output in JSON format")
+	scanStaleCmd.Flags().StringVar(&scanStaleReference, "reference", "", "Directory with unpacked game files to compare against (defaults to the GAMEDATA/PCBANKS archives)")
+}
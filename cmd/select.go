@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"nmsmods/pkg/nmsmods"
+
+	"github.com/spf13/cobra"
+)
+
+// modSelectorFlags are the batch selectors of enable, disable, uninstall and reinstall.
+type modSelectorFlags struct {
+	all      bool
+	source   string
+	health   string
+	tags     []string
+	category string
+	outdated bool
+}
+
+func (f *modSelectorFlags) register(c *cobra.Command) {
+	c.Flags().BoolVar(&f.all, "all", false, "Select every mod installed in the profile")
+	c.Flags().StringVar(&f.source, "source", "", "Only mods from this source: nexus, url or local")
+	c.Flags().StringVar(&f.health, "health", "", "Only mods with this health: ok or warning")
+	c.Flags().StringSliceVar(&f.tags, "tag", nil, "Only mods with this tag (repeatable; any of them)")
+	c.Flags().StringVar(&f.category, "category", "", "Only mods in this Nexus category (e.g. Visuals)")
+	c.Flags().BoolVar(&f.outdated, "outdated", false, "Only mods the last 'nmsmods nexus check-updates' found an update for")
+}
+
+func (f *modSelectorFlags) selector(args []string) nmsmods.Selector {
+	return nmsmods.Selector{
+		Args: args, All: f.all, Source: f.source, Health: f.health, Tags: f.tags,
+		Category: f.category, Outdated: f.outdated, Installed: true,
+	}
+}
+
+// batch reports whether the invocation names anything but exactly one id or handle. Those
+// keep their single-mod behaviour and output.
+func (f *modSelectorFlags) batch(args []string) bool {
+	sel := f.selector(args)
+	return f.all || sel.HasFilters() || len(args) != 1 || nmsmods.IsGlob(args[0])
+}
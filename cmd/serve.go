@@ -0,0 +1,371 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"nmsmods/internal/app"
+	"nmsmods/pkg/nmsmods"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	serveSocket    string
+	serveListen    string
+	serveTokenFile string
+)
+
+var serveCmd = &cobra.Command{
+	Use:         "serve",
+	Short:       "Serve a local HTTP API for front ends and scripts",
+	Annotations: map[string]string{textOnlyAnnotation: "true"},
+	Long: `Serve a local HTTP API on a unix socket (--socket) or a loopback address (--listen).
+
+Every request needs "Authorization: Bearer <token>"; the token is read from --token-file
+(default: api-token in the data directory, created with mode 0600 on first start).
+
+  GET  /v1/mods                       mods with the profile's state (same shape as downloads --json)
+  GET  /v1/profiles                   profiles and their settings
+  POST /v1/mods/{id}/install          also: reinstall, enable, disable, uninstall
+  POST /v1/deploy                     deploy the active profile
+  POST /v1/profiles/{name}/use        switch the active profile
+  POST /v1/update-check               check Nexus for updates
+
+{id} is one mod id or handle: flags and globs are rejected with 400, as are invalid profile
+names. Add ?profile=<name> to scope a call like --profile. POST calls answer with JSON
+{"ok", "command", "output", "result", "error"}; with "Accept: text/event-stream" they stream
+"progress" events (one per output line) and a final "done" event with the same JSON.
+
+Operations run as nmsmods child processes, one at a time, and take the same state lock as
+the CLI: a CLI command running at the same moment makes the call fail with 409 (lock busy)
+instead of interleaving writes.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p := mustPaths()
+		if (serveSocket == "") == (serveListen == "") {
+			return fmt.Errorf("use exactly one of --socket <path> or --listen 127.0.0.1:<port>")
+		}
+		tokenPath := serveTokenFile
+		if tokenPath == "" {
+			tokenPath = app.APITokenPath(p)
+		}
+		token, err := app.LoadOrCreateAPIToken(tokenPath)
+		if err != nil {
+			return err
+		}
+
+		ln, where, err := serveListener()
+		if err != nil {
+			return err
+		}
+		if serveSocket != "" {
+			defer os.Remove(serveSocket)
+		}
+
+		s := &apiServer{p: p, token: token}
+		srv := &http.Server{Handler: s.routes(), ReadHeaderTimeout: 10 * time.Second}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		go func() {
+			<-ctx.Done()
+			shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_ = srv.Shutdown(shutdown)
+		}()
+
+		fmt.Fprintf(cmd.OutOrStdout(), "Serving the nmsmods API on %s (token: %s)\n", where, tokenPath)
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	},
+}
+
+// serveListener opens the unix socket (mode 0600) or the loopback TCP address.
+func serveListener() (net.Listener, string, error) {
+	if serveSocket != "" {
+		if fi, err := os.Lstat(serveSocket); err == nil {
+			if fi.Mode()&os.ModeSocket == 0 {
+				return nil, "", fmt.Errorf("refusing to replace %s: not a socket", serveSocket)
+			}
+			// A stale socket from a previous run; a live server would still accept connections.
+			if c, err := net.Dial("unix", serveSocket); err == nil {
+				c.Close()
+				return nil, "", fmt.Errorf("another server is listening on %s", serveSocket)
+			}
+			_ = os.Remove(serveSocket)
+		}
+		ln, err := net.Listen("unix", serveSocket)
+		if err != nil {
+			return nil, "", err
+		}
+		if err := os.Chmod(serveSocket, 0o600); err != nil {
+			ln.Close()
+			return nil, "", err
+		}
+		return ln, "unix:" + serveSocket, nil
+	}
+
+	host, _, err := net.SplitHostPort(serveListen)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid --listen address %q: %w", serveListen, err)
+	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return nil, "", fmt.Errorf("--listen must be a loopback address (127.0.0.1, ::1 or localhost), got %q", host)
+	}
+	ln, err := net.Listen("tcp", serveListen)
+	if err != nil {
+		return nil, "", err
+	}
+	return ln, "http://" + ln.Addr().String(), nil
+}
+
+type apiServer struct {
+	p     *app.Paths
+	token string
+	mu    sync.Mutex // one operation at a time; the state lock then guards against the CLI
+}
+
+// apiResult is the stable JSON shape of POST responses and of the final "done" event.
+type apiResult struct {
+	OK      bool            `json:"ok"`
+	Command string          `json:"command"`
+	Output  []string        `json:"output"`
+	Result  json.RawMessage `json:"result,omitempty"` // stdout of --json commands
+	Error   string          `json:"error,omitempty"`
+}
+
+// apiProfile is the stable JSON shape of one entry in GET /v1/profiles.
+type apiProfile struct {
+	Name   string `json:"name"`
+	Active bool   `json:"active"`
+	app.ProfileSettings
+}
+
+func (s *apiServer) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/mods", func(w http.ResponseWriter, r *http.Request) {
+		s.runJSON(w, r, "downloads", "--json")
+	})
+	mux.HandleFunc("GET /v1/profiles", s.handleProfiles)
+	mux.HandleFunc("POST /v1/mods/{id}/{op}", func(w http.ResponseWriter, r *http.Request) {
+		switch op := r.PathValue("op"); op {
+		case "install", "reinstall", "enable", "disable", "uninstall":
+			id := r.PathValue("id")
+			if err := apiModArg(id); err != nil {
+				apiError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			s.run(w, r, false, op, "--", id)
+		default:
+			apiError(w, http.StatusNotFound, "unknown operation: "+op)
+		}
+	})
+	mux.HandleFunc("POST /v1/deploy", func(w http.ResponseWriter, r *http.Request) {
+		s.run(w, r, false, "profile", "deploy")
+	})
+	mux.HandleFunc("POST /v1/profiles/{name}/use", func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("name")
+		if err := app.ValidateProfileName(name); err != nil {
+			apiError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		s.run(w, r, false, "profile", "use", "--", name)
+	})
+	mux.HandleFunc("POST /v1/update-check", func(w http.ResponseWriter, r *http.Request) {
+		s.run(w, r, true, "nexus", "check-updates", "--json")
+	})
+	return s.auth(mux)
+}
+
+func (s *apiServer) auth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(strings.TrimSpace(got)), []byte(s.token)) != 1 {
+			slog.Warn("api request rejected", "method", r.Method, "path", r.URL.Path, "remote", r.RemoteAddr)
+			apiError(w, http.StatusUnauthorized, "missing or invalid bearer token")
+			return
+		}
+		slog.Info("api request", "method", r.Method, "path", r.URL.Path, "query", r.URL.RawQuery)
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *apiServer) handleProfiles(w http.ResponseWriter, r *http.Request) {
+	cfg, err := loadConfig(s.p)
+	if err != nil {
+		apiError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	names, err := listProfiles(s.p)
+	if err != nil {
+		apiError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	out := []apiProfile{}
+	for _, n := range names {
+		settings, err := app.LoadProfileSettings(s.p, n)
+		if err != nil {
+			apiError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		out = append(out, apiProfile{Name: n, Active: n == app.ActiveProfile(cfg), ProfileSettings: settings})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// apiModArg rejects a mod from the URL that the child command would not take as one mod:
+// flags ("--all") and globs ("*"), which select many.
+func apiModArg(id string) error {
+	if id == "" || strings.HasPrefix(id, "-") || nmsmods.IsGlob(id) {
+		return fmt.Errorf("invalid mod %q (use an id or handle)", id)
+	}
+	return nil
+}
+
+// runJSON answers a read with the stdout of an nmsmods --json command.
+func (s *apiServer) runJSON(w http.ResponseWriter, r *http.Request, args ...string) {
+	args, err := scopeArgs(r, args)
+	if err != nil {
+		apiError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	c := nmsmodsCommand(args...)
+	var stderr bytes.Buffer
+	c.Stderr = &stderr
+	out, err := c.Output()
+	if err != nil {
+		apiError(w, http.StatusInternalServerError, strings.TrimSpace(stderr.String()))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(out)
+}
+
+// scopeArgs adds --profile from ?profile= in front of args, which may end in "--" and
+// positional arguments.
+func scopeArgs(r *http.Request, args []string) ([]string, error) {
+	prof := strings.TrimSpace(r.URL.Query().Get("profile"))
+	if prof == "" {
+		return args, nil
+	}
+	if err := app.ValidateProfileName(prof); err != nil {
+		return nil, err
+	}
+	return append([]string{"--profile=" + prof}, args...), nil
+}
+
+// run executes one operation and answers with apiResult, streaming progress as server-sent
+// events when the client asks for text/event-stream. jsonResult keeps stdout apart as Result.
+func (s *apiServer) run(w http.ResponseWriter, r *http.Request, jsonResult bool, args ...string) {
+	args, err := scopeArgs(r, args)
+	if err != nil {
+		apiError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	stream := strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+	flusher, _ := w.(http.Flusher)
+	if stream {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+	}
+	emit := func(event string, v any) {
+		b, _ := json.Marshal(v)
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, b)
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res := apiResult{Command: "nmsmods " + strings.Join(args, " "), Output: []string{}}
+	c := nmsmodsCommand(args...)
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		apiError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	var stdout bytes.Buffer
+	c.Stderr = pw
+	if jsonResult {
+		c.Stdout = &stdout
+	} else {
+		c.Stdout = pw
+	}
+	err = c.Start()
+	pw.Close()
+	if err == nil {
+		sc := bufio.NewScanner(pr)
+		for sc.Scan() {
+			line := sc.Text()
+			res.Output = append(res.Output, line)
+			if stream {
+				emit("progress", map[string]string{"line": line})
+			}
+		}
+		_, _ = io.Copy(io.Discard, pr)
+		err = c.Wait()
+	}
+	pr.Close()
+
+	status := http.StatusOK
+	if err != nil {
+		res.Error = err.Error()
+		status = http.StatusUnprocessableEntity
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == exitLockBusy {
+			status = http.StatusConflict
+		}
+		if len(res.Output) > 0 {
+			res.Error = res.Output[len(res.Output)-1]
+		}
+	} else {
+		res.OK = true
+	}
+	if jsonResult && json.Valid(stdout.Bytes()) {
+		res.Result = json.RawMessage(bytes.TrimSpace(stdout.Bytes()))
+	}
+	if stream {
+		emit("done", res)
+		return
+	}
+	writeJSON(w, status, res)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	b, _ := json.MarshalIndent(v, "", "  ")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(append(b, '\n'))
+}
+
+func apiError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&serveSocket, "socket", "", "Listen on this unix socket (created with mode 0600)")
+	serveCmd.Flags().StringVar(&serveListen, "listen", "", "Listen on a loopback TCP address, e.g. 127.0.0.1:7878")
+	serveCmd.Flags().StringVar(&serveTokenFile, "token-file", "", "Bearer token file (default: <data dir>/api-token)")
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestAPIServer_RejectsArgumentsThatSelectMany(t *testing.T) {
+	h := (&apiServer{token: "secret"}).routes()
+	for _, path := range []string{
+		"/v1/mods/--all/uninstall",
+		"/v1/mods/-v/enable",
+		"/v1/mods/*/disable",
+		"/v1/mods/better-%5B/reinstall",
+		"/v1/profiles/--help/use",
+		"/v1/mods/foo/enable?profile=--all",
+		"/v1/deploy?profile=a%20b",
+	} {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("POST %s: status %d, want 400 (%s)", path, rec.Code, rec.Body)
+		}
+	}
+}
+
+func TestScopeArgs_PutsProfileBeforePositionals(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/v1/mods/x/enable?profile=vr", nil)
+	got, err := scopeArgs(req, []string{"enable", "--", "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"--profile=vr", "enable", "--", "x"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("scopeArgs = %q, want %q", got, want)
+	}
+}
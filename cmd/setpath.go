@@ -6,8 +6,8 @@ import (
 	"strings"
 
 	"nmsmods/internal/app"
+	"nmsmods/internal/discover"
 	"nmsmods/internal/nms"
-	"nmsmods/internal/steam"
 
 	"github.com/spf13/cobra"
 )
@@ -28,42 +28,31 @@ var setPathCmd = &cobra.Command{
 			if newPath != "" {
 				return fmt.Errorf("--auto cannot be used with an explicit path")
 			}
-			paths, err := steam.GuessNMSPaths()
+			cands, err := validGameCandidates()
 			if err != nil {
 				return err
 			}
-			valid := []string{}
-			for _, cand := range paths {
-				g, err := nms.ValidateGamePath(cand)
-				if err == nil {
-					valid = append(valid, g.Path)
+			from, _ := cmd.Flags().GetString("from")
+			if from != "" {
+				filtered := []discover.Candidate{}
+				for _, c := range cands {
+					if strings.EqualFold(c.Source, from) {
+						filtered = append(filtered, c)
+					}
 				}
+				cands = filtered
 			}
-			// de-dupe
-			seen := map[string]struct{}{}
-			uniq := []string{}
-			for _, v := range valid {
-				v = strings.TrimSpace(v)
-				if v == "" {
-					continue
+			if len(cands) == 0 {
+				if from != "" {
+					return fmt.Errorf("no No Man's Sky install detected from %q (sources: %s)", from, strings.Join(discover.Sources(), ", "))
 				}
-				if _, ok := seen[v]; ok {
-					continue
-				}
-				seen[v] = struct{}{}
-				uniq = append(uniq, v)
-			}
-			if len(uniq) == 0 {
 				return fmt.Errorf("could not auto-detect No Man's Sky install. Use: nmsmods set-path <path>")
 			}
-			if len(uniq) > 1 {
-				msg := "multiple No Man's Sky installs detected. Please choose one with: nmsmods set-path <path>\n"
-				for _, v := range uniq {
-					msg += " - " + v + "\n"
-				}
-				return errors.New(strings.TrimSpace(msg))
+			if len(cands) > 1 {
+				return errors.New("multiple No Man's Sky installs detected. Choose one with: nmsmods set-path <path> (or --auto --from <source>)\n" + formatCandidates(cands))
 			}
-			newPath = uniq[0]
+			newPath = cands[0].Path
+			fmt.Fprintf(cmd.OutOrStdout(), "Detected [%s] %s\n", cands[0].Source, newPath)
 		}
 		if newPath == "" {
 			return fmt.Errorf("missing path (or use --auto)")
@@ -99,5 +88,6 @@ var setPathCmd = &cobra.Command{
 }
 
 func init() {
-	setPathCmd.Flags().Bool("auto", false, "Auto-detect the No Man's Sky install path from Steam libraries")
+	setPathCmd.Flags().Bool("auto", false, "Auto-detect the No Man's Sky install path (Steam, Heroic, Lutris, Bottles)")
+	setPathCmd.Flags().String("from", "", "With --auto, only consider installs from this source ("+strings.Join(discover.Sources(), ", ")+")")
 }
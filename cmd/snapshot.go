@@ -0,0 +1,363 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"nmsmods/internal/app"
+	"nmsmods/internal/mods"
+	"nmsmods/internal/nms"
+	"nmsmods/internal/snapshot"
+
+	"github.com/spf13/cobra"
+)
+
+func snapshotStore(p *app.Paths) snapshot.Store {
+	return snapshot.Store{Dir: filepath.Join(p.Root, "snapshots")}
+}
+
+// stateRevision returns the sha256 of the state rendered as state.json, whatever the backend.
+func stateRevision(p *app.Paths) (string, []byte, error) {
+	st, err := loadState(p)
+	if err != nil {
+		return "", nil, err
+	}
+	b, err := app.MarshalState(st)
+	if err != nil {
+		return "", nil, err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), b, nil
+}
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Save and restore the exact contents of GAMEDATA/MODS",
+}
+
+var snapshotCreateExternal bool
+
+var snapshotCreateCmd = &cobra.Command{
+	Use:   "create [name]",
+	Short: "Record the deployed MODS folders (and optionally unmanaged ones) under a name",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p := mustPaths()
+		name := time.Now().Format("20060102-150405")
+		if len(args) == 1 {
+			name = args[0]
+		}
+		if err := snapshot.ValidateName(name); err != nil {
+			return err
+		}
+
+		return withStateLock(p, func() error {
+			cfg, game, err := requireGame(p)
+			if err != nil {
+				return err
+			}
+			store := snapshotStore(p)
+			if store.Exists(name) {
+				return fmt.Errorf("snapshot %q already exists (delete it first or pick another name)", name)
+			}
+
+			folders, err := store.Capture(game.ModsDir, snapshotCreateExternal)
+			if err != nil {
+				return err
+			}
+			snap := snapshot.Snapshot{
+				Name:             name,
+				CreatedAt:        app.NowRFC3339(),
+				GamePath:         game.Path,
+				ActiveProfile:    app.ActiveProfile(*cfg),
+				ModsDisabled:     game.ModsDisabled,
+				IncludesExternal: snapshotCreateExternal,
+				Folders:          folders,
+			}
+			_, stateJSON, err := stateRevision(p)
+			if err != nil {
+				return err
+			}
+			if snap.StateSHA256, err = store.PutBytes(stateJSON); err != nil {
+				return err
+			}
+			if err := store.Save(snap); err != nil {
+				return err
+			}
+
+			managed, external := countFolders(snap)
+			fmt.Fprintf(cmd.OutOrStdout(), "Snapshot %s: %d managed, %d external folders\n", name, managed, external)
+			return nil
+		})
+	},
+}
+
+func countFolders(snap snapshot.Snapshot) (managed, external int) {
+	for _, f := range snap.Folders {
+		if f.Managed {
+			managed++
+		} else {
+			external++
+		}
+	}
+	return managed, external
+}
+
+var snapshotListJSON bool
+
+// snapshotRow is the stable JSON shape for `nmsmods snapshot list --json`.
+type snapshotRow struct {
+	Name             string `json:"name"`
+	CreatedAt        string `json:"created_at"`
+	ActiveProfile    string `json:"active_profile"`
+	Managed          int    `json:"managed"`
+	External         int    `json:"external"`
+	IncludesExternal bool   `json:"includes_external"`
+	ModsDisabled     bool   `json:"mods_disabled"`
+	StateSHA256      string `json:"state_sha256,omitempty"`
+}
+
+var snapshotListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List snapshots (oldest first)",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p := mustPaths()
+		snaps, err := snapshotStore(p).List()
+		if err != nil {
+			return err
+		}
+		rows := make([]snapshotRow, 0, len(snaps))
+		for _, s := range snaps {
+			m, e := countFolders(s)
+			rows = append(rows, snapshotRow{
+				Name:             s.Name,
+				CreatedAt:        s.CreatedAt,
+				ActiveProfile:    s.ActiveProfile,
+				Managed:          m,
+				External:         e,
+				IncludesExternal: s.IncludesExternal,
+				ModsDisabled:     s.ModsDisabled,
+				StateSHA256:      s.StateSHA256,
+			})
+		}
+		if snapshotListJSON {
+			b, _ := json.MarshalIndent(rows, "", "  ")
+			fmt.Fprintln(cmd.OutOrStdout(), string(b))
+			return nil
+		}
+		if len(rows) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "No snapshots.")
+			return nil
+		}
+		for _, r := range rows {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\tprofile=%s\tmanaged=%d\texternal=%d\n", r.Name, r.CreatedAt, r.ActiveProfile, r.Managed, r.External)
+		}
+		return nil
+	},
+}
+
+var snapshotRestoreState bool
+var snapshotRestoreDryRun bool
+
+var snapshotRestoreCmd = &cobra.Command{
+	Use:   "restore <name>",
+	Short: "Bring GAMEDATA/MODS back to a snapshot (managed folders are redeployed safely)",
+	Long: `Restore GAMEDATA/MODS to the folders recorded in a snapshot.
+
+Managed folders that differ from the snapshot are undeployed and redeployed from the snapshot's
+content with the usual safety rules: folders not managed by nmsmods are never overwritten or removed.
+Unmanaged folders recorded with --external are re-created only if missing.
+
+With --state, state.json and the active profile are also rolled back to the snapshot's revision.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p := mustPaths()
+		return withStateLock(p, func() error {
+			cfg, game, err := requireGame(p)
+			if err != nil {
+				return err
+			}
+			if err := ensureGameNotRunning(); err != nil {
+				return err
+			}
+			store := snapshotStore(p)
+			snap, err := store.Load(args[0])
+			if err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			dry := snapshotRestoreDryRun
+			prefix := ""
+			if dry {
+				prefix = "[dry-run] "
+			}
+
+			want := map[string]snapshot.Folder{}
+			for _, f := range snap.Folders {
+				want[f.Folder] = f
+			}
+
+			// 1) Remove managed folders that are not in the snapshot or differ from it.
+			keep := map[string]bool{}
+			ents, err := os.ReadDir(game.ModsDir)
+			if err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			for _, e := range ents {
+				if !e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+					continue
+				}
+				dir := filepath.Join(game.ModsDir, e.Name())
+				m, merr := mods.ReadManagedMarker(dir)
+				w, inSnap := want[e.Name()]
+				if merr != nil || m.Tag == "" {
+					if !inSnap {
+						fmt.Fprintln(cmd.ErrOrStderr(), "Warning: leaving unmanaged folder in place:", e.Name())
+					}
+					continue
+				}
+				if inSnap && w.Managed && w.ModID == m.ModID && w.Profile == m.Profile {
+					if h, herr := snapshot.HashFolder(dir); herr == nil && h == w.Hash {
+						keep[e.Name()] = true
+						continue
+					}
+				}
+				fmt.Fprintf(out, "%sUndeploy: %s (%s, profile %s)\n", prefix, e.Name(), m.ModID, m.Profile)
+				if !dry {
+					if err := mods.Undeploy(game.ModsDir, e.Name(), m.ModID, m.Profile); err != nil {
+						return err
+					}
+				}
+			}
+
+			// 2) Deploy snapshot folders that are missing or were just removed.
+			stageRoot := filepath.Join(p.Staging, "snapshot-"+snap.Name)
+			if !dry {
+				_ = os.RemoveAll(stageRoot)
+				defer os.RemoveAll(stageRoot)
+			}
+			for _, f := range snap.Folders {
+				if keep[f.Folder] {
+					continue
+				}
+				dest, err := mods.SafeJoinUnder(game.ModsDir, f.Folder)
+				if err != nil {
+					return err
+				}
+				if !f.Managed {
+					if fileExists(dest) {
+						if h, herr := snapshot.HashFolder(dest); herr != nil || h != f.Hash {
+							fmt.Fprintln(cmd.ErrOrStderr(), "Warning: unmanaged folder differs from snapshot; not touching it:", f.Folder)
+						}
+						continue
+					}
+					fmt.Fprintf(out, "%sRestore unmanaged: %s\n", prefix, f.Folder)
+					if !dry {
+						if err := store.Materialize(f, dest); err != nil {
+							return err
+						}
+					}
+					continue
+				}
+				fmt.Fprintf(out, "%sDeploy: %s (%s, profile %s)\n", prefix, f.Folder, f.ModID, f.Profile)
+				if dry {
+					continue
+				}
+				stage := filepath.Join(stageRoot, f.Folder)
+				if err := store.Materialize(f, stage); err != nil {
+					return err
+				}
+				if _, err := mods.Deploy(stage, game.ModsDir, f.Folder, f.ModID, f.Profile, deployOptions(p, f.Profile, game)); err != nil {
+					return err
+				}
+			}
+
+			// 3) Mod loading switch.
+			if snap.ModsDisabled != game.ModsDisabled {
+				state := "on"
+				if snap.ModsDisabled {
+					state = "off"
+				}
+				fmt.Fprintf(out, "%sMod loading: %s\n", prefix, state)
+				if !dry {
+					if _, err := nms.SetModsEnabled(game, !snap.ModsDisabled); err != nil {
+						return err
+					}
+				}
+			}
+
+			// 4) state.json revision.
+			rev, _, err := stateRevision(p)
+			if err != nil {
+				return err
+			}
+			if snap.StateSHA256 != "" && rev != snap.StateSHA256 {
+				if !snapshotRestoreState {
+					fmt.Fprintln(cmd.ErrOrStderr(), "Note: state.json changed since this snapshot; the next redeploy follows the current state (use --state to roll it back too)")
+				} else {
+					fmt.Fprintf(out, "%sRestore state.json revision %s and active profile %s\n", prefix, snap.StateSHA256[:12], snap.ActiveProfile)
+					if !dry {
+						b, err := os.ReadFile(store.ObjectPath(snap.StateSHA256))
+						if err != nil {
+							return fmt.Errorf("state.json revision missing from snapshot store: %w", err)
+						}
+						var st app.State
+						if err := json.Unmarshal(b, &st); err != nil {
+							return err
+						}
+						if err := saveState(p, st); err != nil {
+							return err
+						}
+						if snap.ActiveProfile != "" && snap.ActiveProfile != app.ActiveProfile(*cfg) {
+							cfg.ActiveProfile = snap.ActiveProfile
+							if err := app.SaveConfig(p.Config, *cfg); err != nil {
+								return err
+							}
+						}
+					}
+				}
+			}
+
+			if !dry {
+				fmt.Fprintln(out, "Restored snapshot:", snap.Name)
+			}
+			return nil
+		})
+	},
+}
+
+var snapshotDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete a snapshot and free content no other snapshot uses",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p := mustPaths()
+		return withStateLock(p, func() error {
+			n, err := snapshotStore(p).Delete(args[0])
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Deleted snapshot %s (%d unreferenced objects removed)\n", args[0], n)
+			return nil
+		})
+	},
+}
+
+func init() {
+	snapshotCreateCmd.Flags().BoolVar(&snapshotCreateExternal, "external", false, "Also capture folders not managed by nmsmods")
+	snapshotListCmd.Flags().BoolVar(&snapshotListJSON, "json", false, "Output in JSON format")
+	snapshotRestoreCmd.Flags().BoolVar(&snapshotRestoreState, "state", false, "Also roll back state.json and the active profile")
+	snapshotRestoreCmd.Flags().BoolVar(&snapshotRestoreDryRun, "dry-run", false, "Print what would happen without making changes")
+
+	snapshotCmd.AddCommand(snapshotCreateCmd)
+	snapshotCmd.AddCommand(snapshotListCmd)
+	snapshotCmd.AddCommand(snapshotRestoreCmd)
+	snapshotCmd.AddCommand(snapshotDeleteCmd)
+}
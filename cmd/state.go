@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"nmsmods/internal/app"
+
+	"github.com/spf13/cobra"
+)
+
+var stateCmd = &cobra.Command{
+	Use:   "state",
+	Short: "Inspect, migrate, export or import the nmsmods state store",
+	Long: `nmsmods keeps its state either in state.json (default) or in state.db, an embedded
+bbolt database with one record per mod, indexes by Nexus mod id/folder/profile and
+transactional updates. state.db is used as soon as it exists.
+
+  nmsmods state migrate --to bolt   import state.json into state.db (state.json goes to the trash)
+  nmsmods state migrate --to json   export state.db back to state.json (state.db goes to the trash)
+  nmsmods state export [file]       write the state as JSON (stdout by default)
+  nmsmods state import <file>       replace the state with a JSON export`,
+}
+
+var stateInfoJSON bool
+
+// stateInfo is the stable JSON shape for `nmsmods state info --json`.
+type stateInfo struct {
+	Backend       string `json:"backend"`
+	Path          string `json:"path"`
+	StateVersion  int    `json:"state_version"`
+	SchemaVersion int    `json:"schema_version,omitempty"`
+	Mods          int    `json:"mods"`
+}
+
+var stateInfoCmd = &cobra.Command{
+	Use:   "info",
+	Short: "Show which backend holds the state and its versions",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p := mustPaths()
+		s, err := app.OpenStateStore(p)
+		if err != nil {
+			return err
+		}
+		defer s.Close()
+		st, err := s.Load()
+		if err != nil {
+			return err
+		}
+		info := stateInfo{Backend: s.Backend(), Path: p.State, StateVersion: st.StateVersion, Mods: len(st.Mods)}
+		if bs, ok := s.(*app.BoltStore); ok {
+			info.Path = p.StateDB
+			info.SchemaVersion = bs.SchemaVersion()
+		}
+		if stateInfoJSON {
+			b, _ := json.MarshalIndent(info, "", "  ")
+			fmt.Fprintln(cmd.OutOrStdout(), string(b))
+			return nil
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), "backend:", info.Backend)
+		fmt.Fprintln(cmd.OutOrStdout(), "path:   ", info.Path)
+		fmt.Fprintln(cmd.OutOrStdout(), "state:   v"+fmt.Sprint(info.StateVersion))
+		if info.SchemaVersion > 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "schema:  v"+fmt.Sprint(info.SchemaVersion))
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), "mods:   ", info.Mods)
+		return nil
+	},
+}
+
+var stateMigrateTo string
+
+var stateMigrateCmd = &cobra.Command{
+	Use:   "migrate --to bolt|json",
+	Short: "Move the state between state.json and the embedded state.db",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p := mustPaths()
+		return withStateLock(p, func() error {
+			current := app.StateBackend(p)
+			if stateMigrateTo == current {
+				return fmt.Errorf("state already uses the %s backend", current)
+			}
+			switch stateMigrateTo {
+			case app.BackendBolt:
+				s, err := app.OpenBoltStore(p.StateDB)
+				if err != nil {
+					return err
+				}
+				n, err := app.ImportJSONState(p.State, s)
+				if cerr := s.Close(); err == nil {
+					err = cerr
+				}
+				if err != nil {
+					_ = os.Remove(p.StateDB)
+					return err
+				}
+				if fileExists(p.State) {
+					if _, err := trashPath(p, p.State, app.TrashState, "", commandLine(cmd, args)); err != nil {
+						return err
+					}
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Imported %d mods into %s\n", n, p.StateDB)
+				return nil
+			case app.BackendJSON:
+				st, err := loadState(p)
+				if err != nil {
+					return err
+				}
+				if err := app.SaveState(p.State, st); err != nil {
+					return err
+				}
+				if _, err := trashPath(p, p.StateDB, app.TrashState, "", commandLine(cmd, args)); err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Exported %d mods to %s\n", len(st.Mods), p.State)
+				return nil
+			default:
+				return fmt.Errorf("--to must be %q or %q", app.BackendBolt, app.BackendJSON)
+			}
+		})
+	},
+}
+
+var stateExportCmd = &cobra.Command{
+	Use:   "export [file]",
+	Short: "Write the state as state.json-compatible JSON (stdout by default)",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p := mustPaths()
+		st, err := loadState(p)
+		if err != nil {
+			return err
+		}
+		if len(args) == 0 {
+			b, err := app.MarshalState(st)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), string(b))
+			return nil
+		}
+		if err := app.SaveState(args[0], st); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "Exported %d mods to %s\n", len(st.Mods), args[0])
+		return nil
+	},
+}
+
+var stateImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Replace the state with a JSON export (the current state goes to the trash first)",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p := mustPaths()
+		b, err := os.ReadFile(args[0])
+		if err != nil {
+			return err
+		}
+		var st app.State
+		if err := json.Unmarshal(b, &st); err != nil {
+			return fmt.Errorf("%s: %w", args[0], err)
+		}
+		if err := app.CheckStateVersion(st); err != nil {
+			return fmt.Errorf("%s: %w", args[0], err)
+		}
+		st, _ = app.MigrateState(st)
+
+		return withStateLock(p, func() error {
+			current, err := loadState(p)
+			if err != nil {
+				return err
+			}
+			backup, err := app.MarshalState(current)
+			if err != nil {
+				return err
+			}
+			tmp := p.State + ".before-import"
+			if err := os.WriteFile(tmp, backup, 0o600); err != nil {
+				return err
+			}
+			if _, err := trashPath(p, tmp, app.TrashState, "", commandLine(cmd, args)); err != nil {
+				return err
+			}
+			if err := saveState(p, st); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Imported %d mods (%s backend)\n", len(st.Mods), app.StateBackend(p))
+			return nil
+		})
+	},
+}
+
+func init() {
+	stateInfoCmd.Flags().BoolVar(&stateInfoJSON, "json", false, "Output in JSON format")
+	stateMigrateCmd.Flags().StringVar(&stateMigrateTo, "to", "", "Target backend: bolt or json")
+	_ = stateMigrateCmd.MarkFlagRequired("to")
+
+	stateCmd.AddCommand(stateInfoCmd)
+	stateCmd.AddCommand(stateMigrateCmd)
+	stateCmd.AddCommand(stateExportCmd)
+	stateCmd.AddCommand(stateImportCmd)
+}
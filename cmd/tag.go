@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"nmsmods/pkg/nmsmods"
+
+	"github.com/spf13/cobra"
+)
+
+var tagListJSON bool
+
+var tagCmd = &cobra.Command{
+	Use:   "tag",
+	Short: "Label mods with your own tags (filter with --tag, group with --group-by tag)",
+	Long: `Label mods with your own tags. Tags are lower case and may contain letters, digits and
+. _ - : /. They select mods in enable, disable, uninstall and reinstall (--tag), and filter or
+group downloads and installed (--tag, --group-by tag). Tag changes are recorded in history.`,
+}
+
+var tagAddCmd = &cobra.Command{
+	Use:   "add <mod> <tag>...",
+	Short: "Add tags to a mod",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		res, err := newManager(mustPaths(), commandLineOption(cmd, args)).AddTags(cmd.Context(), args[0], args[1:]...)
+		if err != nil {
+			return err
+		}
+		return printMetaResult(cmd, res)
+	},
+}
+
+var tagRemoveCmd = &cobra.Command{
+	Use:     "rm <mod> <tag>...",
+	Aliases: []string{"remove"},
+	Short:   "Remove tags from a mod",
+	Args:    cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		res, err := newManager(mustPaths(), commandLineOption(cmd, args)).RemoveTags(cmd.Context(), args[0], args[1:]...)
+		if err != nil {
+			return err
+		}
+		return printMetaResult(cmd, res)
+	},
+}
+
+// tagCount is the stable JSON shape for `nmsmods tag list --json` (without a mod).
+type tagCount struct {
+	Tag  string `json:"tag"`
+	Mods int    `json:"mods"`
+}
+
+var tagListCmd = &cobra.Command{
+	Use:   "list [mod]",
+	Short: "List all tags with their mod counts, or one mod's tags",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		st, err := loadState(mustPaths())
+		if err != nil {
+			return err
+		}
+		w := cmd.OutOrStdout()
+		if len(args) == 1 {
+			id, err := resolveModArg(args[0], st)
+			if err != nil {
+				return err
+			}
+			tags := st.Mods[id].Tags
+			if tags == nil {
+				tags = []string{}
+			}
+			if tagListJSON {
+				b, _ := json.MarshalIndent(tags, "", "  ")
+				fmt.Fprintln(w, string(b))
+				return nil
+			}
+			if len(tags) == 0 {
+				fmt.Fprintln(w, "(none)")
+				return nil
+			}
+			fmt.Fprintln(w, strings.Join(tags, "\n"))
+			return nil
+		}
+
+		out := []tagCount{}
+		for t, n := range nmsmods.TagCounts(st) {
+			out = append(out, tagCount{Tag: t, Mods: n})
+		}
+		sort.Slice(out, func(i, j int) bool { return out[i].Tag < out[j].Tag })
+		if tagListJSON {
+			b, _ := json.MarshalIndent(out, "", "  ")
+			fmt.Fprintln(w, string(b))
+			return nil
+		}
+		if len(out) == 0 {
+			fmt.Fprintln(w, "(none)")
+			return nil
+		}
+		for _, tc := range out {
+			fmt.Fprintf(w, "%s\t%d mod(s)\n", tc.Tag, tc.Mods)
+		}
+		return nil
+	},
+}
+
+func printMetaResult(cmd *cobra.Command, res nmsmods.MetaResult) error {
+	setResult(res)
+	w := cmd.OutOrStdout()
+	tags := strings.Join(res.Tags, ", ")
+	if tags == "" {
+		tags = "(none)"
+	}
+	if res.Unchanged {
+		fmt.Fprintf(w, "Unchanged: %s (tags: %s)\n", res.ID, tags)
+		return nil
+	}
+	fmt.Fprintf(w, "Updated: %s (tags: %s)\n", res.ID, tags)
+	return nil
+}
+
+func init() {
+	tagListCmd.Flags().BoolVar(&tagListJSON, "json", false, "Output in JSON format")
+	tagCmd.AddCommand(tagAddCmd, tagRemoveCmd, tagListCmd)
+}
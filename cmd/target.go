@@ -0,0 +1,388 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"nmsmods/internal/app"
+	"nmsmods/internal/nms"
+
+	"github.com/spf13/cobra"
+)
+
+var targetCmd = &cobra.Command{
+	Use:   "target",
+	Short: "Manage named game installations (targets) and bind profiles to them",
+	Long: `A target is a named No Man's Sky installation. game_path (set-path) is the "default"
+target; more can be added, e.g. a Steam build and an experimental-branch copy:
+
+  nmsmods target add experimental /mnt/games/SteamLibrary/steamapps/common/No\ Man\'s\ Sky
+  nmsmods target bind testing experimental   # profile "testing" deploys to "experimental"
+  nmsmods enable foo --game-target experimental
+
+The target for an operation is --game-target, else the active profile's binding, else
+default_target, else "default".`,
+}
+
+var targetListJSON bool
+
+// targetRow is the stable JSON shape for `nmsmods target list --json`.
+type targetRow struct {
+	Name     string   `json:"name"`
+	Path     string   `json:"path"`
+	Default  bool     `json:"default"`
+	Active   bool     `json:"active"` // used by the active profile right now
+	Valid    bool     `json:"valid"`
+	Profiles []string `json:"profiles,omitempty"` // profiles bound to this target
+	Error    string   `json:"error,omitempty"`
+}
+
+var targetListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List configured game targets",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p := mustPaths()
+		cfg, err := loadConfig(p)
+		if err != nil {
+			return err
+		}
+		defaultName := cfg.DefaultTarget
+		if defaultName == "" {
+			defaultName = app.DefaultTargetName
+		}
+		active := ""
+		if prof, err := resolveProfile(p, &cfg); err == nil {
+			active, _ = app.ResolveTarget(cfg, prof.Settings.Target, strings.TrimSpace(gameTargetOverride))
+		}
+		bindings, err := profileTargetBindings(p)
+		if err != nil {
+			return err
+		}
+
+		rows := []targetRow{}
+		for _, name := range app.TargetNames(cfg) {
+			path, _ := app.TargetPath(cfg, name)
+			row := targetRow{Name: name, Path: path, Default: name == defaultName, Active: name == active}
+			if _, err := nms.ValidateGamePath(path); err != nil {
+				row.Error = err.Error()
+			} else {
+				row.Valid = true
+			}
+			for _, prof := range sortedKeys(bindings) {
+				if bindings[prof] == name {
+					row.Profiles = append(row.Profiles, prof)
+				}
+			}
+			rows = append(rows, row)
+		}
+
+		if targetListJSON {
+			b, _ := json.MarshalIndent(rows, "", "  ")
+			fmt.Fprintln(cmd.OutOrStdout(), string(b))
+			return nil
+		}
+		if len(rows) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "No targets configured (run: nmsmods set-path <path> or nmsmods target add <name> <path>)")
+			return nil
+		}
+		for _, r := range rows {
+			mark := " "
+			if r.Active {
+				mark = "*"
+			}
+			extra := ""
+			if r.Default {
+				extra += " (default)"
+			}
+			if len(r.Profiles) > 0 {
+				extra += " profiles: " + strings.Join(r.Profiles, ", ")
+			}
+			if !r.Valid {
+				extra += " INVALID: " + r.Error
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s %s\t%s%s\n", mark, r.Name, r.Path, extra)
+		}
+		return nil
+	},
+}
+
+// addTarget validates and stores a target; "default" sets game_path.
+func addTarget(cfg *app.Config, name, path string) (string, error) {
+	if err := app.ValidateTargetName(name); err != nil {
+		return "", err
+	}
+	g, err := nms.ValidateGamePath(path)
+	if err != nil {
+		return "", fmt.Errorf("invalid game path: %w", err)
+	}
+	if name == app.DefaultTargetName {
+		cfg.GamePath = g.Path
+		return g.Path, nil
+	}
+	if cfg.Targets == nil {
+		cfg.Targets = map[string]app.GameTarget{}
+	}
+	cfg.Targets[name] = app.GameTarget{Path: g.Path}
+	return g.Path, nil
+}
+
+var targetAddCmd = &cobra.Command{
+	Use:   "add <name> <path>",
+	Short: "Add or update a named game target",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p := mustPaths()
+		return withStateLock(p, func() error {
+			cfg, err := loadConfig(p)
+			if err != nil {
+				return err
+			}
+			path, err := addTarget(&cfg, args[0], args[1])
+			if err != nil {
+				return err
+			}
+			if err := app.SaveConfig(p.Config, cfg); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Target %s: %s\n", args[0], path)
+			return nil
+		})
+	},
+}
+
+var targetDetectSave bool
+
+var targetDetectCmd = &cobra.Command{
+	Use:   "detect",
+	Short: "Detect installations and propose target names (--save adds them)",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p := mustPaths()
+		return withStateLock(p, func() error {
+			cfg, err := loadConfig(p)
+			if err != nil {
+				return err
+			}
+			cands, err := validGameCandidates()
+			if err != nil {
+				return err
+			}
+			if len(cands) == 0 {
+				return fmt.Errorf("could not auto-detect No Man's Sky install. Use: nmsmods target add <name> <path>")
+			}
+
+			known := map[string]string{} // path -> target name
+			for _, name := range app.TargetNames(cfg) {
+				path, _ := app.TargetPath(cfg, name)
+				known[path] = name
+			}
+			added := 0
+			for _, c := range cands {
+				if name, ok := known[c.Path]; ok {
+					fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s (already target %s)\n", c.Source, c.Path, name)
+					continue
+				}
+				name := c.Source
+				if _, ok := app.TargetPath(cfg, name); ok {
+					for i := 2; ; i++ {
+						if _, ok := app.TargetPath(cfg, fmt.Sprintf("%s-%d", c.Source, i)); !ok {
+							name = fmt.Sprintf("%s-%d", c.Source, i)
+							break
+						}
+					}
+				}
+				if targetDetectSave {
+					if cfg.GamePath == "" && len(cfg.Targets) == 0 {
+						// The first install becomes game_path so single-install setups keep working.
+						name = app.DefaultTargetName
+					}
+					if _, err := addTarget(&cfg, name, c.Path); err != nil {
+						return err
+					}
+					known[c.Path] = name
+					added++
+					fmt.Fprintf(cmd.OutOrStdout(), "Added target %s: %s [%s]\n", name, c.Path, c.Source)
+				} else {
+					fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s (would add as %s)\n", c.Source, c.Path, name)
+				}
+			}
+			if added > 0 {
+				return app.SaveConfig(p.Config, cfg)
+			}
+			if !targetDetectSave {
+				fmt.Fprintln(cmd.OutOrStdout(), "Run with --save to add them.")
+			}
+			return nil
+		})
+	},
+}
+
+var targetRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove a named target (its deployed folders are left in place)",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p := mustPaths()
+		name := args[0]
+		return withStateLock(p, func() error {
+			cfg, err := loadConfig(p)
+			if err != nil {
+				return err
+			}
+			if _, ok := app.TargetPath(cfg, name); !ok {
+				return fmt.Errorf("unknown target %q (run: nmsmods target list)", name)
+			}
+			bindings, err := profileTargetBindings(p)
+			if err != nil {
+				return err
+			}
+			for _, prof := range sortedKeys(bindings) {
+				if bindings[prof] == name {
+					return fmt.Errorf("profile %q is bound to target %q (run: nmsmods target unbind %s)", prof, name, prof)
+				}
+			}
+			if name == app.DefaultTargetName {
+				cfg.GamePath = ""
+			}
+			delete(cfg.Targets, name)
+			if cfg.DefaultTarget == name {
+				cfg.DefaultTarget = ""
+			}
+			if err := app.SaveConfig(p.Config, cfg); err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), "Removed target:", name)
+			return nil
+		})
+	},
+}
+
+var targetDefaultCmd = &cobra.Command{
+	Use:   "default <name>",
+	Short: "Use a target for profiles that are not bound to one",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p := mustPaths()
+		return withStateLock(p, func() error {
+			cfg, err := loadConfig(p)
+			if err != nil {
+				return err
+			}
+			if _, ok := app.TargetPath(cfg, args[0]); !ok {
+				return fmt.Errorf("unknown target %q (run: nmsmods target list)", args[0])
+			}
+			cfg.DefaultTarget = args[0]
+			if args[0] == app.DefaultTargetName {
+				cfg.DefaultTarget = ""
+			}
+			if err := app.SaveConfig(p.Config, cfg); err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), "Default target:", args[0])
+			return nil
+		})
+	},
+}
+
+var targetBindCmd = &cobra.Command{
+	Use:   "bind <profile> <target>",
+	Short: "Deploy a profile to a specific target",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p := mustPaths()
+		profile, name := args[0], args[1]
+		if err := app.ValidateProfileName(profile); err != nil {
+			return err
+		}
+		return withStateLock(p, func() error {
+			cfg, err := loadConfig(p)
+			if err != nil {
+				return err
+			}
+			if _, ok := app.TargetPath(cfg, name); !ok {
+				return fmt.Errorf("unknown target %q (run: nmsmods target list)", name)
+			}
+			settings, err := app.LoadProfileSettings(p, profile)
+			if err != nil {
+				return err
+			}
+			settings.Target = name
+			if err := app.SaveProfileSettings(p, profile, settings); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Profile %s -> target %s\n", profile, name)
+			if profile == activeProfile(&cfg) {
+				fmt.Fprintln(cmd.OutOrStdout(), "Run 'nmsmods profile deploy' to deploy it there.")
+			}
+			return nil
+		})
+	},
+}
+
+var targetUnbindCmd = &cobra.Command{
+	Use:   "unbind <profile>",
+	Short: "Remove a profile's target binding",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p := mustPaths()
+		return withStateLock(p, func() error {
+			settings, err := app.LoadProfileSettings(p, args[0])
+			if err != nil {
+				return err
+			}
+			if settings.Target == "" {
+				return fmt.Errorf("profile %q is not bound to a target", args[0])
+			}
+			settings.Target = ""
+			if err := app.SaveProfileSettings(p, args[0], settings); err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), "Unbound profile:", args[0])
+			return nil
+		})
+	},
+}
+
+// profileTargetBindings maps every profile with a bound target to that target.
+func profileTargetBindings(p *app.Paths) (map[string]string, error) {
+	names, err := listProfiles(p)
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]string{}
+	for _, n := range names {
+		s, err := app.LoadProfileSettings(p, n)
+		if err != nil {
+			return nil, err
+		}
+		if s.Target != "" {
+			out[n] = s.Target
+		}
+	}
+	return out, nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func init() {
+	targetListCmd.Flags().BoolVar(&targetListJSON, "json", false, "Output in JSON format")
+	targetDetectCmd.Flags().BoolVar(&targetDetectSave, "save", false, "Add detected installs as targets")
+
+	targetCmd.AddCommand(targetListCmd)
+	targetCmd.AddCommand(targetAddCmd)
+	targetCmd.AddCommand(targetDetectCmd)
+	targetCmd.AddCommand(targetRemoveCmd)
+	targetCmd.AddCommand(targetDefaultCmd)
+	targetCmd.AddCommand(targetBindCmd)
+	targetCmd.AddCommand(targetUnbindCmd)
+}
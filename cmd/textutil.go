@@ -1,6 +1,9 @@
 package cmd
 
-import "strings"
+import (
+	"fmt"
+	"strings"
+)
 
 // oneLine squashes whitespace for terminal display.
 func oneLine(s string) string {
@@ -12,3 +15,17 @@ func oneLine(s string) string {
 	}
 	return s
 }
+
+// humanBytes formats a byte count with binary units (e.g. 1.5 MiB).
+func humanBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"nmsmods/internal/app"
+
+	"github.com/spf13/cobra"
+)
+
+// trashPath moves origin into the trash with metadata, then applies the configured retention.
+// Use it instead of os.RemoveAll for anything a user may want back (stores, downloads, state).
+func trashPath(p *app.Paths, origin, kind, modID, command string) (app.TrashItem, error) {
+	return newManager(p).Trash(origin, kind, modID, command)
+}
+
+// parseAge accepts Go durations plus a day suffix ("30d", "12h", "1d12h").
+func parseAge(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	var days int
+	if i := strings.Index(s, "d"); i > 0 {
+		n, err := strconv.Atoi(s[:i])
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		days = n
+		s = s[i+1:]
+	}
+	d := time.Duration(days) * 24 * time.Hour
+	if s != "" {
+		rest, err := time.ParseDuration(s)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q (examples: 30d, 12h, 1d12h)", s)
+		}
+		d += rest
+	}
+	return d, nil
+}
+
+var trashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "List, restore or empty deleted stores, downloads and state",
+	Long: `Deleted profile stores, downloads, state.json and reset homes are moved to <root>/trash.
+
+Retention is configured in config.json:
+
+  "trash": { "retention_days": 30, "max_size_mb": 0 }
+
+retention_days 0 means 30 days, a negative value keeps items forever; max_size_mb 0 means unlimited.
+Emptying the trash also removes what 'nmsmods undo' would need to bring a store back.`,
+}
+
+var trashListJSON bool
+
+var trashListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List trash items (oldest first)",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p := mustPaths()
+		items, err := app.ListTrash(p)
+		if err != nil {
+			return err
+		}
+		if trashListJSON {
+			b, _ := json.MarshalIndent(items, "", "  ")
+			fmt.Fprintln(cmd.OutOrStdout(), string(b))
+			return nil
+		}
+		if len(items) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "Trash is empty.")
+			return nil
+		}
+		for _, it := range items {
+			mod := ""
+			if it.ModID != "" {
+				mod = " [" + it.ModID + "]"
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\t%-8s\t%s%s\t%s\n", it.ID, it.Time, it.Kind, it.Origin, mod, humanBytes(it.Size))
+		}
+		return nil
+	},
+}
+
+var trashRestoreCmd = &cobra.Command{
+	Use:   "restore <id>",
+	Short: "Move a trash item back to where it came from (never overwrites)",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p := mustPaths()
+		return withStateLock(p, func() error {
+			it, err := app.RestoreTrash(p, args[0])
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), "Restored:", it.Origin)
+			if it.Kind == app.TrashStore {
+				fmt.Fprintln(cmd.OutOrStdout(), "Note: state.json is unchanged; use 'nmsmods undo' to restore the install record too")
+			}
+			return nil
+		})
+	},
+}
+
+var trashEmptyOlderThan string
+
+var trashEmptyCmd = &cobra.Command{
+	Use:   "empty",
+	Short: "Permanently delete trash items (all, or --older-than 7d)",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var age time.Duration
+		if trashEmptyOlderThan != "" {
+			d, err := parseAge(trashEmptyOlderThan)
+			if err != nil {
+				return err
+			}
+			age = d
+		}
+		p := mustPaths()
+		return withStateLock(p, func() error {
+			n, err := app.EmptyTrash(p, age, time.Now())
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Deleted %d trash item(s)\n", n)
+			return nil
+		})
+	},
+}
+
+func init() {
+	trashListCmd.Flags().BoolVar(&trashListJSON, "json", false, "Output in JSON format")
+	trashEmptyCmd.Flags().StringVar(&trashEmptyOlderThan, "older-than", "", "Only delete items older than this (e.g. 30d, 12h)")
+	trashCmd.AddCommand(trashListCmd)
+	trashCmd.AddCommand(trashRestoreCmd)
+	trashCmd.AddCommand(trashEmptyCmd)
+}
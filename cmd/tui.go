@@ -0,0 +1,476 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"nmsmods/internal/app"
+	"nmsmods/internal/tui"
+	"nmsmods/pkg/nmsmods"
+
+	"github.com/spf13/cobra"
+)
+
+var tuiCmd = &cobra.Command{
+	Use:         "tui",
+	Short:       "Browse and manage mods in a full-screen terminal UI",
+	Annotations: map[string]string{textOnlyAnnotation: "true"},
+	Long: `Interactive view of every tracked mod with its state in each profile, health, Nexus
+version and update status. Mods are addressed by id, so nothing shifts when mods are added.
+
+Keys:
+  up/down, j/k, PgUp/PgDn   move              space, e   enable/disable
+  i                         install           x          uninstall (asks first)
+  K / J                     load order up/down
+  p                         switch profile    c          conflicts of the selected mod
+  u                         check Nexus updates          r  reload     q  quit
+
+Every action runs the matching nmsmods command (enable, order, profile use, ...), so the TUI
+takes the same state lock and follows the same rules as the CLI.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		m := &tuiModel{p: mustPaths(), updates: map[string]nexusUpdateRow{}}
+		if err := m.reload(); err != nil {
+			return err
+		}
+		term, err := tui.Open()
+		if errors.Is(err, tui.ErrNotTerminal) {
+			return fmt.Errorf("nmsmods tui needs an interactive terminal (use the other commands in scripts)")
+		}
+		if err != nil {
+			return err
+		}
+		defer term.Close()
+		m.term = term
+		return m.loop()
+	},
+}
+
+type tuiMode int
+
+const (
+	tuiList tuiMode = iota
+	tuiConflicts
+	tuiProfiles
+	tuiConfirm
+)
+
+type tuiModel struct {
+	p    *app.Paths
+	term *tui.Terminal
+
+	cfg      app.Config
+	st       app.State
+	profile  string
+	profiles []string
+	ids      []string // profile's mods in load order, then the rest by id
+
+	cursor, offset int
+	mode           tuiMode
+	detail         []string // conflicts view
+	pick           int      // profile picker cursor
+	confirm        []string // command awaiting y/n
+	status         []string
+	updates        map[string]nexusUpdateRow
+}
+
+func (m *tuiModel) reload() error {
+	cfg, err := loadConfig(m.p)
+	if err != nil {
+		return err
+	}
+	st, err := loadState(m.p)
+	if err != nil {
+		return err
+	}
+	profiles, err := listProfiles(m.p)
+	if err != nil {
+		return err
+	}
+	m.cfg, m.st, m.profiles = cfg, st, profiles
+	m.profile = activeProfile(&cfg)
+
+	ordered := nmsmods.LoadOrder(st, m.profile)
+	seen := map[string]bool{}
+	for _, id := range ordered {
+		seen[id] = true
+	}
+	for _, id := range sortedModIDs(st) {
+		if !seen[id] {
+			ordered = append(ordered, id)
+		}
+	}
+	m.ids = ordered
+	if m.cursor >= len(m.ids) {
+		m.cursor = max(0, len(m.ids)-1)
+	}
+	return nil
+}
+
+func (m *tuiModel) selected() string {
+	if m.cursor < len(m.ids) {
+		return m.ids[m.cursor]
+	}
+	return ""
+}
+
+func (m *tuiModel) loop() error {
+	for {
+		m.term.Draw(m.render())
+		keys, err := m.term.ReadKeys()
+		if err != nil {
+			return err
+		}
+		for _, k := range keys {
+			if quit := m.handle(k); quit {
+				return nil
+			}
+		}
+	}
+}
+
+// handle applies one key; it returns true to quit.
+func (m *tuiModel) handle(k tui.Key) bool {
+	if k.Code == tui.KeyCtrlC {
+		return true
+	}
+	switch m.mode {
+	case tuiConfirm:
+		if k.Code == tui.KeyRune && (k.Rune == 'y' || k.Rune == 'Y') {
+			m.run(m.confirm...)
+		} else {
+			m.status = []string{"Cancelled."}
+		}
+		m.mode = tuiList
+		return false
+	case tuiConflicts:
+		if k.Code == tui.KeyEsc || k.Code == tui.KeyEnter || (k.Code == tui.KeyRune && (k.Rune == 'q' || k.Rune == 'c')) {
+			m.mode = tuiList
+		}
+		return false
+	case tuiProfiles:
+		switch {
+		case k.Code == tui.KeyUp || (k.Code == tui.KeyRune && k.Rune == 'k'):
+			m.pick = max(0, m.pick-1)
+		case k.Code == tui.KeyDown || (k.Code == tui.KeyRune && k.Rune == 'j'):
+			m.pick = min(len(m.profiles)-1, m.pick+1)
+		case k.Code == tui.KeyEnter:
+			m.mode = tuiList
+			if name := m.profiles[m.pick]; name != app.ActiveProfile(m.cfg) || profileOverride != "" {
+				m.run("profile", "use", name)
+				profileOverride = "" // the switch is persistent; later actions use the new profile
+				_ = m.reload()
+			}
+		case k.Code == tui.KeyEsc || (k.Code == tui.KeyRune && k.Rune == 'q'):
+			m.mode = tuiList
+		}
+		return false
+	}
+
+	id := m.selected()
+	_, h := m.term.Size()
+	page := max(1, h-6)
+	switch k.Code {
+	case tui.KeyUp:
+		m.cursor = max(0, m.cursor-1)
+	case tui.KeyDown:
+		m.cursor = max(0, min(len(m.ids)-1, m.cursor+1))
+	case tui.KeyPgUp:
+		m.cursor = max(0, m.cursor-page)
+	case tui.KeyPgDn:
+		m.cursor = max(0, min(len(m.ids)-1, m.cursor+page))
+	case tui.KeyHome:
+		m.cursor = 0
+	case tui.KeyEnd:
+		m.cursor = max(0, len(m.ids)-1)
+	case tui.KeyEsc:
+		m.status = nil
+	case tui.KeyRune:
+		switch k.Rune {
+		case 'q':
+			return true
+		case 'k':
+			m.cursor = max(0, m.cursor-1)
+		case 'j':
+			m.cursor = max(0, min(len(m.ids)-1, m.cursor+1))
+		case 'r':
+			if err := m.reload(); err != nil {
+				m.status = []string{"Error: " + err.Error()}
+			} else {
+				m.status = []string{"Reloaded."}
+			}
+		case ' ', 'e':
+			if id == "" {
+				break
+			}
+			pi := m.st.Mods[id].Installations[m.profile]
+			switch {
+			case !pi.Installed:
+				m.status = []string{fmt.Sprintf("%s is not installed in profile %s (press i to install).", id, m.profile)}
+			case pi.Enabled:
+				m.run("disable", id)
+			default:
+				m.run("enable", id)
+			}
+		case 'i':
+			if id != "" {
+				m.run("install", id)
+			}
+		case 'x':
+			if id != "" {
+				m.confirm = []string{"uninstall", id}
+				m.mode = tuiConfirm
+			}
+		case 'K', 'J':
+			m.move(id, k.Rune == 'K')
+		case 'p':
+			m.pick = 0
+			for i, n := range m.profiles {
+				if n == m.profile {
+					m.pick = i
+				}
+			}
+			m.mode = tuiProfiles
+		case 'c':
+			m.showConflicts(id)
+		case 'u':
+			m.checkUpdates()
+		}
+	}
+	return false
+}
+
+// move shifts id one place in the profile's load order through `nmsmods order`.
+func (m *tuiModel) move(id string, up bool) {
+	order := nmsmods.LoadOrder(m.st, m.profile)
+	pos := -1
+	for i, other := range order {
+		if other == id {
+			pos = i + 1
+		}
+	}
+	if pos < 0 {
+		m.status = []string{fmt.Sprintf("%s is not installed in profile %s.", id, m.profile)}
+		return
+	}
+	if up {
+		pos--
+	} else {
+		pos++
+	}
+	if pos < 1 || pos > len(order) {
+		return
+	}
+	m.run("order", id, fmt.Sprint(pos))
+	for i, other := range m.ids {
+		if other == id {
+			m.cursor = i
+		}
+	}
+}
+
+// run executes nmsmods <args> as a child process (same lock and code path as the CLI), shows
+// the tail of its output and reloads state.
+func (m *tuiModel) run(args ...string) {
+	m.status = []string{"Running: nmsmods " + strings.Join(args, " ") + " ..."}
+	m.term.Draw(m.render())
+	out, err := nmsmodsCommand(args...).CombinedOutput()
+	lines := strings.Split(strings.TrimRight(string(out), "\n"), "\n")
+	if len(lines) > 3 {
+		lines = lines[len(lines)-3:]
+	}
+	if err != nil {
+		lines = append([]string{fmt.Sprintf("Error: nmsmods %s: %v", strings.Join(args, " "), err)}, lines...)
+	}
+	m.status = lines
+	if rerr := m.reload(); rerr != nil {
+		m.status = append(m.status, "Error: "+rerr.Error())
+	}
+}
+
+func (m *tuiModel) showConflicts(id string) {
+	if id == "" {
+		return
+	}
+	out, err := nmsmodsCommand("inspect", id, "--json").Output()
+	if err != nil {
+		m.status = []string{fmt.Sprintf("%s: no conflicts view (is it installed in profile %s?)", id, m.profile)}
+		return
+	}
+	var res inspectOut
+	if err := json.Unmarshal(out, &res); err != nil {
+		m.status = []string{"Error: " + err.Error()}
+		return
+	}
+	m.detail = []string{fmt.Sprintf("Conflicts of %s in profile %s (%d files):", id, res.Profile, len(res.Files)), ""}
+	if len(res.Conflicts) == 0 {
+		m.detail = append(m.detail, "  none")
+	}
+	for _, c := range res.Conflicts {
+		m.detail = append(m.detail, fmt.Sprintf("  %s  %s", c.Path, strings.Join(c.Mods, ", ")))
+	}
+	m.mode = tuiConflicts
+}
+
+func (m *tuiModel) checkUpdates() {
+	m.status = []string{"Checking Nexus for updates ..."}
+	m.term.Draw(m.render())
+	out, err := nmsmodsCommand("nexus", "check-updates", "--json").Output()
+	if err != nil {
+		m.status = []string{"Error: update check failed (run: nmsmods nexus check-updates): " + err.Error()}
+		return
+	}
+	var rows []nexusUpdateRow
+	if err := json.Unmarshal(out, &rows); err != nil {
+		m.status = []string{"Error: " + err.Error()}
+		return
+	}
+	n := 0
+	for _, r := range rows {
+		m.updates[r.ID] = r
+		if r.HasUpdate {
+			n++
+		}
+	}
+	m.status = []string{fmt.Sprintf("Checked %d Nexus mods: %d with updates.", len(rows), n)}
+}
+
+const tuiHelp = "↑↓ move  space enable/disable  i install  x uninstall  K/J order  p profile  c conflicts  u updates  r reload  q quit"
+
+func (m *tuiModel) render() []string {
+	w, h := m.term.Size()
+	scope := "active"
+	if m.profile != app.ActiveProfile(m.cfg) {
+		scope = "not active: changes stay in its store"
+	}
+	lines := []string{tui.Bold + fmt.Sprintf(" nmsmods  profile: %s (%s)  mods: %d", m.profile, scope, len(m.ids))}
+
+	switch m.mode {
+	case tuiConflicts:
+		lines = append(lines, m.detail...)
+		lines = append(lines, "", tui.Dim+" esc: back")
+		return lines
+	case tuiProfiles:
+		lines = append(lines, " Switch profile (enter: profile use, esc: cancel):", "")
+		for i, n := range m.profiles {
+			row := "   " + n
+			if n == app.ActiveProfile(m.cfg) {
+				row += "  (active)"
+			}
+			if i == m.pick {
+				row = tui.Reverse + row
+			}
+			lines = append(lines, row)
+		}
+		return lines
+	}
+
+	cols := []int{4, 18, 22, 9, 22, 8, 10}
+	header := tui.Pad(" #", cols[0]) + tui.Pad("ID", cols[1]) + tui.Pad("NAME", cols[2]) + tui.Pad("STATE", cols[3]) +
+		tui.Pad("PROFILES", cols[4]) + tui.Pad("HEALTH", cols[5]) + tui.Pad("VERSION", cols[6]) + "UPDATE"
+	lines = append(lines, tui.Reverse+header)
+
+	status := m.status
+	if m.mode == tuiConfirm {
+		status = []string{fmt.Sprintf("Run nmsmods %s? (y/n)", strings.Join(m.confirm, " "))}
+	}
+	listHeight := max(1, h-len(lines)-len(status)-2)
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+listHeight {
+		m.offset = m.cursor - listHeight + 1
+	}
+	order := map[string]int{}
+	for i, id := range nmsmods.LoadOrder(m.st, m.profile) {
+		order[id] = i + 1
+	}
+	for i := m.offset; i < len(m.ids) && i < m.offset+listHeight; i++ {
+		id := m.ids[i]
+		me := m.st.Mods[id]
+		pos := ""
+		if n, ok := order[id]; ok {
+			pos = fmt.Sprint(n)
+		}
+		name := me.DisplayName
+		if name == "" {
+			name = id
+		}
+		version := ""
+		if me.Nexus != nil {
+			version = me.Nexus.Version
+		}
+		row := " " + tui.Pad(pos, cols[0]-1) + tui.Pad(id, cols[1]) + tui.Pad(name, cols[2]) +
+			tui.Pad(m.modState(me), cols[3]) + tui.Pad(m.otherProfiles(me), cols[4]) +
+			tui.Pad(me.Health, cols[5]) + tui.Pad(version, cols[6]) + m.updateState(id, me)
+		if i == m.cursor {
+			row = tui.Reverse + row
+		}
+		lines = append(lines, row)
+	}
+	if len(m.ids) == 0 {
+		lines = append(lines, "  No mods tracked yet (run: nmsmods download <url-or-zip>).")
+	}
+	for len(lines) < h-len(status)-1 {
+		lines = append(lines, "")
+	}
+	lines = append(lines, status...)
+	lines = append(lines, tui.Dim+tui.Pad(tuiHelp, w))
+	return lines
+}
+
+func (m *tuiModel) modState(me app.ModEntry) string {
+	pi := me.Installations[m.profile]
+	switch {
+	case pi.Installed && pi.Enabled:
+		return "enabled"
+	case pi.Installed:
+		return "disabled"
+	case me.ZIP != "":
+		return "download"
+	}
+	return "-"
+}
+
+// otherProfiles summarizes the mod in the other profiles: name+ enabled, name- disabled.
+func (m *tuiModel) otherProfiles(me app.ModEntry) string {
+	names := make([]string, 0, len(me.Installations))
+	for prof := range me.Installations {
+		names = append(names, prof)
+	}
+	sort.Strings(names)
+	parts := []string{}
+	for _, prof := range names {
+		pi := me.Installations[prof]
+		if prof == m.profile || !pi.Installed {
+			continue
+		}
+		if pi.Enabled {
+			parts = append(parts, prof+"+")
+		} else {
+			parts = append(parts, prof+"-")
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func (m *tuiModel) updateState(id string, me app.ModEntry) string {
+	if me.Nexus == nil {
+		return ""
+	}
+	r, ok := m.updates[id]
+	switch {
+	case me.Nexus.Pinned:
+		return "pinned"
+	case !ok:
+		return "?"
+	case r.HasUpdate && r.Latest != nil && r.Latest.Version != "":
+		return "-> " + r.Latest.Version
+	case r.HasUpdate:
+		return "available"
+	}
+	return "up to date"
+}
@@ -2,142 +2,80 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
-	"strconv"
 
-	"nmsmods/internal/app"
-	"nmsmods/internal/mods"
+	"nmsmods/pkg/nmsmods"
 
 	"github.com/spf13/cobra"
 )
 
 var dryRunUninstall bool
+var uninstallSel modSelectorFlags
 
 var uninstallCmd = &cobra.Command{
-	Use:   "uninstall <id-or-index-or-folder>",
+	Use:   "uninstall <mod-or-folder>... | --all | --source/--health/--tag/--category/--outdated",
 	Short: "Uninstall a mod from the active profile (and undeploy from the game)",
-	Args:  cobra.ExactArgs(1),
+	Long: `Uninstall mods from the active profile (or --profile): the store folder goes to the trash and
+the deployed folder leaves the game. A single argument may also be a folder name in
+GAMEDATA/MODS; an untracked folder goes to the trash too. Several ids, handles or globs on ids and the selectors (see: nmsmods enable
+--help) run as one operation with one history entry.`,
+	Args: cobra.ArbitraryArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		p := mustPaths()
-		target := args[0]
-
-		return withStateLock(p, func() error {
-			cfg, game, err := requireGame(p)
-			if err != nil {
-				return err
-			}
-			profile, err := ensureActiveProfileDirs(p, cfg)
-			if err != nil {
-				return err
-			}
-
-			st, err := app.LoadState(p.State)
-			if err != nil {
-				return err
-			}
-
-			treatedAsTracked := false
-			var trackedID string
-
-			if _, aerr := strconv.Atoi(target); aerr == nil {
-				if id, rerr := resolveModArg(target, st); rerr == nil {
-					treatedAsTracked = true
-					trackedID = id
-				}
-			} else {
-				if _, ok := st.Mods[target]; ok {
-					treatedAsTracked = true
-					trackedID = target
-				}
-			}
-
-			if treatedAsTracked {
-				me := st.Mods[trackedID]
-				pi, ok := me.Installations[profile]
-				if !ok || !pi.Installed || pi.Folder == "" {
-					return fmt.Errorf("mod %s is not installed in profile %q", trackedID, profile)
-				}
-
-				storeAbs := filepath.Join(p.Root, filepath.FromSlash(pi.Store))
-				deployDest := filepath.Join(game.ModsDir, pi.Folder)
-
-				if dryRunUninstall {
-					fmt.Println("[dry-run] Would uninstall from profile:")
-					fmt.Println("  profile:", profile)
-					fmt.Println("  id:     ", trackedID)
-					fmt.Println("  folder: ", pi.Folder)
-					fmt.Println("  store:  ", storeAbs)
-					fmt.Println("  deploy: ", deployDest)
-					fmt.Println("  action:  REMOVE store + undeploy; set installed=false in state.json for this profile")
-					return nil
-				}
-
-				// Undeploy first (so game state is clean even if store removal fails).
-				if err := mods.Undeploy(game.ModsDir, pi.Folder, trackedID, profile); err != nil {
-					return err
-				}
-
-				if pi.Store != "" {
-					if _, err := os.Stat(storeAbs); err == nil {
-						if err := os.RemoveAll(storeAbs); err != nil {
-							return err
-						}
-					}
-				}
-
-				pi.Installed = false
-				pi.Enabled = false
-				pi.DeployedPath = ""
-				pi.InstalledAt = ""
-				me.Installations[profile] = pi
-
-				// Legacy fields best-effort.
-				me.Installed = false
-				me.InstalledAt = ""
-				me.InstalledPath = ""
-				me.Health = ""
-
-				st.Mods[trackedID] = me
-				if err := app.SaveState(p.State, st); err != nil {
-					return err
-				}
-
-				fmt.Println("Uninstalled:", trackedID, "(profile:", profile+")")
+		m := newManager(mustPaths(), commandLineOption(cmd, args))
+		opts := nmsmods.UninstallOptions{DryRun: dryRunUninstall}
+		if uninstallSel.batch(args) {
+			results, err := m.UninstallMods(cmd.Context(), uninstallSel.selector(args), opts)
+			setResult(results)
+			if err == nil && len(results) == 0 {
+				fmt.Println("No mods matched.")
 				return nil
 			}
-
-			// Otherwise treat as folder name directly (DANGEROUS - not tracked).
-			// Still validate it's a single segment to avoid accidental traversal.
-			folder, err := mods.SanitizeFolderName(target, target)
-			if err != nil {
-				return err
+			for _, r := range results {
+				printUninstalled(r)
 			}
-			dest, err := mods.SafeJoinUnder(game.ModsDir, folder)
 			if err != nil {
 				return err
 			}
-
-			if dryRunUninstall {
-				fmt.Println("[dry-run] Would uninstall by folder (untracked):")
-				fmt.Println("  folder: ", target)
-				fmt.Println("  dest:   ", dest)
-				fmt.Println("  action:  REMOVE folder")
-				return nil
-			}
-
-			if _, err := os.Stat(dest); os.IsNotExist(err) {
-				return fmt.Errorf("not found: %s", dest)
+			if !dryRunUninstall {
+				fmt.Printf("%d mod(s) uninstalled (profile: %s)\n", len(results), results[0].Profile)
 			}
-			if err := os.RemoveAll(dest); err != nil {
-				return err
-			}
-			fmt.Println("Removed (untracked):", dest)
 			return nil
-		})
+		}
+
+		res, err := m.Uninstall(cmd.Context(), args[0], opts)
+		if err != nil {
+			return err
+		}
+		setResult(res)
+		printUninstalled(res)
+		return nil
 	},
 }
 
+// printUninstalled reports an uninstall, or with --dry-run what it would do.
+func printUninstalled(res nmsmods.UninstallResult) {
+	switch {
+	case res.ID == "" && res.DryRun:
+		fmt.Println("[dry-run] Would uninstall by folder (untracked):")
+		fmt.Println("  folder: ", res.Folder)
+		fmt.Println("  dest:   ", res.RemovedPath)
+		fmt.Println("  action:  MOVE folder to trash")
+	case res.ID == "":
+		fmt.Println("Removed (untracked):", res.RemovedPath, "- kept in trash:", res.TrashID)
+	case res.DryRun:
+		fmt.Println("[dry-run] Would uninstall from profile:")
+		fmt.Println("  profile:", res.Profile)
+		fmt.Println("  id:     ", res.ID)
+		fmt.Println("  folder: ", res.Folder)
+		fmt.Println("  store:  ", res.StorePath)
+		fmt.Println("  deploy: ", filepath.Join(res.Game.ModsDir, res.Folder))
+		fmt.Println("  action:  MOVE store to trash + undeploy; set installed=false in state.json for this profile")
+	default:
+		fmt.Println("Uninstalled:", res.ID, "(profile:", res.Profile+")")
+	}
+}
+
 func init() {
 	uninstallCmd.Flags().BoolVar(&dryRunUninstall, "dry-run", false, "Print what would happen without making changes")
+	uninstallSel.register(uninstallCmd)
 }
@@ -7,7 +7,8 @@ import (
 	"path/filepath"
 	"strings"
 
-	"nmsmods/internal/app"
+	"nmsmods/internal/nms/psarc"
+	"nmsmods/pkg/nmsmods"
 
 	"github.com/spf13/cobra"
 )
@@ -15,12 +16,12 @@ import (
 var verifyJSON bool
 
 var verifyCmd = &cobra.Command{
-	Use:   "verify <id-or-index>",
+	Use:   "verify <mod>",
 	Short: "Verify installed mod integrity",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		p := mustPaths()
-		st, err := app.LoadState(p.State)
+		st, err := loadState(p)
 		if err != nil {
 			return err
 		}
@@ -30,16 +31,25 @@ var verifyCmd = &cobra.Command{
 			return err
 		}
 
+		cfg, err := loadConfig(p)
+		if err != nil {
+			return err
+		}
+		profile := activeProfile(&cfg)
 		me := st.Mods[id]
-		if !me.Installed {
-			return fmt.Errorf("mod not installed")
+		pi := me.Installations[profile]
+		if !pi.Installed || pi.Store == "" {
+			return &nmsmods.NotInstalledError{ID: id, Profile: profile}
 		}
+		// The profile store is authoritative; the deployed copy only exists for enabled mods.
+		storeAbs := joinPathFromState(p.Root, pi.Store)
 
 		countEXML := 0
 		countMBIN := 0
 		countPAK := 0
+		countPakEntries := 0
 
-		err = filepath.Walk(me.InstalledPath, func(path string, info os.FileInfo, err error) error {
+		err = filepath.Walk(storeAbs, func(path string, info os.FileInfo, err error) error {
 			if err != nil {
 				return err
 			}
@@ -55,6 +65,10 @@ var verifyCmd = &cobra.Command{
 			}
 			if strings.HasSuffix(name, ".pak") {
 				countPAK++
+				if a, aerr := psarc.Open(path); aerr == nil {
+					countPakEntries += len(a.Files())
+					_ = a.Close()
+				}
 			}
 			return nil
 		})
@@ -62,24 +76,37 @@ var verifyCmd = &cobra.Command{
 			return err
 		}
 
+		ref, closeRef := bestEffortMBINReference(p)
+		defer closeRef()
+		stale := staleMBINPaths(storeAbs, ref)
+
 		result := map[string]any{
-			"id":           id,
-			"installed":    me.Installed,
-			"exml_count":   countEXML,
-			"mbin_count":   countMBIN,
-			"pak_count":    countPAK,
-			"health":       me.Health,
-			"installed_at": me.InstalledAt,
+			"id":            id,
+			"installed":     pi.Installed,
+			"profile":       profile,
+			"enabled":       pi.Enabled,
+			"store":         storeAbs,
+			"deployed_path": pi.DeployedPath,
+			"exml_count":    countEXML,
+			"mbin_count":    countMBIN,
+			"pak_count":     countPAK,
+			"pak_entries":   countPakEntries,
+			"stale_mbins":   stale,
+			"health":        me.Health,
+			"installed_at":  pi.InstalledAt,
 		}
 
 		if verifyJSON {
 			return json.NewEncoder(cmd.OutOrStdout()).Encode(result)
 		}
 
-		fmt.Printf("EXML: %d, MBIN: %d, PAK: %d\n", countEXML, countMBIN, countPAK)
+		fmt.Printf("EXML: %d, MBIN: %d, PAK: %d (%d packed files)\n", countEXML, countMBIN, countPAK, countPakEntries)
 		if countPAK > 0 {
 			fmt.Println("Warning: PAK files detected (likely incompatible with NMS 5.50+)")
 		}
+		if len(stale) > 0 {
+			fmt.Printf("Warning: %d MBIN file(s) differ from the game's version (run: nmsmods scan-stale %s)\n", len(stale), id)
+		}
 		return nil
 	},
 }
@@ -0,0 +1,458 @@
+package cmd
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"nmsmods/internal/app"
+	"nmsmods/internal/watch"
+	"nmsmods/pkg/nmsmods"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	watchInstall  bool
+	watchExisting bool
+)
+
+var watchCmd = &cobra.Command{
+	Use:         "watch [dir]",
+	Short:       "Import mod archives as they are downloaded into a directory (and optionally install them)",
+	Annotations: map[string]string{textOnlyAnnotation: "true"},
+	Long: `Watch a directory (default: watch.dir in config.json, else your XDG download directory) and
+import every .zip that finishes arriving there, like 'nmsmods download <zip>'. Partial browser
+downloads (.part, .crdownload) are ignored until they are renamed to their final name.
+
+Rules decide the mod id and whether to install. They are tried in order and the first glob
+matching the file name wins:
+
+  nmsmods watch rule add 'BetterFlight-*.zip' --id better-flight --install
+  nmsmods watch rule add 'Experimental*' --install --disabled
+
+Without a rule the id is derived from the file name (Nexus' "-<mod id>-<version>-<time>"
+suffix is dropped) and the archive is only imported, unless --install is given. Installs go
+into the active profile (or --profile) and follow the usual lock: if another nmsmods command
+holds it, the watcher waits for it.
+
+Run it in the background with a systemd user service: nmsmods watch unit --write`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p := mustPaths()
+		cfg, err := loadConfig(p)
+		if err != nil {
+			return err
+		}
+		dir, err := watchDir(cfg, args)
+		if err != nil {
+			return err
+		}
+		w, err := watch.New(dir)
+		if err != nil {
+			return err
+		}
+		defer w.Close()
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		out := cmd.OutOrStdout()
+		fmt.Fprintf(out, "Watching %s for .zip archives (Ctrl-C to stop)\n", dir)
+		slog.Info("watch started", "dir", dir, "install_unmatched", watchInstall)
+
+		seen := map[string]time.Time{} // path -> mtime already handled
+		handle := func(path string) {
+			if !watch.IsArchive(filepath.Base(path)) {
+				return
+			}
+			fi, err := os.Stat(path)
+			if err != nil || !fi.Mode().IsRegular() {
+				return
+			}
+			if t, ok := seen[path]; ok && t.Equal(fi.ModTime()) {
+				return
+			}
+			seen[path] = fi.ModTime()
+			if err := watchImport(ctx, p, out, path); err != nil {
+				slog.Error("watch import failed", "file", path, "error", err)
+				fmt.Fprintf(cmd.ErrOrStderr(), "Error: %s: %v\n", filepath.Base(path), err)
+			}
+		}
+
+		if watchExisting {
+			ents, err := os.ReadDir(dir)
+			if err != nil {
+				return err
+			}
+			for _, e := range ents {
+				handle(filepath.Join(dir, e.Name()))
+			}
+		}
+		for {
+			path, err := w.Next(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					fmt.Fprintln(out, "Stopped watching", dir)
+					return nil
+				}
+				return err
+			}
+			handle(path)
+		}
+	},
+}
+
+// watchImport imports one archive and installs it when its rule (or --install) says so.
+func watchImport(ctx context.Context, p *app.Paths, out io.Writer, path string) error {
+	name := filepath.Base(path)
+	if err := checkZip(path); err != nil {
+		return err
+	}
+	cfg, err := loadConfig(p)
+	if err != nil {
+		return err
+	}
+	rule, matched := cfg.Watch.Match(name)
+	id := rule.ID
+	if id == "" {
+		id = watch.GuessID(name)
+	}
+	install := rule.Install || (!matched && watchInstall)
+
+	m := newManager(p, nmsmods.WithProgress(func(string) {}))
+	var dl nmsmods.DownloadResult
+	if err := retryLockBusy(ctx, func() (err error) {
+		dl, err = m.Download(ctx, path, id)
+		return err
+	}); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Imported %s as %s\n", name, dl.ID)
+	if !install {
+		return nil
+	}
+
+	var res nmsmods.InstallResult
+	if err := retryLockBusy(ctx, func() (err error) {
+		res, err = m.Install(ctx, dl.ID, nmsmods.InstallOptions{Disabled: rule.Disabled})
+		return err
+	}); err != nil {
+		return fmt.Errorf("imported as %s but not installed: %w", dl.ID, err)
+	}
+	switch {
+	case res.DeployedPath != "":
+		fmt.Fprintf(out, "Installed %s into profile %s and deployed to %s\n", res.ID, res.Profile, res.DeployedPath)
+	case res.Enabled:
+		fmt.Fprintf(out, "Installed %s into profile %s (not active, not deployed)\n", res.ID, res.Profile)
+	default:
+		fmt.Fprintf(out, "Installed %s into profile %s (disabled)\n", res.ID, res.Profile)
+	}
+	return nil
+}
+
+// checkZip rejects files that are not (yet) complete zip archives.
+func checkZip(path string) error {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return fmt.Errorf("not a complete zip archive: %w", err)
+	}
+	return r.Close()
+}
+
+// retryLockBusy retries fn while another nmsmods process holds the state lock.
+func retryLockBusy(ctx context.Context, fn func() error) error {
+	for {
+		err := fn()
+		if !errors.Is(err, app.ErrLockBusy) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(2 * time.Second):
+		}
+	}
+}
+
+// watchDir resolves the watched directory: the argument, watch.dir, then the XDG download dir.
+func watchDir(cfg app.Config, args []string) (string, error) {
+	dir := ""
+	switch {
+	case len(args) == 1:
+		dir = args[0]
+	case cfg.Watch.Dir != "":
+		dir = cfg.Watch.Dir
+	default:
+		d, err := xdgDownloadDir()
+		if err != nil {
+			return "", err
+		}
+		dir = d
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	if fi, err := os.Stat(abs); err != nil || !fi.IsDir() {
+		return "", fmt.Errorf("not a directory: %s", abs)
+	}
+	return abs, nil
+}
+
+// xdgDownloadDir returns XDG_DOWNLOAD_DIR from user-dirs.dirs, else ~/Downloads.
+func xdgDownloadDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	cfgHome := os.Getenv("XDG_CONFIG_HOME")
+	if cfgHome == "" {
+		cfgHome = filepath.Join(home, ".config")
+	}
+	if b, err := os.ReadFile(filepath.Join(cfgHome, "user-dirs.dirs")); err == nil {
+		for _, line := range strings.Split(string(b), "\n") {
+			v, ok := strings.CutPrefix(strings.TrimSpace(line), "XDG_DOWNLOAD_DIR=")
+			if !ok {
+				continue
+			}
+			v = strings.ReplaceAll(strings.Trim(v, `"`), "$HOME", home)
+			if v != "" && v != home {
+				return v, nil
+			}
+		}
+	}
+	return filepath.Join(home, "Downloads"), nil
+}
+
+var watchRuleCmd = &cobra.Command{
+	Use:   "rule",
+	Short: "Manage watch rules (file name glob -> mod id, install, enabled/disabled)",
+}
+
+var (
+	watchRuleID       string
+	watchRuleInstall  bool
+	watchRuleDisabled bool
+	watchRuleListJSON bool
+)
+
+var watchRuleAddCmd = &cobra.Command{
+	Use:   "add <glob>",
+	Short: "Add a rule, or replace the rule with the same glob",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		glob := args[0]
+		if err := app.ValidateWatchGlob(glob); err != nil {
+			return &usageError{err: err}
+		}
+		if watchRuleDisabled && !watchRuleInstall {
+			return usageErrorf("--disabled needs --install")
+		}
+		rule := app.WatchRule{Glob: glob, ID: strings.TrimSpace(watchRuleID), Install: watchRuleInstall, Disabled: watchRuleDisabled}
+		p := mustPaths()
+		return withStateLock(p, func() error {
+			cfg, err := loadConfig(p)
+			if err != nil {
+				return err
+			}
+			replaced := false
+			for i, r := range cfg.Watch.Rules {
+				if r.Glob == glob {
+					cfg.Watch.Rules[i] = rule
+					replaced = true
+				}
+			}
+			if !replaced {
+				cfg.Watch.Rules = append(cfg.Watch.Rules, rule)
+			}
+			if err := app.SaveConfig(p.Config, cfg); err != nil {
+				return err
+			}
+			verb := "Added"
+			if replaced {
+				verb = "Updated"
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s rule: %s\n", verb, describeWatchRule(rule))
+			return nil
+		})
+	},
+}
+
+var watchRuleListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List watch rules in the order they are tried",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig(mustPaths())
+		if err != nil {
+			return err
+		}
+		rules := cfg.Watch.Rules
+		if rules == nil {
+			rules = []app.WatchRule{}
+		}
+		if watchRuleListJSON {
+			b, _ := json.MarshalIndent(rules, "", "  ")
+			fmt.Fprintln(cmd.OutOrStdout(), string(b))
+			return nil
+		}
+		if len(rules) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "No watch rules (archives are imported with an id from their file name)")
+			return nil
+		}
+		for i, r := range rules {
+			fmt.Fprintf(cmd.OutOrStdout(), "%d. %s\n", i+1, describeWatchRule(r))
+		}
+		return nil
+	},
+}
+
+var watchRuleRemoveCmd = &cobra.Command{
+	Use:   "remove <glob>",
+	Short: "Remove the rule with this glob",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p := mustPaths()
+		return withStateLock(p, func() error {
+			cfg, err := loadConfig(p)
+			if err != nil {
+				return err
+			}
+			kept := cfg.Watch.Rules[:0]
+			for _, r := range cfg.Watch.Rules {
+				if r.Glob != args[0] {
+					kept = append(kept, r)
+				}
+			}
+			if len(kept) == len(cfg.Watch.Rules) {
+				return usageErrorf("no rule with glob %q (run: nmsmods watch rule list)", args[0])
+			}
+			cfg.Watch.Rules = kept
+			if err := app.SaveConfig(p.Config, cfg); err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), "Removed rule:", args[0])
+			return nil
+		})
+	},
+}
+
+func describeWatchRule(r app.WatchRule) string {
+	id := r.ID
+	if id == "" {
+		id = "(from file name)"
+	}
+	action := "import only"
+	if r.Install {
+		action = "install enabled"
+		if r.Disabled {
+			action = "install disabled"
+		}
+	}
+	return fmt.Sprintf("%s -> %s, %s", r.Glob, id, action)
+}
+
+var watchUnitWrite bool
+
+var watchUnitCmd = &cobra.Command{
+	Use:   "unit [dir]",
+	Short: "Print (or --write) a systemd user service that runs nmsmods watch",
+	Long: `Print a systemd user unit running 'nmsmods watch' with the same directory, --install,
+--home, --profile and --game-target. With --write it is saved as
+~/.config/systemd/user/` + watch.UnitName + `; then enable it with:
+
+  systemctl --user daemon-reload
+  systemctl --user enable --now ` + watch.UnitName,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig(mustPaths())
+		if err != nil {
+			return err
+		}
+		dir, err := watchDir(cfg, args)
+		if err != nil {
+			return err
+		}
+		exe, err := os.Executable()
+		if err != nil {
+			return err
+		}
+		u := watch.Unit{Exe: exe, Dir: dir, Args: []string{"watch", dir}, Env: map[string]string{}}
+		if watchInstall {
+			u.Args = append(u.Args, "--install")
+		}
+		if homeOverride != "" {
+			abs, err := filepath.Abs(homeOverride)
+			if err != nil {
+				return err
+			}
+			u.Args = append(u.Args, "--home", abs)
+		} else if h := os.Getenv("NMSMODS_HOME"); h != "" {
+			u.Env["NMSMODS_HOME"] = h
+		}
+		if profileOverride != "" {
+			u.Args = append(u.Args, "--profile", profileOverride)
+		}
+		if gameTargetOverride != "" {
+			u.Args = append(u.Args, "--game-target", gameTargetOverride)
+		}
+		text := u.Render()
+
+		if !watchUnitWrite {
+			fmt.Fprint(cmd.OutOrStdout(), text)
+			return nil
+		}
+		unitDir, err := systemdUserDir()
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(unitDir, 0o755); err != nil {
+			return err
+		}
+		path := filepath.Join(unitDir, watch.UnitName)
+		if err := app.WriteFileAtomic(path, []byte(text), 0o644); err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), "Wrote", path)
+		fmt.Fprintln(cmd.OutOrStdout(), "Enable it with: systemctl --user daemon-reload && systemctl --user enable --now "+watch.UnitName)
+		return nil
+	},
+}
+
+// systemdUserDir is where systemd looks for user units written by the user.
+func systemdUserDir() (string, error) {
+	if d := os.Getenv("XDG_CONFIG_HOME"); d != "" {
+		return filepath.Join(d, "systemd", "user"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "systemd", "user"), nil
+}
+
+func init() {
+	watchCmd.Flags().BoolVar(&watchInstall, "install", false, "Also install archives no rule matches (into the active profile, or --profile)")
+	watchCmd.Flags().BoolVar(&watchExisting, "existing", false, "Also import the .zip files already in the directory when starting")
+
+	watchRuleAddCmd.Flags().StringVar(&watchRuleID, "id", "", "Mod id to import matching archives as (default: from the file name)")
+	watchRuleAddCmd.Flags().BoolVar(&watchRuleInstall, "install", false, "Install matching archives after importing them")
+	watchRuleAddCmd.Flags().BoolVar(&watchRuleDisabled, "disabled", false, "Install without enabling (with --install)")
+	watchRuleListCmd.Flags().BoolVar(&watchRuleListJSON, "json", false, "Output in JSON format")
+	watchRuleCmd.AddCommand(watchRuleAddCmd, watchRuleListCmd, watchRuleRemoveCmd)
+
+	watchUnitCmd.Flags().BoolVar(&watchInstall, "install", false, "Run the watcher with --install")
+	watchUnitCmd.Flags().BoolVar(&watchUnitWrite, "write", false, "Write the unit to ~/.config/systemd/user instead of printing it")
+	watchCmd.AddCommand(watchRuleCmd, watchUnitCmd)
+}
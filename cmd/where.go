@@ -3,20 +3,27 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"path/filepath"
+
+	"nmsmods/internal/discover"
 
 	"github.com/spf13/cobra"
 )
 
 var whereJSON bool
+var whereAll bool
 
 type whereReport struct {
 	ConfiguredGamePath string   `json:"game_path,omitempty"`
 	DetectedGamePaths  []string `json:"detected_game_paths,omitempty"`
+
+	// Detected lists the same installs labelled with the store/launcher they were found through.
+	Detected []discover.Candidate `json:"detected,omitempty"`
 }
 
 var whereCmd = &cobra.Command{
 	Use:   "where",
-	Short: "Show detected/configured No Man's Sky path",
+	Short: "Show detected/configured No Man's Sky path (--all lists every store/launcher install)",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		p := mustPaths()
 
@@ -25,25 +32,47 @@ var whereCmd = &cobra.Command{
 			return err
 		}
 
-		guesses, _ := detectGamePaths()
+		cands, _ := discover.FindAll()
+		guesses := make([]string, 0, len(cands))
+		for _, c := range cands {
+			guesses = append(guesses, c.Path)
+		}
 
 		if whereJSON {
 			rep := whereReport{
 				ConfiguredGamePath: cfg.GamePath,
 				DetectedGamePaths:  guesses,
+				Detected:           cands,
 			}
 			b, _ := json.MarshalIndent(rep, "", "  ")
 			fmt.Fprintln(cmd.OutOrStdout(), string(b))
 			return nil
 		}
 
+		if whereAll {
+			if len(cands) == 0 {
+				return fmt.Errorf("no No Man's Sky installs detected (run: nmsmods set-path <path>)")
+			}
+			for _, c := range cands {
+				mark := " "
+				if cfg.GamePath != "" && filepath.Clean(cfg.GamePath) == c.Path {
+					mark = "*"
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "%s %-13s %s\n", mark, c.Source, c.Path)
+			}
+			return nil
+		}
+
 		// Preserve the old UX: print configured if set; otherwise print first detected (but do NOT persist).
 		if cfg.GamePath != "" {
 			fmt.Fprintln(cmd.OutOrStdout(), cfg.GamePath)
 			return nil
 		}
-		if len(guesses) > 0 {
-			fmt.Fprintln(cmd.OutOrStdout(), guesses[0])
+		if len(cands) > 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), cands[0].Path)
+			if len(cands) > 1 {
+				fmt.Fprintf(cmd.ErrOrStderr(), "note: %d installs detected (run: nmsmods where --all)\n", len(cands))
+			}
 			return nil
 		}
 		return fmt.Errorf("no game path configured and none detected (run: nmsmods set-path <path>)")
@@ -52,4 +81,5 @@ var whereCmd = &cobra.Command{
 
 func init() {
 	whereCmd.Flags().BoolVar(&whereJSON, "json", false, "Output in JSON format")
+	whereCmd.Flags().BoolVar(&whereAll, "all", false, "List every detected install with its store/launcher")
 }
@@ -2,9 +2,12 @@ module nmsmods
 
 go 1.22
 
-require github.com/spf13/cobra v1.10.2
-
 require (
-	github.com/inconshreveable/mousetrap v1.1.0 // indirect
-	github.com/spf13/pflag v1.0.9 // indirect
+	github.com/spf13/cobra v1.10.2
+	github.com/spf13/pflag v1.0.9
+	github.com/ulikunitz/xz v0.5.15
+	go.etcd.io/bbolt v1.3.6
+	golang.org/x/sys v0.29.0
 )
+
+require github.com/inconshreveable/mousetrap v1.1.0 // indirect
@@ -0,0 +1,345 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	bucketMeta       = []byte("meta")
+	bucketMods       = []byte("mods")
+	bucketIdxNexus   = []byte("idx_nexus")
+	bucketIdxFolder  = []byte("idx_folder")
+	bucketIdxProfile = []byte("idx_profile")
+
+	keySchemaVersion = []byte("schema_version")
+	keyStateVersion  = []byte("state_version")
+	keyNextHandle    = []byte("next_handle")
+)
+
+// boltMigration upgrades the database layout. Migrations run in order inside one transaction
+// when the database is opened; schema_version in the meta bucket records the last applied one.
+type boltMigration struct {
+	Version int
+	Name    string
+	Apply   func(tx *bolt.Tx) error
+}
+
+var boltMigrations = []boltMigration{
+	{Version: 1, Name: "create meta and mods buckets", Apply: func(tx *bolt.Tx) error {
+		for _, b := range [][]byte{bucketMeta, bucketMods} {
+			if _, err := tx.CreateBucketIfNotExists(b); err != nil {
+				return err
+			}
+		}
+		return nil
+	}},
+	{Version: 2, Name: "index by nexus mod id, folder and profile", Apply: func(tx *bolt.Tx) error {
+		for _, b := range [][]byte{bucketIdxNexus, bucketIdxFolder, bucketIdxProfile} {
+			if _, err := tx.CreateBucketIfNotExists(b); err != nil {
+				return err
+			}
+		}
+		return reindexAll(tx)
+	}},
+}
+
+// BoltSchemaVersion is the layout version written by the newest migration.
+var BoltSchemaVersion = boltMigrations[len(boltMigrations)-1].Version
+
+// BoltStore keeps one record per mod entry in state.db and maintains secondary indexes.
+type BoltStore struct {
+	db *bolt.DB
+}
+
+// OpenBoltStore opens (creating if needed) state.db and applies pending schema migrations.
+func OpenBoltStore(path string) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+	s := &BoltStore{db: db}
+	if err := s.migrate(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// peekBoltState reads a state.db without opening it for writing (see PeekState).
+func peekBoltState(path string) (State, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{ReadOnly: true, Timeout: 200 * time.Millisecond})
+	if err != nil {
+		return State{}, fmt.Errorf("open %s: %w", path, err)
+	}
+	defer db.Close()
+	var st State
+	err = db.View(func(tx *bolt.Tx) error {
+		if tx.Bucket(bucketMeta) == nil || tx.Bucket(bucketMods) == nil {
+			return fmt.Errorf("%s: not initialized", path)
+		}
+		st, err = loadTx(tx)
+		return err
+	})
+	return st, err
+}
+
+func (s *BoltStore) migrate() error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		current := 0
+		if meta := tx.Bucket(bucketMeta); meta != nil {
+			current, _ = strconv.Atoi(string(meta.Get(keySchemaVersion)))
+		}
+		if current > BoltSchemaVersion {
+			return fmt.Errorf("state.db schema v%d is newer than this nmsmods supports (v%d); upgrade nmsmods", current, BoltSchemaVersion)
+		}
+		for _, m := range boltMigrations {
+			if m.Version <= current {
+				continue
+			}
+			if err := m.Apply(tx); err != nil {
+				return fmt.Errorf("state.db migration %d (%s): %w", m.Version, m.Name, err)
+			}
+			if err := tx.Bucket(bucketMeta).Put(keySchemaVersion, []byte(strconv.Itoa(m.Version))); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// SchemaVersion returns the applied schema migration version.
+func (s *BoltStore) SchemaVersion() int {
+	v := 0
+	_ = s.db.View(func(tx *bolt.Tx) error {
+		v, _ = strconv.Atoi(string(tx.Bucket(bucketMeta).Get(keySchemaVersion)))
+		return nil
+	})
+	return v
+}
+
+func (s *BoltStore) Backend() string { return BackendBolt }
+func (s *BoltStore) Close() error    { return s.db.Close() }
+
+func (s *BoltStore) Load() (State, error) {
+	var st State
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		st, err = loadTx(tx)
+		return err
+	})
+	if err != nil {
+		return State{}, err
+	}
+	if err := CheckStateVersion(st); err != nil {
+		return State{}, fmt.Errorf("state.db: %w", err)
+	}
+	st, migrated := MigrateState(st)
+	if migrated {
+		if err := s.Save(st); err != nil {
+			return State{}, fmt.Errorf("save migrated state (v%d): %w", st.StateVersion, err)
+		}
+	}
+	return st, nil
+}
+
+func (s *BoltStore) Save(st State) error {
+	if err := s.db.Update(func(tx *bolt.Tx) error { return saveTx(tx, st) }); err != nil {
+		return err
+	}
+	slog.Info("state saved", "backend", "bolt", "path", s.db.Path(), "mods", len(st.Mods))
+	return nil
+}
+
+func (s *BoltStore) Update(fn func(st *State) error) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		st, err := loadTx(tx)
+		if err != nil {
+			return err
+		}
+		if err := CheckStateVersion(st); err != nil {
+			return fmt.Errorf("state.db: %w", err)
+		}
+		st, _ = MigrateState(st)
+		if err := fn(&st); err != nil {
+			return err
+		}
+		return saveTx(tx, st)
+	})
+}
+
+func (s *BoltStore) Get(id string) (ModEntry, bool, error) {
+	var me ModEntry
+	found := false
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketMods).Get([]byte(id))
+		if b == nil {
+			return nil
+		}
+		found = true
+		return json.Unmarshal(b, &me)
+	})
+	return me, found, err
+}
+
+func (s *BoltStore) ByNexusModID(modID int) ([]string, error) {
+	return s.prefixIDs(bucketIdxNexus, strconv.Itoa(modID)+"\x00")
+}
+
+func (s *BoltStore) ByFolder(profile, folder string) ([]string, error) {
+	return s.prefixIDs(bucketIdxFolder, profile+"\x00"+folder+"\x00")
+}
+
+func (s *BoltStore) ByProfile(profile string) ([]string, error) {
+	return s.prefixIDs(bucketIdxProfile, profile+"\x00")
+}
+
+func (s *BoltStore) prefixIDs(bucket []byte, prefix string) ([]string, error) {
+	out := []string{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(bucket).Cursor()
+		p := []byte(prefix)
+		for k, _ := c.Seek(p); k != nil && bytes.HasPrefix(k, p); k, _ = c.Next() {
+			out = append(out, string(k[len(p):]))
+		}
+		return nil
+	})
+	sort.Strings(out)
+	return out, err
+}
+
+func loadTx(tx *bolt.Tx) (State, error) {
+	st := State{Mods: map[string]ModEntry{}}
+	st.StateVersion, _ = strconv.Atoi(string(tx.Bucket(bucketMeta).Get(keyStateVersion)))
+	st.NextHandle, _ = strconv.Atoi(string(tx.Bucket(bucketMeta).Get(keyNextHandle)))
+	err := tx.Bucket(bucketMods).ForEach(func(k, v []byte) error {
+		var me ModEntry
+		if err := json.Unmarshal(v, &me); err != nil {
+			return fmt.Errorf("mod %s: %w", k, err)
+		}
+		st.Mods[string(k)] = me
+		return nil
+	})
+	return st, err
+}
+
+// saveTx writes only entries that changed and keeps the indexes in step.
+func saveTx(tx *bolt.Tx, st State) error {
+	if st.StateVersion == 0 {
+		st.StateVersion = CurrentStateVersion
+	}
+	AssignHandles(&st)
+	mods := tx.Bucket(bucketMods)
+
+	var stale [][]byte
+	if err := mods.ForEach(func(k, _ []byte) error {
+		if _, ok := st.Mods[string(k)]; !ok {
+			stale = append(stale, append([]byte(nil), k...))
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	for _, k := range stale {
+		if err := unindex(tx, string(k), mods.Get(k)); err != nil {
+			return err
+		}
+		if err := mods.Delete(k); err != nil {
+			return err
+		}
+	}
+
+	for id, me := range st.Mods {
+		b, err := json.Marshal(me)
+		if err != nil {
+			return err
+		}
+		old := mods.Get([]byte(id))
+		if old != nil && bytes.Equal(old, b) {
+			continue
+		}
+		if err := unindex(tx, id, old); err != nil {
+			return err
+		}
+		if err := mods.Put([]byte(id), b); err != nil {
+			return err
+		}
+		if err := index(tx, id, me); err != nil {
+			return err
+		}
+	}
+	if err := tx.Bucket(bucketMeta).Put(keyNextHandle, []byte(strconv.Itoa(st.NextHandle))); err != nil {
+		return err
+	}
+	return tx.Bucket(bucketMeta).Put(keyStateVersion, []byte(strconv.Itoa(st.StateVersion)))
+}
+
+func index(tx *bolt.Tx, id string, me ModEntry) error {
+	nexus, folder, profile := indexKeys(id, me)
+	for bucket, keys := range map[string][]string{string(bucketIdxNexus): nexus, string(bucketIdxFolder): folder, string(bucketIdxProfile): profile} {
+		b := tx.Bucket([]byte(bucket))
+		for _, k := range keys {
+			if err := b.Put([]byte(k), nil); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func unindex(tx *bolt.Tx, id string, old []byte) error {
+	if old == nil {
+		return nil
+	}
+	var me ModEntry
+	if err := json.Unmarshal(old, &me); err != nil {
+		return nil // corrupt record: nothing reliable to remove
+	}
+	nexus, folder, profile := indexKeys(id, me)
+	for bucket, keys := range map[string][]string{string(bucketIdxNexus): nexus, string(bucketIdxFolder): folder, string(bucketIdxProfile): profile} {
+		b := tx.Bucket([]byte(bucket))
+		for _, k := range keys {
+			if err := b.Delete([]byte(k)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func reindexAll(tx *bolt.Tx) error {
+	for _, name := range [][]byte{bucketIdxNexus, bucketIdxFolder, bucketIdxProfile} {
+		if err := tx.DeleteBucket(name); err != nil && err != bolt.ErrBucketNotFound {
+			return err
+		}
+		if _, err := tx.CreateBucket(name); err != nil {
+			return err
+		}
+	}
+	return tx.Bucket(bucketMods).ForEach(func(k, v []byte) error {
+		var me ModEntry
+		if err := json.Unmarshal(v, &me); err != nil {
+			return fmt.Errorf("mod %s: %w", k, err)
+		}
+		return index(tx, string(k), me)
+	})
+}
+
+// ImportJSONState copies state.json into a (new or existing) bolt store in one transaction.
+func ImportJSONState(jsonPath string, dst StateStore) (int, error) {
+	st, err := LoadState(jsonPath)
+	if err != nil {
+		return 0, err
+	}
+	if err := dst.Save(st); err != nil {
+		return 0, err
+	}
+	return len(st.Mods), nil
+}
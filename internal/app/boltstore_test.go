@@ -0,0 +1,118 @@
+package app
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestBoltStore_IndexesFollowUpdates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.db")
+	s, err := OpenBoltStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	st := State{Mods: map[string]ModEntry{
+		"a": {Nexus: &NexusInfo{ModID: 42}, Installations: map[string]ProfileInstall{
+			"default": {Installed: true, Folder: "AFolder"},
+		}},
+		"b": {Installations: map[string]ProfileInstall{
+			"default": {Installed: true, Folder: "BFolder"},
+			"test":    {Installed: true, Folder: "BFolder"},
+		}},
+	}}
+	if err := s.Save(st); err != nil {
+		t.Fatal(err)
+	}
+
+	if ids, _ := s.ByNexusModID(42); !reflect.DeepEqual(ids, []string{"a"}) {
+		t.Fatalf("ByNexusModID: %v", ids)
+	}
+	if ids, _ := s.ByProfile("default"); !reflect.DeepEqual(ids, []string{"a", "b"}) {
+		t.Fatalf("ByProfile: %v", ids)
+	}
+
+	// Move a to another folder and drop b: stale index keys must go.
+	err = s.Update(func(st *State) error {
+		me := st.Mods["a"]
+		me.Installations["default"] = ProfileInstall{Installed: true, Folder: "Renamed"}
+		st.Mods["a"] = me
+		delete(st.Mods, "b")
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids, _ := s.ByFolder("default", "AFolder"); len(ids) != 0 {
+		t.Fatalf("stale folder index: %v", ids)
+	}
+	if ids, _ := s.ByFolder("default", "Renamed"); !reflect.DeepEqual(ids, []string{"a"}) {
+		t.Fatalf("ByFolder: %v", ids)
+	}
+	if ids, _ := s.ByProfile("test"); len(ids) != 0 {
+		t.Fatalf("stale profile index: %v", ids)
+	}
+
+	// A failing update changes nothing.
+	boom := errors.New("boom")
+	if err := s.Update(func(st *State) error { delete(st.Mods, "a"); return boom }); !errors.Is(err, boom) {
+		t.Fatalf("expected boom, got %v", err)
+	}
+	if _, ok, _ := s.Get("a"); !ok {
+		t.Fatalf("rolled-back update deleted a")
+	}
+
+	if v := s.SchemaVersion(); v != BoltSchemaVersion {
+		t.Fatalf("schema version %d", v)
+	}
+}
+
+func TestImportJSONState_RoundTrip(t *testing.T) {
+	p := PathsFromRoot(t.TempDir())
+	want := State{StateVersion: CurrentStateVersion, NextHandle: 2, Mods: map[string]ModEntry{"x": {Handle: 1, DisplayName: "X", Source: "local"}}}
+	if err := SaveState(p.State, want); err != nil {
+		t.Fatal(err)
+	}
+	s, err := OpenBoltStore(p.StateDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if n, err := ImportJSONState(p.State, s); err != nil || n != 1 {
+		t.Fatalf("import: %d %v", n, err)
+	}
+	got, err := s.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("round trip:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestPeekState_ReadsWithoutWaitingForWriter(t *testing.T) {
+	p := PathsFromRoot(t.TempDir())
+	s, err := OpenBoltStore(p.StateDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Save(State{Mods: map[string]ModEntry{"x": {DisplayName: "X"}}}); err != nil {
+		t.Fatal(err)
+	}
+	// The open store holds state.db for writing: peeking gives up instead of blocking.
+	if _, err := PeekState(p); err == nil {
+		t.Fatal("PeekState succeeded while state.db was open for writing")
+	}
+	s.Close()
+
+	st, err := PeekState(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if me, ok := st.Mods["x"]; !ok || me.DisplayName != "X" || me.Handle != 1 {
+		t.Fatalf("peeked %+v", st.Mods)
+	}
+}
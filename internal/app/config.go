@@ -2,8 +2,8 @@ package app
 
 import (
 	"encoding/json"
+	"log/slog"
 	"os"
-	"path/filepath"
 )
 
 type NexusConfig struct {
@@ -14,8 +14,34 @@ type Config struct {
 	GamePath      string      `json:"game_path"`
 	ActiveProfile string      `json:"active_profile,omitempty"`
 	Nexus         NexusConfig `json:"nexus,omitempty"`
+
+	// Targets are additional named game installations; game_path is the "default" target.
+	Targets map[string]GameTarget `json:"targets,omitempty"`
+	// DefaultTarget is used when neither --game-target nor a profile binding picks one.
+	DefaultTarget string `json:"default_target,omitempty"`
+
+	// LaunchCommand is run through `sh -c` by `nmsmods play`.
+	// Empty means DefaultLaunchCommand.
+	LaunchCommand string `json:"launch_command,omitempty"`
+
+	Trash TrashConfig `json:"trash,omitempty"`
+
+	Watch WatchConfig `json:"watch,omitempty"`
 }
 
+// TrashConfig controls how long deleted stores, downloads and state stay recoverable.
+type TrashConfig struct {
+	// RetentionDays deletes items older than this many days (0 = DefaultTrashRetentionDays,
+	// negative = keep forever).
+	RetentionDays int `json:"retention_days,omitempty"`
+	// MaxSizeMB deletes the oldest items once the trash grows past this size (0 = unlimited).
+	// The newest item is never deleted this way.
+	MaxSizeMB int `json:"max_size_mb,omitempty"`
+}
+
+// DefaultLaunchCommand starts No Man's Sky through Steam (works for native Steam and Proton).
+const DefaultLaunchCommand = "steam -applaunch 275850"
+
 func LoadConfig(path string) (Config, error) {
 	var c Config
 	b, err := os.ReadFile(path)
@@ -39,9 +65,10 @@ func SaveConfig(path string, c Config) error {
 	if err != nil {
 		return err
 	}
-	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+	// Config may contain secrets (Nexus API key), so 0600. Only the fact of the save is logged.
+	if err := WriteFileAtomic(path, b, 0o600); err != nil {
 		return err
 	}
-	// Config may contain secrets (Nexus API key), so 0600.
-	return os.WriteFile(path, b, 0o600)
+	slog.Info("config saved", "path", path, "active_profile", ActiveProfile(c))
+	return nil
 }
@@ -0,0 +1,41 @@
+package app
+
+import "sort"
+
+// AssignHandles gives every entry without a handle the next unused one, in id order, and
+// advances st.NextHandle past every handle in use. Handles are never reused: NextHandle only
+// grows, so a removed mod's handle stays retired. It reports whether anything changed.
+func AssignHandles(st *State) bool {
+	next := st.NextHandle
+	if next < 1 {
+		next = 1
+	}
+	var missing []string
+	for id, me := range st.Mods {
+		if me.Handle == 0 {
+			missing = append(missing, id)
+		} else if me.Handle >= next {
+			next = me.Handle + 1
+		}
+	}
+	sort.Strings(missing)
+	for _, id := range missing {
+		me := st.Mods[id]
+		me.Handle = next
+		next++
+		st.Mods[id] = me
+	}
+	changed := len(missing) > 0 || next != st.NextHandle
+	st.NextHandle = next
+	return changed
+}
+
+// ModByHandle returns the id of the entry with handle h.
+func ModByHandle(st State, h int) (string, bool) {
+	for id, me := range st.Mods {
+		if me.Handle == h {
+			return id, true
+		}
+	}
+	return "", false
+}
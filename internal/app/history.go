@@ -0,0 +1,219 @@
+package app
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// History entry kinds.
+const (
+	HistoryOp   = "op"
+	HistoryUndo = "undo"
+	HistoryRedo = "redo"
+)
+
+// ModChange is a before/after snapshot of one state.json entry. A nil side means the
+// entry did not exist.
+type ModChange struct {
+	ID     string    `json:"id"`
+	Before *ModEntry `json:"before,omitempty"`
+	After  *ModEntry `json:"after,omitempty"`
+}
+
+// StoreChange records a profile store folder replaced or created by an operation.
+// All paths are relative to Root.
+type StoreChange struct {
+	Store string `json:"store"`           // e.g. profiles/default/mods/<folder>
+	Trash string `json:"trash,omitempty"` // where the pre-operation content was moved ("" = none)
+
+	// Displaced is set on undo entries: where the post-operation content was moved so redo
+	// can put it back.
+	Displaced string `json:"displaced,omitempty"`
+}
+
+// HistoryEntry is one line of history.jsonl. Operations are never rewritten; undo and redo
+// append their own entries pointing at the operation (Target).
+type HistoryEntry struct {
+	Seq     int    `json:"seq"`
+	Time    string `json:"time"`
+	Kind    string `json:"kind"`
+	Command string `json:"command,omitempty"`
+	Target  int    `json:"target,omitempty"`
+
+	ProfileBefore string `json:"profile_before,omitempty"`
+	ProfileAfter  string `json:"profile_after,omitempty"`
+
+	Changes []ModChange   `json:"changes,omitempty"`
+	Stores  []StoreChange `json:"stores,omitempty"`
+}
+
+// HistoryPath is the append-only operation log under the state root.
+func HistoryPath(p *Paths) string { return filepath.Join(p.Root, "history.jsonl") }
+
+// TrashDir holds store folders removed or replaced by recorded operations.
+func TrashDir(p *Paths) string { return filepath.Join(p.Root, "trash") }
+
+// ReadHistory loads every entry of history.jsonl (missing file = empty history).
+func ReadHistory(p *Paths) ([]HistoryEntry, error) {
+	f, err := os.Open(HistoryPath(p))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	out := []HistoryEntry{}
+	s := bufio.NewScanner(f)
+	s.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	line := 0
+	for s.Scan() {
+		line++
+		if len(s.Bytes()) == 0 {
+			continue
+		}
+		var e HistoryEntry
+		if err := json.Unmarshal(s.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("history.jsonl line %d: %w", line, err)
+		}
+		out = append(out, e)
+	}
+	return out, s.Err()
+}
+
+// AppendHistory assigns the next sequence number and appends e to history.jsonl.
+func AppendHistory(p *Paths, e HistoryEntry) (HistoryEntry, error) {
+	prev, err := ReadHistory(p)
+	if err != nil {
+		return e, err
+	}
+	e.Seq = 1
+	if n := len(prev); n > 0 {
+		e.Seq = prev[n-1].Seq + 1
+	}
+	if e.Time == "" {
+		e.Time = NowRFC3339()
+	}
+	b, err := json.Marshal(e)
+	if err != nil {
+		return e, err
+	}
+	f, err := os.OpenFile(HistoryPath(p), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return e, err
+	}
+	defer f.Close()
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		return e, err
+	}
+	slog.Info("history recorded", "seq", e.Seq, "kind", e.Kind, "command", e.Command, "changes", len(e.Changes))
+	return e, f.Sync()
+}
+
+// HistoryStacks replays the log and returns the operations that can be undone and redone,
+// each ordered oldest first (the next candidate is the last element).
+// A new operation clears the redo stack.
+func HistoryStacks(entries []HistoryEntry) (undo []HistoryEntry, redo []HistoryEntry) {
+	bySeq := map[int]HistoryEntry{}
+	for _, e := range entries {
+		switch e.Kind {
+		case HistoryOp:
+			bySeq[e.Seq] = e
+			undo = append(undo, e)
+			redo = nil
+		case HistoryUndo:
+			if n := len(undo); n > 0 && undo[n-1].Seq == e.Target {
+				undo = undo[:n-1]
+				redo = append(redo, bySeq[e.Target])
+			}
+		case HistoryRedo:
+			if n := len(redo); n > 0 && redo[n-1].Seq == e.Target {
+				redo = redo[:n-1]
+				undo = append(undo, bySeq[e.Target])
+			}
+		}
+	}
+	return undo, redo
+}
+
+// LastUndo returns the most recent undo entry for an operation (used by redo to find
+// displaced store folders).
+func LastUndo(entries []HistoryEntry, target int) (HistoryEntry, bool) {
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].Kind == HistoryUndo && entries[i].Target == target {
+			return entries[i], true
+		}
+	}
+	return HistoryEntry{}, false
+}
+
+// DiffModEntries returns entries that differ between two states, ignoring DeployedPath
+// (it is recomputed on every redeploy).
+func DiffModEntries(before, after State) []ModChange {
+	ids := map[string]struct{}{}
+	for id := range before.Mods {
+		ids[id] = struct{}{}
+	}
+	for id := range after.Mods {
+		ids[id] = struct{}{}
+	}
+	sorted := make([]string, 0, len(ids))
+	for id := range ids {
+		sorted = append(sorted, id)
+	}
+	sort.Strings(sorted)
+
+	out := []ModChange{}
+	for _, id := range sorted {
+		b, bok := before.Mods[id]
+		a, aok := after.Mods[id]
+		if bok && aok && comparableEntry(b) == comparableEntry(a) {
+			continue
+		}
+		c := ModChange{ID: id}
+		if bok {
+			c.Before = &b
+		}
+		if aok {
+			c.After = &a
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// SameModEntry reports whether two snapshots describe the same entry (nil = no entry),
+// ignoring DeployedPath and Handle, which redeploys and state migrations rewrite.
+func SameModEntry(a, b *ModEntry) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return comparableEntry(*a) == comparableEntry(*b)
+}
+
+func comparableEntry(me ModEntry) string {
+	me.Handle = 0
+	inst := map[string]ProfileInstall{}
+	for prof, pi := range me.Installations {
+		pi.DeployedPath = ""
+		inst[prof] = pi
+	}
+	me.Installations = inst
+	b, _ := json.Marshal(me)
+	return string(b)
+}
+
+// RelToRoot returns abs relative to p.Root in slash form, as history and state record paths.
+func RelToRoot(p *Paths, abs string) string {
+	rel, err := filepath.Rel(p.Root, abs)
+	if err != nil {
+		return filepath.ToSlash(abs)
+	}
+	return filepath.ToSlash(rel)
+}
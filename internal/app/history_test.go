@@ -0,0 +1,49 @@
+package app
+
+import "testing"
+
+func TestHistoryStacks_UndoRedoAndNewOpClearsRedo(t *testing.T) {
+	p := PathsFromRoot(t.TempDir())
+	if err := p.Ensure(); err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range []HistoryEntry{
+		{Kind: HistoryOp, Command: "install a"},
+		{Kind: HistoryOp, Command: "install b"},
+		{Kind: HistoryUndo, Target: 2},
+	} {
+		if _, err := AppendHistory(p, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := ReadHistory(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	undo, redo := HistoryStacks(entries)
+	if len(undo) != 1 || undo[0].Seq != 1 || len(redo) != 1 || redo[0].Seq != 2 {
+		t.Fatalf("after undo: undo=%v redo=%v", undo, redo)
+	}
+
+	if _, err := AppendHistory(p, HistoryEntry{Kind: HistoryOp, Command: "install c"}); err != nil {
+		t.Fatal(err)
+	}
+	entries, _ = ReadHistory(p)
+	undo, redo = HistoryStacks(entries)
+	if len(undo) != 2 || undo[1].Seq != 4 || len(redo) != 0 {
+		t.Fatalf("after new op: undo=%v redo=%v", undo, redo)
+	}
+}
+
+func TestSameModEntry_IgnoresDeployedPathAndHandle(t *testing.T) {
+	a := ModEntry{Handle: 3, ZIP: "downloads/a.zip", Installations: map[string]ProfileInstall{"default": {Installed: true, DeployedPath: "/g/A"}}}
+	b := ModEntry{ZIP: "downloads/a.zip", Installations: map[string]ProfileInstall{"default": {Installed: true}}}
+	if !SameModEntry(&a, &b) {
+		t.Fatal("entries differing only in handle and deployed path should match")
+	}
+	b.Nexus = &NexusInfo{ModID: 1, Pinned: true}
+	if SameModEntry(&a, &b) || SameModEntry(&a, nil) || !SameModEntry(nil, nil) {
+		t.Fatal("SameModEntry ignores real changes")
+	}
+}
@@ -1,13 +1,16 @@
 package app
 
 import (
-	"fmt"
+	"errors"
 	"os"
 	"path/filepath"
 	"runtime"
 	"syscall"
 )
 
+// ErrLockBusy is returned by AcquireLock when another process holds the state lock.
+var ErrLockBusy = errors.New("another nmsmods process is running (lock busy)")
+
 type Lock struct {
 	f *os.File
 }
@@ -27,7 +30,7 @@ func AcquireLock(root string) (*Lock, error) {
 	if runtime.GOOS != "windows" {
 		if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
 			_ = f.Close()
-			return nil, fmt.Errorf("another nmsmods process is running (lock busy)")
+			return nil, ErrLockBusy
 		}
 	}
 
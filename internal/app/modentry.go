@@ -1,11 +1,23 @@
 package app
 
+import (
+	"fmt"
+	"regexp"
+)
+
+var modIDRe = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]{0,127}$`)
+
+// ValidateModID ensures a new mod id is safe to use in file names (downloads/<id>.zip and the
+// staging directory).
+func ValidateModID(id string) error {
+	if !modIDRe.MatchString(id) {
+		return fmt.Errorf("invalid mod id %q (allowed: letters, digits, ., _, -, max 128 chars)", id)
+	}
+	return nil
+}
+
 // IsInstalledInAnyProfile returns true if the mod entry is installed in at least one profile.
-// It also checks legacy v2 fields for backwards compatibility.
 func IsInstalledInAnyProfile(me ModEntry) bool {
-	if me.Installed {
-		return true
-	}
 	for _, pi := range me.Installations {
 		if pi.Installed {
 			return true
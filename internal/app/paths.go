@@ -11,8 +11,9 @@ type Paths struct {
 	Staging   string
 	Profiles  string
 
-	Config string
-	State  string
+	Config  string
+	State   string
+	StateDB string // present only after `nmsmods state migrate --to bolt`
 }
 
 func PathsFromRoot(root string) *Paths {
@@ -23,6 +24,7 @@ func PathsFromRoot(root string) *Paths {
 		Profiles:  filepath.Join(root, "profiles"),
 		Config:    filepath.Join(root, "config.json"),
 		State:     filepath.Join(root, "state.json"),
+		StateDB:   filepath.Join(root, "state.db"),
 	}
 }
 
@@ -48,6 +50,7 @@ func pathsFromXDG(home string) *Paths {
 		Profiles:  filepath.Join(stateDir, "profiles"),
 		Config:    filepath.Join(configDir, "config.json"),
 		State:     filepath.Join(stateDir, "state.json"),
+		StateDB:   filepath.Join(stateDir, "state.db"),
 	}
 }
 
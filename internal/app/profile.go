@@ -1,6 +1,7 @@
 package app
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -41,3 +42,98 @@ func EnsureProfileDirs(p *Paths, profile string) error {
 	}
 	return os.MkdirAll(ProfileModsDir(p, profile), 0o755)
 }
+
+// Deploy strategies for ProfileSettings.DeployStrategy.
+const (
+	DeployCopy     = "copy"     // copy store files into GAMEDATA/MODS (default)
+	DeployHardlink = "hardlink" // hard-link store files (falls back to copying across filesystems)
+)
+
+// ProfileSettings is profiles/<name>/profile.json: per-profile behaviour that used to be
+// global (or did not exist).
+type ProfileSettings struct {
+	Description string `json:"description,omitempty"`
+	// Target binds the profile to a game target (see ResolveTarget).
+	Target string `json:"target,omitempty"`
+	// DeployStrategy is DeployCopy (default) or DeployHardlink.
+	DeployStrategy string `json:"deploy_strategy,omitempty"`
+	// NewModsDisabled installs new mods into the store without deploying them.
+	NewModsDisabled bool `json:"new_mods_disabled,omitempty"`
+}
+
+// ResolvedProfile is the profile a command operates on, with its settings.
+type ResolvedProfile struct {
+	Name     string
+	Settings ProfileSettings
+}
+
+// ProfileSettingsPath returns profiles/<name>/profile.json.
+func ProfileSettingsPath(p *Paths, profile string) string {
+	return filepath.Join(ProfileRoot(p, profile), "profile.json")
+}
+
+// LoadProfileSettings reads profile.json; a missing file means defaults.
+func LoadProfileSettings(p *Paths, profile string) (ProfileSettings, error) {
+	var s ProfileSettings
+	if err := ValidateProfileName(profile); err != nil {
+		return s, err
+	}
+	b, err := os.ReadFile(ProfileSettingsPath(p, profile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return s, err
+	}
+	if err := json.Unmarshal(b, &s); err != nil {
+		return s, fmt.Errorf("%s: %w", ProfileSettingsPath(p, profile), err)
+	}
+	return s, nil
+}
+
+// SaveProfileSettings validates and writes profile.json.
+func SaveProfileSettings(p *Paths, profile string, s ProfileSettings) error {
+	if err := ValidateProfileName(profile); err != nil {
+		return err
+	}
+	switch s.DeployStrategy {
+	case "", DeployCopy, DeployHardlink:
+	default:
+		return fmt.Errorf("invalid deploy strategy %q (use %q or %q)", s.DeployStrategy, DeployCopy, DeployHardlink)
+	}
+	if s.Target != "" {
+		if err := ValidateTargetName(s.Target); err != nil {
+			return err
+		}
+	}
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return WriteFileAtomic(ProfileSettingsPath(p, profile), b, 0o644)
+}
+
+// ProfileExists reports whether profile was created (profile use or profile set made its
+// directory). "default" and the configured active profile always exist; their directories are
+// created on first use.
+func ProfileExists(p *Paths, c Config, profile string) bool {
+	if profile == "default" || profile == ActiveProfile(c) {
+		return true
+	}
+	fi, err := os.Stat(ProfileRoot(p, profile))
+	return err == nil && fi.IsDir()
+}
+
+// ResolveProfile returns the profile for one command: override (a one-off --profile) when set,
+// else the configured active profile. It never changes the config.
+func ResolveProfile(p *Paths, c Config, override string) (ResolvedProfile, error) {
+	name := override
+	if name == "" {
+		name = ActiveProfile(c)
+	}
+	settings, err := LoadProfileSettings(p, name)
+	if err != nil {
+		return ResolvedProfile{Name: name}, err
+	}
+	return ResolvedProfile{Name: name, Settings: settings}, nil
+}
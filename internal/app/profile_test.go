@@ -16,3 +16,32 @@ func TestValidateProfileName(t *testing.T) {
 		}
 	}
 }
+
+func TestProfileSettings_RoundTripAndOverride(t *testing.T) {
+	p := PathsFromRoot(t.TempDir())
+	if s, err := LoadProfileSettings(p, "vr"); err != nil || s != (ProfileSettings{}) {
+		t.Fatalf("missing profile.json should load defaults, got %+v, %v", s, err)
+	}
+	want := ProfileSettings{Description: "VR setup", Target: "exp", DeployStrategy: DeployHardlink, NewModsDisabled: true}
+	if err := SaveProfileSettings(p, "vr", want); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveProfileSettings(p, "vr", ProfileSettings{DeployStrategy: "symlink"}); err == nil {
+		t.Fatal("expected invalid deploy strategy to be rejected")
+	}
+
+	cfg := Config{ActiveProfile: "default"}
+	rp, err := ResolveProfile(p, cfg, "vr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rp.Name != "vr" || rp.Settings != want {
+		t.Fatalf("override not resolved: %+v", rp)
+	}
+	if rp, _ := ResolveProfile(p, cfg, ""); rp.Name != "default" || rp.Settings != (ProfileSettings{}) {
+		t.Fatalf("expected configured default profile, got %+v", rp)
+	}
+	if cfg.ActiveProfile != "default" {
+		t.Fatal("ResolveProfile must not change the config")
+	}
+}
@@ -1,13 +1,16 @@
 package app
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"time"
 )
 
-const CurrentStateVersion = 3
+const CurrentStateVersion = 5
 
 // NexusInfo stores all metadata needed for version tracking and updates.
 type NexusInfo struct {
@@ -21,7 +24,11 @@ type NexusInfo struct {
 
 	Version string `json:"version,omitempty"`
 
-	CategoryName string `json:"category_name,omitempty"`
+	CategoryName string `json:"category_name,omitempty"` // the file's category (MAIN, OPTIONAL, ...)
+
+	// The mod's category on Nexus (e.g. "Visuals"), from the mod page and the game's category list.
+	ModCategoryID int    `json:"mod_category_id,omitempty"`
+	ModCategory   string `json:"mod_category,omitempty"`
 
 	UploadedTimestamp int64  `json:"uploaded_timestamp,omitempty"`
 	UploadedTime      string `json:"uploaded_time,omitempty"`
@@ -46,6 +53,10 @@ type ProfileInstall struct {
 }
 
 type ModEntry struct {
+	// Handle is a short number given when the entry is first saved and never reused; it is
+	// accepted wherever a mod id is (see AssignHandles).
+	Handle int `json:"handle,omitempty"`
+
 	URL          string `json:"url,omitempty"`
 	ZIP          string `json:"zip,omitempty"` // relative to Root (e.g. downloads/foo.zip)
 	DownloadedAt string `json:"downloaded_at,omitempty"`
@@ -57,64 +68,183 @@ type ModEntry struct {
 	Health string `json:"health,omitempty"` // "ok" | "warning"
 	SHA256 string `json:"sha256,omitempty"`
 
+	// Tags are user-defined labels, lower case (selectable with --tag).
+	Tags []string `json:"tags,omitempty"`
+	// Notes is free text the user keeps with the mod (nmsmods note).
+	Notes string `json:"notes,omitempty"`
+
 	// Per-profile install/enabled state.
 	Installations map[string]ProfileInstall `json:"installations,omitempty"`
 
-	// Legacy fields (v2 and earlier). Kept for backwards compatibility/migrations.
+	// legacy holds v2-and-earlier fields read from old state files. Only migrations use it;
+	// it is never written back.
+	legacy legacyModFields
+}
+
+// legacyModFields are the single-install fields ModEntry carried before per-profile
+// installations (state v3) and dropped in v4.
+type legacyModFields struct {
 	Folder        string `json:"folder,omitempty"`
 	Installed     bool   `json:"installed,omitempty"`
 	InstalledAt   string `json:"installed_at,omitempty"`
 	InstalledPath string `json:"installed_path,omitempty"`
 }
 
+// UnmarshalJSON reads a ModEntry and keeps any legacy fields aside for MigrateState.
+func (me *ModEntry) UnmarshalJSON(b []byte) error {
+	type plain ModEntry
+	var aux struct {
+		plain
+		legacyModFields
+	}
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+	*me = ModEntry(aux.plain)
+	me.legacy = aux.legacyModFields
+	return nil
+}
+
+// Category returns the mod's Nexus category, or "" when it is not known.
+func (me ModEntry) Category() string {
+	if me.Nexus == nil {
+		return ""
+	}
+	return me.Nexus.ModCategory
+}
+
 type State struct {
 	StateVersion int                 `json:"state_version,omitempty"`
+	NextHandle   int                 `json:"next_handle,omitempty"` // the handle AssignHandles gives next
 	Mods         map[string]ModEntry `json:"mods,omitempty"`
 }
 
 func NowRFC3339() string { return time.Now().Format(time.RFC3339) }
 
+// StateBackups is how many previous versions SaveState keeps next to state.json
+// (state.json.1 is the newest).
+const StateBackups = 5
+
+// StateBackupPath returns the path of the n-th rotated backup of a state file.
+func StateBackupPath(path string, n int) string { return fmt.Sprintf("%s.%d", path, n) }
+
+// StateRecovery describes a LoadState fallback to a backup because state.json was unreadable.
+type StateRecovery struct {
+	From    string // backup the state was restored from
+	Corrupt string // where the unreadable state.json was moved
+	Cause   error  // why state.json was rejected
+}
+
 func LoadState(path string) (State, error) {
-	b, err := os.ReadFile(path)
+	st, _, err := LoadStateRecover(path)
+	return st, err
+}
+
+// LoadStateRecover is LoadState that also reports a fallback to a backup. When state.json
+// is corrupt (e.g. truncated by a power loss), the newest valid backup is used, the broken
+// file is kept as state.json.corrupt-<time> and the recovered state is written back.
+func LoadStateRecover(path string) (State, *StateRecovery, error) {
+	st, err := readStateFile(path)
+	if os.IsNotExist(err) {
+		return State{
+			StateVersion: CurrentStateVersion,
+			Mods:         map[string]ModEntry{},
+		}, nil, nil
+	}
+	if err == nil {
+		if err := CheckStateVersion(st); err != nil {
+			return State{}, nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	var rec *StateRecovery
 	if err != nil {
-		if os.IsNotExist(err) {
-			return State{
-				StateVersion: CurrentStateVersion,
-				Mods:         map[string]ModEntry{},
-			}, nil
+		st, rec, err = recoverState(path, err)
+		if err != nil {
+			return State{}, nil, err
 		}
-		return State{}, err
 	}
 
+	st, migrated := MigrateState(st)
+	if migrated || rec != nil {
+		if err := SaveState(path, st); err != nil {
+			return State{}, nil, fmt.Errorf("save state (v%d): %w", st.StateVersion, err)
+		}
+	}
+
+	return st, rec, nil
+}
+
+func readStateFile(path string) (State, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return State{}, err
+	}
 	var st State
 	if err := json.Unmarshal(b, &st); err != nil {
-		return State{}, err
+		return State{}, fmt.Errorf("%s: %w", path, err)
 	}
+	return st, nil
+}
 
-	if st.Mods == nil {
-		st.Mods = map[string]ModEntry{}
+func recoverState(path string, cause error) (State, *StateRecovery, error) {
+	for n := 1; n <= StateBackups; n++ {
+		backup := StateBackupPath(path, n)
+		st, err := readStateFile(backup)
+		if err != nil || CheckStateVersion(st) != nil {
+			continue
+		}
+		corrupt := fmt.Sprintf("%s.corrupt-%s", path, time.Now().Format("20060102-150405"))
+		if err := os.Rename(path, corrupt); err != nil {
+			return State{}, nil, fmt.Errorf("%w (recovering from %s: %v)", cause, backup, err)
+		}
+		return st, &StateRecovery{From: backup, Corrupt: corrupt, Cause: cause}, nil
 	}
+	return State{}, nil, fmt.Errorf("%w (no valid backup in %s.1..%d)", cause, filepath.Base(path), StateBackups)
+}
 
-	if st.StateVersion == 0 {
-		st = migrateV0toV1(st)
-	}
+// stateMigrations[i] upgrades a state from version i to i+1.
+var stateMigrations = []func(State) State{
+	migrateV0toV1,
+	migrateV1toV2,
+	migrateV2toV3,
+	migrateV3toV4,
+	migrateV4toV5,
+}
 
-	if st.StateVersion == 1 {
-		st = migrateV1toV2(st)
-		_ = SaveState(path, st)
-	}
+// ErrStateTooNew is returned for state written by a newer nmsmods.
+type ErrStateTooNew struct {
+	Version int
+}
 
-	if st.StateVersion == 2 {
-		st = migrateV2toV3(st)
-		_ = SaveState(path, st)
+func (e *ErrStateTooNew) Error() string {
+	return fmt.Sprintf("state was written by a newer nmsmods (state v%d, this build supports up to v%d); upgrade nmsmods or restore an older backup", e.Version, CurrentStateVersion)
+}
+
+// CheckStateVersion refuses states this build cannot read without losing data.
+func CheckStateVersion(st State) error {
+	if st.StateVersion > CurrentStateVersion {
+		return &ErrStateTooNew{Version: st.StateVersion}
 	}
+	return nil
+}
 
+// MigrateState runs every migration newer than st.StateVersion, in order, and reports whether
+// anything changed. Store backends call it after reading so they share one upgrade path.
+func MigrateState(st State) (State, bool) {
+	if st.Mods == nil {
+		st.Mods = map[string]ModEntry{}
+	}
+	from := st.StateVersion
+	for v := st.StateVersion; v < len(stateMigrations); v++ {
+		st = stateMigrations[v](st)
+	}
 	if st.StateVersion < CurrentStateVersion {
 		st.StateVersion = CurrentStateVersion
-		_ = SaveState(path, st)
 	}
-
-	return st, nil
+	// Entries added by hand or by an older build writing a v5 file still need a handle.
+	assigned := AssignHandles(&st)
+	return st, st.StateVersion != from || assigned
 }
 
 func migrateV0toV1(st State) State {
@@ -135,7 +265,7 @@ func migrateV0toV1(st State) State {
 			me.DisplayName = id
 		}
 
-		if me.Health == "" && me.Installed {
+		if me.Health == "" && me.legacy.Installed {
 			me.Health = "ok"
 		}
 
@@ -170,13 +300,13 @@ func migrateV2toV3(st State) State {
 		if me.Installations == nil {
 			me.Installations = map[string]ProfileInstall{}
 		}
-		if me.Installed && me.Folder != "" {
+		if me.legacy.Installed && me.legacy.Folder != "" {
 			pi := me.Installations["default"]
 			pi.Installed = true
 			pi.Enabled = true
-			pi.Folder = me.Folder
-			pi.DeployedPath = me.InstalledPath
-			pi.InstalledAt = me.InstalledAt
+			pi.Folder = me.legacy.Folder
+			pi.DeployedPath = me.legacy.InstalledPath
+			pi.InstalledAt = me.legacy.InstalledAt
 			me.Installations["default"] = pi
 		}
 		st.Mods[id] = me
@@ -185,28 +315,76 @@ func migrateV2toV3(st State) State {
 	return st
 }
 
-// SaveState writes atomically (temp file + rename) to avoid partial/corrupted JSON.
-func SaveState(path string, st State) error {
+// v4 migration: drop the legacy single-install fields; Installations is the only record.
+func migrateV3toV4(st State) State {
+	st.StateVersion = 4
+
+	for id, me := range st.Mods {
+		me.legacy = legacyModFields{}
+		st.Mods[id] = me
+	}
+
+	return st
+}
+
+// v5 migration: give every entry a stable handle. Id order matches the positional indexes
+// earlier versions listed, so existing numbers keep pointing at the same mods.
+func migrateV4toV5(st State) State {
+	st.StateVersion = 5
+	AssignHandles(&st)
+	return st
+}
+
+// MarshalState renders st exactly as state.json stores it (also used for exports and revisions).
+func MarshalState(st State) ([]byte, error) {
 	if st.Mods == nil {
 		st.Mods = map[string]ModEntry{}
 	}
 	if st.StateVersion == 0 {
 		st.StateVersion = CurrentStateVersion
 	}
+	return json.MarshalIndent(st, "", "  ")
+}
 
-	b, err := json.MarshalIndent(st, "", "  ")
+// SaveState writes atomically (temp file + rename) to avoid partial/corrupted JSON.
+func SaveState(path string, st State) error {
+	AssignHandles(&st)
+	b, err := MarshalState(st)
 	if err != nil {
 		return err
 	}
-
-	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+	if err := rotateStateBackups(path, b); err != nil {
+		return fmt.Errorf("rotate state backups: %w", err)
+	}
+	// State may contain URLs and Nexus metadata; keep it user-private by default.
+	if err := WriteFileAtomic(path, b, 0o600); err != nil {
 		return err
 	}
+	slog.Info("state saved", "backend", "json", "path", path, "mods", len(st.Mods))
+	return nil
+}
 
-	tmp := path + ".tmp"
-	// State may contain URLs and Nexus metadata; keep it user-private by default.
-	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+// rotateStateBackups shifts state.json.1..N-1 up by one and copies the current state.json to
+// state.json.1. Saves that change nothing, and a state.json that is not valid JSON, leave the
+// backups untouched so a corrupt file never pushes a good backup out.
+func rotateStateBackups(path string, next []byte) error {
+	cur, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
 		return err
 	}
-	return os.Rename(tmp, path)
+	if bytes.Equal(cur, next) || !json.Valid(cur) {
+		return nil
+	}
+	if err := os.Remove(StateBackupPath(path, StateBackups)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for n := StateBackups - 1; n >= 1; n-- {
+		if err := os.Rename(StateBackupPath(path, n), StateBackupPath(path, n+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return WriteFileAtomic(StateBackupPath(path, 1), cur, 0o600)
 }
@@ -0,0 +1,145 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveState_RotatesBackupsAndLoadRecovers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	for i := 0; i < StateBackups+2; i++ {
+		st := State{Mods: map[string]ModEntry{"m": {DisplayName: string(rune('a' + i))}}}
+		if err := SaveState(path, st); err != nil {
+			t.Fatal(err)
+		}
+		// Saving the same state again must not rotate.
+		if err := SaveState(path, st); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := os.Stat(StateBackupPath(path, StateBackups+1)); !os.IsNotExist(err) {
+		t.Fatalf("kept more than %d backups", StateBackups)
+	}
+	newest, err := readStateFile(StateBackupPath(path, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := newest.Mods["m"].DisplayName; got != string(rune('a'+StateBackups)) {
+		t.Fatalf("state.json.1 holds %q", got)
+	}
+	if matches, _ := filepath.Glob(filepath.Join(filepath.Dir(path), ".state.json.tmp-*")); len(matches) != 0 {
+		t.Fatalf("temp files left behind: %v", matches)
+	}
+
+	// Simulate a torn write.
+	if err := os.WriteFile(path, []byte(`{"state_version": 3, "mo`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	st, rec, err := LoadStateRecover(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec == nil || rec.From != StateBackupPath(path, 1) {
+		t.Fatalf("recovery: %+v", rec)
+	}
+	if got := st.Mods["m"].DisplayName; got != string(rune('a'+StateBackups)) {
+		t.Fatalf("recovered %q", got)
+	}
+	if _, err := os.Stat(rec.Corrupt); err != nil {
+		t.Fatalf("corrupt copy: %v", err)
+	}
+	// state.json was rewritten, so the next load is clean.
+	if _, rec, err := LoadStateRecover(path); err != nil || rec != nil {
+		t.Fatalf("second load: %+v %v", rec, err)
+	}
+}
+
+func TestLoadState_CorruptWithoutBackupFails(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadState(path); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestLoadState_MigratesLegacyFieldsToV4(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	v2 := `{"state_version":2,"mods":{"m":{"source":"url","folder":"MyMod","installed":true,"installed_path":"/g/MODS/MyMod","installed_at":"2024-01-01T00:00:00Z"}}}`
+	if err := os.WriteFile(path, []byte(v2), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	st, err := LoadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.StateVersion != CurrentStateVersion {
+		t.Fatalf("version %d", st.StateVersion)
+	}
+	pi := st.Mods["m"].Installations["default"]
+	if !pi.Installed || !pi.Enabled || pi.Folder != "MyMod" || pi.DeployedPath != "/g/MODS/MyMod" || pi.InstalledAt == "" {
+		t.Fatalf("default installation: %+v", pi)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raw struct {
+		Mods map[string]map[string]any `json:"mods"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range []string{"folder", "installed", "installed_path", "installed_at"} {
+		if _, ok := raw.Mods["m"][k]; ok {
+			t.Fatalf("legacy field %q still written", k)
+		}
+	}
+}
+
+func TestLoadState_RefusesNewerVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := os.WriteFile(path, []byte(`{"state_version":99,"mods":{}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_, err := LoadState(path)
+	var tooNew *ErrStateTooNew
+	if !errors.As(err, &tooNew) || tooNew.Version != 99 {
+		t.Fatalf("expected ErrStateTooNew, got %v", err)
+	}
+}
+
+func TestLoadState_AssignsHandlesNeverReused(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	v4 := `{"state_version":4,"mods":{"b":{"source":"url"},"a":{"source":"url"}}}`
+	if err := os.WriteFile(path, []byte(v4), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	st, err := LoadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Migration numbers mods in the old positional order (sorted ids).
+	if st.Mods["a"].Handle != 1 || st.Mods["b"].Handle != 2 || st.NextHandle != 3 {
+		t.Fatalf("handles: %+v next=%d", st.Mods, st.NextHandle)
+	}
+
+	delete(st.Mods, "b")
+	st.Mods["c"] = ModEntry{Source: "local"}
+	if err := SaveState(path, st); err != nil {
+		t.Fatal(err)
+	}
+	if st, err = LoadState(path); err != nil {
+		t.Fatal(err)
+	}
+	if st.Mods["c"].Handle != 3 {
+		t.Fatalf("new mod reused a retired handle: %d", st.Mods["c"].Handle)
+	}
+	if id, ok := ModByHandle(st, 2); ok {
+		t.Fatalf("handle 2 resolves to %s after removal", id)
+	}
+}
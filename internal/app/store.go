@@ -0,0 +1,170 @@
+package app
+
+import (
+	"os"
+	"sort"
+	"strconv"
+)
+
+// State store backends.
+const (
+	BackendJSON = "json" // state.json, rewritten in full on every save
+	BackendBolt = "bolt" // state.db, an embedded bbolt database with per-entry records and indexes
+)
+
+// StateStore persists State. Save and Update are atomic: either every entry changes or none does.
+type StateStore interface {
+	Backend() string
+	Load() (State, error)
+	Save(st State) error
+	// Update runs fn on the current state and saves the result in one transaction.
+	Update(fn func(st *State) error) error
+
+	Get(id string) (ModEntry, bool, error)
+	// ByNexusModID returns ids of entries tracking a Nexus mod id.
+	ByNexusModID(modID int) ([]string, error)
+	// ByFolder returns ids installed into folder in a profile.
+	ByFolder(profile, folder string) ([]string, error)
+	// ByProfile returns ids installed in a profile.
+	ByProfile(profile string) ([]string, error)
+
+	Close() error
+}
+
+// StateBackend reports which backend OpenStateStore will use: bolt once state.db exists.
+func StateBackend(p *Paths) string {
+	if _, err := os.Stat(p.StateDB); err == nil {
+		return BackendBolt
+	}
+	return BackendJSON
+}
+
+// OpenStateStore opens the active state backend. Callers must Close it.
+func OpenStateStore(p *Paths) (StateStore, error) {
+	if StateBackend(p) == BackendBolt {
+		return OpenBoltStore(p.StateDB)
+	}
+	return &JSONStore{Path: p.State}, nil
+}
+
+// PeekState reads the state for read-only callers such as shell completion. It takes no lock,
+// migrates older states in memory only and gives up quickly on a state.db another process has
+// open for writing.
+func PeekState(p *Paths) (State, error) {
+	var (
+		st  State
+		err error
+	)
+	if StateBackend(p) == BackendBolt {
+		st, err = peekBoltState(p.StateDB)
+	} else {
+		st, err = readStateFile(p.State)
+		if os.IsNotExist(err) {
+			st, err = State{Mods: map[string]ModEntry{}}, nil
+		}
+	}
+	if err != nil {
+		return State{}, err
+	}
+	if err := CheckStateVersion(st); err != nil {
+		return State{}, err
+	}
+	st, _ = MigrateState(st)
+	return st, nil
+}
+
+// indexKeys lists the secondary index keys of an entry. Keys are "<value>\x00<id>" so a
+// prefix scan on "<value>\x00" finds every id.
+func indexKeys(id string, me ModEntry) (nexus, folder, profile []string) {
+	if me.Nexus != nil && me.Nexus.ModID != 0 {
+		nexus = append(nexus, strconv.Itoa(me.Nexus.ModID)+"\x00"+id)
+	}
+	for prof, pi := range me.Installations {
+		if !pi.Installed {
+			continue
+		}
+		profile = append(profile, prof+"\x00"+id)
+		if pi.Folder != "" {
+			folder = append(folder, prof+"\x00"+pi.Folder+"\x00"+id)
+		}
+	}
+	return nexus, folder, profile
+}
+
+// JSONStore is the original state.json backend. Lookups scan the whole state.
+type JSONStore struct {
+	Path string
+
+	// Recovered is set when Load had to fall back to a backup of a corrupt state.json.
+	Recovered *StateRecovery
+}
+
+func (s *JSONStore) Backend() string     { return BackendJSON }
+func (s *JSONStore) Save(st State) error { return SaveState(s.Path, st) }
+func (s *JSONStore) Close() error        { return nil }
+
+func (s *JSONStore) Load() (State, error) {
+	st, rec, err := LoadStateRecover(s.Path)
+	if rec != nil {
+		s.Recovered = rec
+	}
+	return st, err
+}
+func (s *JSONStore) Get(id string) (ModEntry, bool, error) {
+	st, err := s.Load()
+	if err != nil {
+		return ModEntry{}, false, err
+	}
+	me, ok := st.Mods[id]
+	return me, ok, nil
+}
+
+func (s *JSONStore) Update(fn func(st *State) error) error {
+	st, err := s.Load()
+	if err != nil {
+		return err
+	}
+	if err := fn(&st); err != nil {
+		return err
+	}
+	return s.Save(st)
+}
+
+func (s *JSONStore) scan(match func(nexus, folder, profile []string) bool) ([]string, error) {
+	st, err := s.Load()
+	if err != nil {
+		return nil, err
+	}
+	out := []string{}
+	for id, me := range st.Mods {
+		if match(indexKeys(id, me)) {
+			out = append(out, id)
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+func (s *JSONStore) ByNexusModID(modID int) ([]string, error) {
+	want := strconv.Itoa(modID) + "\x00"
+	return s.scan(func(nexus, _, _ []string) bool { return hasKeyPrefix(nexus, want) })
+}
+
+func (s *JSONStore) ByFolder(profile, folder string) ([]string, error) {
+	want := profile + "\x00" + folder + "\x00"
+	return s.scan(func(_, folders, _ []string) bool { return hasKeyPrefix(folders, want) })
+}
+
+func (s *JSONStore) ByProfile(profile string) ([]string, error) {
+	want := profile + "\x00"
+	return s.scan(func(_, _, profiles []string) bool { return hasKeyPrefix(profiles, want) })
+}
+
+func hasKeyPrefix(keys []string, prefix string) bool {
+	for _, k := range keys {
+		if len(k) >= len(prefix) && k[:len(prefix)] == prefix {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+)
+
+// DefaultTargetName names the game target backed by Config.GamePath.
+const DefaultTargetName = "default"
+
+// GameTarget is a named No Man's Sky installation (e.g. the Steam build and an
+// experimental-branch copy in another library).
+type GameTarget struct {
+	Path string `json:"path"`
+}
+
+// ValidateTargetName applies the profile naming rules to target names.
+func ValidateTargetName(name string) error {
+	if !profileNameRe.MatchString(name) {
+		return fmt.Errorf("invalid target name %q (allowed: letters, digits, ., _, -, max 64 chars)", name)
+	}
+	return nil
+}
+
+// TargetPath returns the game path configured for a target.
+func TargetPath(c Config, name string) (string, bool) {
+	if name == DefaultTargetName {
+		if t, ok := c.Targets[name]; ok {
+			return t.Path, t.Path != ""
+		}
+		return c.GamePath, c.GamePath != ""
+	}
+	t, ok := c.Targets[name]
+	return t.Path, ok && t.Path != ""
+}
+
+// TargetNames lists configured targets, "default" first when game_path is set.
+func TargetNames(c Config) []string {
+	var out []string
+	for name := range c.Targets {
+		if name != DefaultTargetName {
+			out = append(out, name)
+		}
+	}
+	sort.Strings(out)
+	if _, ok := TargetPath(c, DefaultTargetName); ok {
+		out = append([]string{DefaultTargetName}, out...)
+	}
+	return out
+}
+
+// ResolveTarget picks the game target for an operation: an explicit override (--game-target),
+// then the profile's binding (ProfileSettings.Target), then default_target, then the game_path
+// target.
+func ResolveTarget(c Config, binding, override string) (string, error) {
+	name := override
+	if name == "" {
+		name = binding
+	}
+	if name == "" {
+		name = c.DefaultTarget
+	}
+	if name == "" {
+		name = DefaultTargetName
+	}
+	if _, ok := TargetPath(c, name); !ok {
+		if name == DefaultTargetName {
+			return name, fmt.Errorf("game path not set. Use: nmsmods set-path <path> (or run: nmsmods where)")
+		}
+		return name, fmt.Errorf("game target %q is not configured (run: nmsmods target list)", name)
+	}
+	return name, nil
+}
@@ -0,0 +1,38 @@
+package app
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResolveTarget_Precedence(t *testing.T) {
+	c := Config{
+		GamePath: "/games/steam",
+		Targets:  map[string]GameTarget{"exp": {Path: "/games/exp"}, "gog": {Path: "/games/gog"}},
+	}
+	cases := []struct {
+		binding, override, want string
+	}{
+		{"", "", DefaultTargetName},
+		{"exp", "", "exp"},
+		{"exp", "gog", "gog"},
+		{"exp", DefaultTargetName, DefaultTargetName},
+	}
+	for _, tc := range cases {
+		got, err := ResolveTarget(c, tc.binding, tc.override)
+		if err != nil || got != tc.want {
+			t.Fatalf("ResolveTarget(%q, %q) = %q, %v; want %q", tc.binding, tc.override, got, err, tc.want)
+		}
+	}
+
+	c.DefaultTarget = "gog"
+	if got, _ := ResolveTarget(c, "", ""); got != "gog" {
+		t.Fatalf("default_target ignored: %q", got)
+	}
+	if _, err := ResolveTarget(c, "", "nope"); err == nil {
+		t.Fatal("expected an error for an unknown target")
+	}
+	if got := TargetNames(c); !reflect.DeepEqual(got, []string{DefaultTargetName, "exp", "gog"}) {
+		t.Fatalf("TargetNames: %v", got)
+	}
+}
@@ -0,0 +1,47 @@
+package app
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// APITokenPath is the default token file for `nmsmods serve`.
+func APITokenPath(p *Paths) string {
+	return filepath.Join(p.Root, "api-token")
+}
+
+// LoadOrCreateAPIToken reads the bearer token from path, creating a random one (mode 0600)
+// when the file does not exist. A token file readable by other users is refused.
+func LoadOrCreateAPIToken(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if err == nil {
+		fi, serr := os.Stat(path)
+		if serr != nil {
+			return "", serr
+		}
+		if fi.Mode().Perm()&0o077 != 0 {
+			return "", fmt.Errorf("token file %s is accessible by other users (run: chmod 600 %s)", path, path)
+		}
+		tok := strings.TrimSpace(string(b))
+		if tok == "" {
+			return "", fmt.Errorf("token file %s is empty", path)
+		}
+		return tok, nil
+	}
+	if !os.IsNotExist(err) {
+		return "", err
+	}
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	tok := hex.EncodeToString(raw)
+	if err := WriteFileAtomic(path, []byte(tok+"\n"), 0o600); err != nil {
+		return "", err
+	}
+	return tok, nil
+}
@@ -0,0 +1,28 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadOrCreateAPIToken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api-token")
+	tok, err := LoadOrCreateAPIToken(path)
+	if err != nil || len(tok) != 64 {
+		t.Fatalf("expected a new 64-char token, got %q, %v", tok, err)
+	}
+	if fi, err := os.Stat(path); err != nil || fi.Mode().Perm() != 0o600 {
+		t.Fatalf("token file must be 0600: %v %v", fi.Mode(), err)
+	}
+	again, err := LoadOrCreateAPIToken(path)
+	if err != nil || again != tok {
+		t.Fatalf("expected the stored token back, got %q, %v", again, err)
+	}
+	if err := os.Chmod(path, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadOrCreateAPIToken(path); err == nil {
+		t.Fatal("expected a world-readable token file to be refused")
+	}
+}
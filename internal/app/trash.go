@@ -0,0 +1,388 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// Trash item kinds.
+const (
+	TrashStore    = "store"    // profile store folder
+	TrashDownload = "download" // downloaded archive
+	TrashState    = "state"    // state.json
+	TrashHome     = "home"     // whole nmsmods home (reset --all)
+	TrashOther    = "other"
+)
+
+const trashMetaFile = ".trash.json"
+
+// rename moves paths into the trash; tests replace it to simulate failures.
+var rename = os.Rename
+
+// DefaultTrashRetentionDays applies when TrashConfig.RetentionDays is 0.
+const DefaultTrashRetentionDays = 30
+
+// TrashItem describes one deleted path kept under <root>/trash/<id>/.
+type TrashItem struct {
+	ID      string `json:"id"`
+	Kind    string `json:"kind"`
+	Origin  string `json:"origin"` // absolute path the payload came from
+	Name    string `json:"name"`   // payload name inside the item directory
+	ModID   string `json:"mod_id,omitempty"`
+	Time    string `json:"time"`
+	Command string `json:"command,omitempty"`
+	Size    int64  `json:"size"`
+}
+
+// TrashItemDir returns the directory holding an item's metadata and payload.
+func TrashItemDir(p *Paths, id string) string { return filepath.Join(TrashDir(p), id) }
+
+// PayloadPath returns where the trashed file or folder currently lives.
+func (it TrashItem) PayloadPath(p *Paths) string {
+	return filepath.Join(TrashItemDir(p, it.ID), it.Name)
+}
+
+// MoveToTrash renames origin into a new trash item and records its metadata.
+// For TrashHome, every entry of origin except the trash itself and the lock file is moved.
+func MoveToTrash(p *Paths, origin string, meta TrashItem) (TrashItem, error) {
+	meta.ID = strconv.FormatInt(time.Now().UnixNano(), 10)
+	meta.Name = filepath.Base(filepath.Clean(origin))
+	return moveToTrash(p, origin, meta)
+}
+
+// ReturnToTrash moves origin to payload, the PayloadPath of an item restored earlier, and
+// recreates that item. Redo uses it so the trash paths recorded in history stay valid.
+func ReturnToTrash(p *Paths, origin, payload string, meta TrashItem) (TrashItem, error) {
+	dir, err := trashItemDirOf(p, payload)
+	if err != nil {
+		return meta, err
+	}
+	if _, err := os.Stat(payload); err == nil {
+		return meta, fmt.Errorf("refusing to overwrite %s in the trash", payload)
+	}
+	meta.ID = filepath.Base(dir)
+	meta.Name = filepath.Base(payload)
+	return moveToTrash(p, origin, meta)
+}
+
+// RestoreTrashAt moves the payload of a trash item (a path PayloadPath returned) to dest and
+// removes the item. Unlike RestoreTrash, dest need not be the recorded origin: history records
+// paths relative to the data directory.
+func RestoreTrashAt(p *Paths, payload, dest string) error {
+	dir, err := trashItemDirOf(p, payload)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(dest); err == nil {
+		return fmt.Errorf("refusing to overwrite %s (move it away first)", dest)
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return err
+	}
+	if err := moveAcross(payload, dest); err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
+
+// trashItemDirOf returns the item directory holding payload, which must sit directly in one.
+func trashItemDirOf(p *Paths, payload string) (string, error) {
+	dir := filepath.Dir(filepath.Clean(payload))
+	if filepath.Dir(dir) != TrashDir(p) {
+		return "", fmt.Errorf("%s is not a trash item payload", payload)
+	}
+	return dir, nil
+}
+
+// moveToTrash moves origin into the item meta.ID as meta.Name and writes the metadata.
+func moveToTrash(p *Paths, origin string, meta TrashItem) (TrashItem, error) {
+	origin = filepath.Clean(origin)
+	meta.Origin = origin
+	if meta.Kind == "" {
+		meta.Kind = TrashOther
+	}
+	meta.Time = NowRFC3339()
+	meta.Size = pathSize(origin)
+
+	dir := TrashItemDir(p, meta.ID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return meta, err
+	}
+
+	if meta.Kind == TrashHome {
+		payload := meta.PayloadPath(p)
+		if err := os.MkdirAll(payload, 0o755); err != nil {
+			return meta, err
+		}
+		ents, err := os.ReadDir(origin)
+		if err != nil {
+			return meta, err
+		}
+		trashRoot := TrashDir(p)
+		var moved []string
+		for _, e := range ents {
+			src := filepath.Join(origin, e.Name())
+			if src == trashRoot || e.Name() == "lock" {
+				continue
+			}
+			if err := rename(src, filepath.Join(payload, e.Name())); err != nil {
+				// Put back what already moved: a half-moved home has no item to restore it from.
+				for _, name := range moved {
+					_ = os.Rename(filepath.Join(payload, name), filepath.Join(origin, name))
+				}
+				_ = os.RemoveAll(dir)
+				return meta, err
+			}
+			moved = append(moved, e.Name())
+		}
+	} else if err := moveAcross(origin, meta.PayloadPath(p)); err != nil {
+		_ = os.RemoveAll(dir)
+		return meta, err
+	}
+
+	slog.Info("moved to trash", "origin", origin, "kind", meta.Kind, "mod", meta.ModID, "trash_id", meta.ID)
+	b, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return meta, err
+	}
+	return meta, os.WriteFile(filepath.Join(dir, trashMetaFile), b, 0o600)
+}
+
+// ListTrash returns trash items whose payload still exists, oldest first.
+// Directories without metadata (created before metadata existed) are reported as kind "other".
+func ListTrash(p *Paths) ([]TrashItem, error) {
+	ents, err := os.ReadDir(TrashDir(p))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []TrashItem{}, nil
+		}
+		return nil, err
+	}
+	out := []TrashItem{}
+	for _, e := range ents {
+		if !e.IsDir() {
+			continue
+		}
+		it, ok := readTrashItem(p, e.Name())
+		if !ok {
+			continue
+		}
+		out = append(out, it)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+func readTrashItem(p *Paths, id string) (TrashItem, bool) {
+	dir := TrashItemDir(p, id)
+	var it TrashItem
+	if b, err := os.ReadFile(filepath.Join(dir, trashMetaFile)); err == nil && json.Unmarshal(b, &it) == nil {
+		it.ID = id
+		if _, err := os.Stat(it.PayloadPath(p)); err != nil {
+			return it, false
+		}
+		return it, true
+	}
+	ents, err := os.ReadDir(dir)
+	if err != nil || len(ents) != 1 {
+		return it, false
+	}
+	it = TrashItem{ID: id, Kind: TrashOther, Name: ents[0].Name(), Size: pathSize(filepath.Join(dir, ents[0].Name()))}
+	if info, err := ents[0].Info(); err == nil {
+		it.Time = info.ModTime().Format(time.RFC3339)
+	}
+	return it, true
+}
+
+// RestoreTrash moves an item back to its origin. It refuses to overwrite existing paths.
+func RestoreTrash(p *Paths, id string) (TrashItem, error) {
+	it, ok := readTrashItem(p, id)
+	if !ok {
+		return it, fmt.Errorf("trash item not found: %s", id)
+	}
+	if it.Origin == "" {
+		return it, fmt.Errorf("trash item %s has no recorded origin", id)
+	}
+	payload := it.PayloadPath(p)
+
+	if it.Kind == TrashHome {
+		ents, err := os.ReadDir(payload)
+		if err != nil {
+			return it, err
+		}
+		for _, e := range ents {
+			if _, err := os.Stat(filepath.Join(it.Origin, e.Name())); err == nil && !isEmptyDir(filepath.Join(it.Origin, e.Name())) {
+				return it, fmt.Errorf("refusing to overwrite %s (move it away first)", filepath.Join(it.Origin, e.Name()))
+			}
+		}
+		for _, e := range ents {
+			dst := filepath.Join(it.Origin, e.Name())
+			_ = os.Remove(dst) // empty directory recreated by Paths.Ensure
+			if err := moveAcross(filepath.Join(payload, e.Name()), dst); err != nil {
+				return it, err
+			}
+		}
+		return it, os.RemoveAll(TrashItemDir(p, id))
+	}
+
+	if _, err := os.Stat(it.Origin); err == nil {
+		return it, fmt.Errorf("refusing to overwrite %s (move it away first)", it.Origin)
+	}
+	if err := os.MkdirAll(filepath.Dir(it.Origin), 0o755); err != nil {
+		return it, err
+	}
+	if err := moveAcross(payload, it.Origin); err != nil {
+		return it, err
+	}
+	return it, os.RemoveAll(TrashItemDir(p, id))
+}
+
+// EmptyTrash permanently deletes items older than olderThan (0 = all) and returns how many.
+func EmptyTrash(p *Paths, olderThan time.Duration, now time.Time) (int, error) {
+	items, err := ListTrash(p)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, it := range items {
+		if olderThan > 0 && !trashedBefore(it, now.Add(-olderThan)) {
+			continue
+		}
+		if err := os.RemoveAll(TrashItemDir(p, it.ID)); err != nil {
+			return n, err
+		}
+		slog.Info("deleted trash item", "trash_id", it.ID, "kind", it.Kind, "origin", it.Origin)
+		n++
+	}
+	removeStaleTrashDirs(p)
+	return n, nil
+}
+
+// PruneTrash applies the retention settings: items older than RetentionDays are deleted, then
+// the oldest items are deleted until the trash fits in MaxSizeMB. The newest item is always
+// kept, so the operation that just trashed it can still be undone.
+func PruneTrash(p *Paths, tc TrashConfig, now time.Time) (int, error) {
+	days := tc.RetentionDays
+	if days == 0 {
+		days = DefaultTrashRetentionDays
+	}
+	n := 0
+	if days > 0 {
+		removed, err := EmptyTrash(p, time.Duration(days)*24*time.Hour, now)
+		if err != nil {
+			return removed, err
+		}
+		n += removed
+	}
+	if tc.MaxSizeMB <= 0 {
+		return n, nil
+	}
+	items, err := ListTrash(p)
+	if err != nil {
+		return n, err
+	}
+	var total int64
+	for _, it := range items {
+		total += it.Size
+	}
+	limit := int64(tc.MaxSizeMB) * 1024 * 1024
+	for i, it := range items {
+		if total <= limit || i == len(items)-1 {
+			break
+		}
+		if err := os.RemoveAll(TrashItemDir(p, it.ID)); err != nil {
+			return n, err
+		}
+		slog.Info("pruned trash item over max_size_mb", "trash_id", it.ID, "kind", it.Kind, "origin", it.Origin, "size", it.Size)
+		total -= it.Size
+		n++
+	}
+	return n, nil
+}
+
+func trashedBefore(it TrashItem, cutoff time.Time) bool {
+	t, err := time.Parse(time.RFC3339, it.Time)
+	if err != nil {
+		return false
+	}
+	return t.Before(cutoff)
+}
+
+// removeStaleTrashDirs drops item directories whose payload was restored elsewhere (e.g. by undo).
+func removeStaleTrashDirs(p *Paths) {
+	ents, err := os.ReadDir(TrashDir(p))
+	if err != nil {
+		return
+	}
+	for _, e := range ents {
+		if !e.IsDir() {
+			continue
+		}
+		if _, ok := readTrashItem(p, e.Name()); !ok {
+			_ = os.RemoveAll(TrashItemDir(p, e.Name()))
+		}
+	}
+}
+
+// moveAcross renames src to dst, copying then removing src when they are on different
+// filesystems (a game folder on another drive than the data directory).
+func moveAcross(src, dst string) error {
+	err := rename(src, dst)
+	if !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+	err = filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		switch {
+		case info.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm()|0o700)
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, b, info.Mode().Perm())
+	})
+	if err != nil {
+		_ = os.RemoveAll(dst)
+		return err
+	}
+	return os.RemoveAll(src)
+}
+
+func pathSize(path string) int64 {
+	var n int64
+	_ = filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			n += info.Size()
+		}
+		return nil
+	})
+	return n
+}
+
+func isEmptyDir(path string) bool {
+	ents, err := os.ReadDir(path)
+	return err == nil && len(ents) == 0
+}
@@ -0,0 +1,251 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestTrash_MoveListRestoreEmpty(t *testing.T) {
+	p := PathsFromRoot(t.TempDir())
+	if err := p.Ensure(); err != nil {
+		t.Fatal(err)
+	}
+	zip := filepath.Join(p.Downloads, "a.zip")
+	if err := os.WriteFile(zip, []byte("zip"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	it, err := MoveToTrash(p, zip, TrashItem{Kind: TrashDownload, ModID: "a", Command: "nmsmods rm-download a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(zip); !os.IsNotExist(err) {
+		t.Fatalf("origin still exists")
+	}
+	items, err := ListTrash(p)
+	if err != nil || len(items) != 1 || items[0].Origin != zip || items[0].ModID != "a" || items[0].Size != 3 {
+		t.Fatalf("list: %+v %v", items, err)
+	}
+
+	if _, err := RestoreTrash(p, it.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(zip); err != nil {
+		t.Fatalf("not restored: %v", err)
+	}
+
+	if _, err := MoveToTrash(p, zip, TrashItem{Kind: TrashDownload}); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := EmptyTrash(p, time.Hour, time.Now()); n != 0 {
+		t.Fatalf("fresh item should survive --older-than, removed %d", n)
+	}
+	if n, _ := EmptyTrash(p, 0, time.Now()); n != 1 {
+		t.Fatalf("expected 1 removed, got %d", n)
+	}
+}
+
+func TestTrash_HomeKeepsTrashAndLock(t *testing.T) {
+	p := PathsFromRoot(t.TempDir())
+	if err := p.Ensure(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p.State, []byte("{}"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_ = os.WriteFile(filepath.Join(p.Root, "lock"), nil, 0o644)
+
+	it, err := MoveToTrash(p, p.Root, TrashItem{Kind: TrashHome})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(p.State); !os.IsNotExist(err) {
+		t.Fatalf("state.json should be in the trash")
+	}
+	if _, err := os.Stat(filepath.Join(p.Root, "lock")); err != nil {
+		t.Fatalf("lock must stay: %v", err)
+	}
+
+	_ = p.Ensure()
+	if _, err := RestoreTrash(p, it.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(p.State); err != nil {
+		t.Fatalf("state.json not restored: %v", err)
+	}
+}
+
+func TestTrash_RestoreAtAndReturnKeepPayloadPath(t *testing.T) {
+	p := PathsFromRoot(t.TempDir())
+	if err := p.Ensure(); err != nil {
+		t.Fatal(err)
+	}
+	store := filepath.Join(p.Root, "profiles", "default", "mods", "Foo")
+	if err := os.MkdirAll(store, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	it, err := MoveToTrash(p, store, TrashItem{Kind: TrashStore, ModID: "foo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := it.PayloadPath(p)
+
+	// Undo: the payload goes back and the item disappears.
+	if err := RestoreTrashAt(p, payload, store); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(TrashItemDir(p, it.ID)); !os.IsNotExist(err) {
+		t.Fatalf("item directory left behind: %v", err)
+	}
+	// Redo: the store returns to the same payload path, as a listed item.
+	back, err := ReturnToTrash(p, store, payload, TrashItem{Kind: TrashStore, ModID: "foo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if back.ID != it.ID || back.PayloadPath(p) != payload {
+		t.Fatalf("returned to %s, want %s", back.PayloadPath(p), payload)
+	}
+	if items, _ := ListTrash(p); len(items) != 1 || items[0].Origin != store {
+		t.Fatalf("list: %+v", items)
+	}
+	if err := RestoreTrashAt(p, store, payload); err == nil {
+		t.Fatal("RestoreTrashAt accepted a path outside the trash")
+	}
+}
+
+func TestTrash_HomeMoveFailingPartwayPutsEntriesBack(t *testing.T) {
+	p := PathsFromRoot(t.TempDir())
+	if err := p.Ensure(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p.State, []byte("{}"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { rename = os.Rename }()
+	calls := 0
+	rename = func(from, to string) error {
+		if calls++; calls == 2 {
+			return os.ErrPermission
+		}
+		return os.Rename(from, to)
+	}
+
+	if _, err := MoveToTrash(p, p.Root, TrashItem{Kind: TrashHome}); err == nil {
+		t.Fatal("expected the injected failure")
+	}
+	if calls < 2 {
+		t.Fatalf("home has too few entries to fail partway (%d renames)", calls)
+	}
+	if _, err := os.Stat(p.State); err != nil {
+		t.Fatalf("state.json not put back: %v", err)
+	}
+	if _, err := os.Stat(p.Downloads); err != nil {
+		t.Fatalf("downloads/ not put back: %v", err)
+	}
+	if items, _ := ListTrash(p); len(items) != 0 {
+		t.Fatalf("half-moved item left in the trash: %+v", items)
+	}
+}
+
+func TestTrash_CopiesAcrossFilesystems(t *testing.T) {
+	p := PathsFromRoot(t.TempDir())
+	if err := p.Ensure(); err != nil {
+		t.Fatal(err)
+	}
+	folder := filepath.Join(t.TempDir(), "GAMEDATA", "MODS", "manual")
+	if err := os.MkdirAll(filepath.Join(folder, "METADATA"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(folder, "METADATA", "A.MBIN"), []byte("mbin"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { rename = os.Rename }()
+	rename = func(string, string) error { return &os.LinkError{Op: "rename", Err: syscall.EXDEV} }
+
+	it, err := MoveToTrash(p, folder, TrashItem{Kind: TrashOther})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(folder); !os.IsNotExist(err) {
+		t.Fatalf("origin still exists: %v", err)
+	}
+	if b, err := os.ReadFile(filepath.Join(it.PayloadPath(p), "METADATA", "A.MBIN")); err != nil || string(b) != "mbin" {
+		t.Fatalf("payload not copied: %q %v", b, err)
+	}
+}
+
+func TestTrash_RestoresAcrossFilesystems(t *testing.T) {
+	p := PathsFromRoot(t.TempDir())
+	if err := p.Ensure(); err != nil {
+		t.Fatal(err)
+	}
+	folder := filepath.Join(t.TempDir(), "GAMEDATA", "MODS", "manual")
+	if err := os.MkdirAll(folder, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(folder, "A.MBIN"), []byte("mbin"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { rename = os.Rename }()
+	rename = func(string, string) error { return &os.LinkError{Op: "rename", Err: syscall.EXDEV} }
+
+	it, err := MoveToTrash(p, folder, TrashItem{Kind: TrashOther})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RestoreTrash(p, it.ID); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := os.ReadFile(filepath.Join(folder, "A.MBIN")); err != nil || string(b) != "mbin" {
+		t.Fatalf("not restored: %q %v", b, err)
+	}
+
+	it, err = MoveToTrash(p, folder, TrashItem{Kind: TrashOther})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := RestoreTrashAt(p, it.PayloadPath(p), folder); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(folder, "A.MBIN")); err != nil {
+		t.Fatalf("not restored at dest: %v", err)
+	}
+	if items, _ := ListTrash(p); len(items) != 0 {
+		t.Fatalf("restored items left in the trash: %+v", items)
+	}
+}
+
+func TestPruneTrash_KeepsNewestItem(t *testing.T) {
+	p := PathsFromRoot(t.TempDir())
+	if err := p.Ensure(); err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, name := range []string{"a.zip", "b.zip"} {
+		zip := filepath.Join(p.Downloads, name)
+		if err := os.WriteFile(zip, make([]byte, 768*1024), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		it, err := MoveToTrash(p, zip, TrashItem{Kind: TrashDownload})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, it.ID)
+	}
+
+	// 1.5 MiB over a 1 MiB limit: the older item goes; the newer one stays although it alone
+	// still does not fit.
+	tc := TrashConfig{MaxSizeMB: 1}
+	if n, err := PruneTrash(p, tc, time.Now()); err != nil || n != 1 {
+		t.Fatalf("prune: %d, %v", n, err)
+	}
+	if items, _ := ListTrash(p); len(items) != 1 || items[0].ID != ids[1] {
+		t.Fatalf("left: %+v", items)
+	}
+	if n, err := PruneTrash(p, tc, time.Now()); err != nil || n != 0 {
+		t.Fatalf("newest item pruned: %d, %v", n, err)
+	}
+}
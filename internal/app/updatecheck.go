@@ -0,0 +1,67 @@
+package app
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// UpdateCheckPath is where `nmsmods nexus check-updates` keeps its last results.
+func UpdateCheckPath(p *Paths) string { return filepath.Join(p.Root, "update-check.json") }
+
+// UpdateCheck is the last Nexus update check per mod. It is a cache: losing it only means
+// --outdated selects nothing until the next check.
+type UpdateCheck struct {
+	Mods map[string]UpdateCheckEntry `json:"mods"`
+}
+
+// UpdateCheckEntry is one mod's result.
+type UpdateCheckEntry struct {
+	CheckedAt     string `json:"checked_at"`
+	HasUpdate     bool   `json:"has_update"`
+	CurrentFileID int    `json:"current_file_id,omitempty"`
+	LatestFileID  int    `json:"latest_file_id,omitempty"`
+	LatestVersion string `json:"latest_version,omitempty"`
+	Reason        string `json:"reason,omitempty"`
+}
+
+// LoadUpdateCheck reads update-check.json (missing = empty).
+func LoadUpdateCheck(p *Paths) (UpdateCheck, error) {
+	uc := UpdateCheck{Mods: map[string]UpdateCheckEntry{}}
+	b, err := os.ReadFile(UpdateCheckPath(p))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return uc, nil
+		}
+		return uc, err
+	}
+	if err := json.Unmarshal(b, &uc); err != nil {
+		return uc, err
+	}
+	if uc.Mods == nil {
+		uc.Mods = map[string]UpdateCheckEntry{}
+	}
+	return uc, nil
+}
+
+// SaveUpdateCheck writes update-check.json atomically.
+func SaveUpdateCheck(p *Paths, uc UpdateCheck) error {
+	b, err := json.MarshalIndent(uc, "", "  ")
+	if err != nil {
+		return err
+	}
+	return WriteFileAtomic(UpdateCheckPath(p), b, 0o600)
+}
+
+// Outdated reports whether the last check found a newer file for id that is still newer than
+// the installed one (a later download of that file clears it).
+func (uc UpdateCheck) Outdated(id string, me ModEntry) bool {
+	e, ok := uc.Mods[id]
+	if !ok || !e.HasUpdate {
+		return false
+	}
+	if me.Nexus != nil && e.LatestFileID != 0 && me.Nexus.FileID == e.LatestFileID {
+		return false
+	}
+	return true
+}
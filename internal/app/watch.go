@@ -0,0 +1,52 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// WatchConfig configures `nmsmods watch`.
+type WatchConfig struct {
+	// Dir is watched when no directory is given (empty = the XDG download directory).
+	Dir string `json:"dir,omitempty"`
+	// Rules are tried in order; the first whose glob matches an archive's name applies.
+	Rules []WatchRule `json:"rules,omitempty"`
+}
+
+// WatchRule maps archive names to a mod id and says whether to install them.
+type WatchRule struct {
+	// Glob is matched against the file name (not the path), case-insensitively.
+	Glob string `json:"glob"`
+	// ID is the mod id to import as (empty = derived from the file name).
+	ID string `json:"id,omitempty"`
+	// Install installs the archive into the profile after importing it.
+	Install bool `json:"install,omitempty"`
+	// Disabled installs without enabling (nothing is deployed). Only used with Install.
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// ValidateWatchGlob checks a rule glob (filepath.Match syntax, no directories).
+func ValidateWatchGlob(glob string) error {
+	if strings.TrimSpace(glob) == "" {
+		return fmt.Errorf("empty glob")
+	}
+	if strings.ContainsRune(glob, '/') {
+		return fmt.Errorf("glob %q must match a file name, not a path", glob)
+	}
+	if _, err := filepath.Match(glob, ""); err != nil {
+		return fmt.Errorf("invalid glob %q: %w", glob, err)
+	}
+	return nil
+}
+
+// Match returns the first rule whose glob matches name.
+func (w WatchConfig) Match(name string) (WatchRule, bool) {
+	lower := strings.ToLower(name)
+	for _, r := range w.Rules {
+		if ok, _ := filepath.Match(strings.ToLower(r.Glob), lower); ok {
+			return r, true
+		}
+	}
+	return WatchRule{}, false
+}
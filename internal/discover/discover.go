@@ -0,0 +1,93 @@
+// Package discover locates No Man's Sky installations across Linux stores and launchers.
+//
+// Each launcher is a Provider; results are labelled with the provider's source so the CLI can
+// show where an install came from and let users pick between several.
+package discover
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Candidate is a directory that looks like a No Man's Sky install.
+// Candidates are not validated; callers should run nms.ValidateGamePath.
+type Candidate struct {
+	Path   string `json:"path"`
+	Source string `json:"source"` // steam, steam-flatpak, steam-snap, heroic-gog, lutris, bottles
+}
+
+// Provider finds candidates for one store or launcher below a home directory.
+type Provider interface {
+	Name() string
+	Find(home string) []Candidate
+}
+
+// DefaultProviders returns every built-in provider in preference order (Steam first).
+func DefaultProviders() []Provider {
+	return []Provider{
+		steamProvider{},
+		heroicProvider{},
+		lutrisProvider{},
+		bottlesProvider{},
+	}
+}
+
+// Find runs providers against home and returns candidates de-duplicated by path.
+// When two providers report the same directory, the first label wins.
+func Find(home string, providers ...Provider) []Candidate {
+	if len(providers) == 0 {
+		providers = DefaultProviders()
+	}
+	out := []Candidate{}
+	seen := map[string]struct{}{}
+	for _, pr := range providers {
+		for _, c := range pr.Find(home) {
+			c.Path = filepath.Clean(c.Path)
+			key := c.Path
+			if r, err := filepath.EvalSymlinks(c.Path); err == nil {
+				key = r
+			}
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// FindAll is Find for the current user's home directory.
+func FindAll() ([]Candidate, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	return Find(home), nil
+}
+
+// Sources returns the labels of the built-in providers' results (for help text and validation).
+func Sources() []string {
+	return []string{"steam", "steam-flatpak", "steam-snap", "heroic-gog", "lutris", "bottles"}
+}
+
+// gameRootFromExe maps .../No Man's Sky/Binaries/NMS.exe to .../No Man's Sky.
+func gameRootFromExe(exe string) string {
+	exe = filepath.Clean(filepath.FromSlash(strings.ReplaceAll(exe, "\\", "/")))
+	dir := filepath.Dir(exe)
+	if strings.EqualFold(filepath.Base(dir), "Binaries") {
+		return filepath.Dir(dir)
+	}
+	return dir
+}
+
+func isDir(p string) bool {
+	st, err := os.Stat(p)
+	return err == nil && st.IsDir()
+}
+
+// looksLikeNMS is a cheap pre-filter: the directory has a GAMEDATA folder.
+func looksLikeNMS(p string) bool {
+	return isDir(filepath.Join(p, "GAMEDATA"))
+}
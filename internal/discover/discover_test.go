@@ -0,0 +1,75 @@
+package discover
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func mkGame(t *testing.T, p string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(p, "GAMEDATA"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func write(t *testing.T, p, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFind_LabelsEachLauncher(t *testing.T) {
+	home := t.TempDir()
+
+	snap := filepath.Join(home, "snap", "steam", "common", ".local", "share", "Steam", "steamapps", "common", "No Man's Sky")
+	mkGame(t, snap)
+
+	gog := filepath.Join(home, "Games", "Heroic", "No Man's Sky")
+	mkGame(t, gog)
+	write(t, filepath.Join(home, ".config", "heroic", "gog_store", "installed.json"),
+		`{"installed":[{"appName":"1446213994","install_path":"`+gog+`","platform":"windows"}]}`)
+
+	lutris := filepath.Join(home, "Games", "nms-lutris", "drive_c", "NMS")
+	mkGame(t, lutris)
+	write(t, filepath.Join(home, ".config", "lutris", "games", "no-mans-sky-1.yml"),
+		"game:\n  exe: drive_c/NMS/Binaries/NMS.exe\n  prefix: "+filepath.Join(home, "Games", "nms-lutris")+"\nwine:\n  version: lutris-GE\n")
+
+	bottle := filepath.Join(home, ".local", "share", "bottles", "bottles", "Gaming", "drive_c", "GOG Games", "No Man's Sky")
+	mkGame(t, bottle)
+
+	got := map[string]string{}
+	for _, c := range Find(home) {
+		got[c.Path] = c.Source
+	}
+	want := map[string]string{
+		snap:   "steam-snap",
+		gog:    "heroic-gog",
+		lutris: "lutris",
+		bottle: "bottles",
+	}
+	for p, src := range want {
+		if got[p] != src {
+			t.Errorf("%s: got source %q, want %q (all: %v)", p, got[p], src, got)
+		}
+	}
+	if len(got) != len(want) {
+		t.Fatalf("unexpected candidates: %v", got)
+	}
+}
+
+func TestPgaDBPaths_ScansTextColumns(t *testing.T) {
+	dir := t.TempDir()
+	game := filepath.Join(dir, "Games", "No Man's Sky")
+	db := filepath.Join(dir, "pga.db")
+	write(t, db, "SQLite format 3\x00\x01\x02no-mans-sky\x00"+game+"\x00wine\x00")
+
+	got := pgaDBPaths(db)
+	if len(got) != 1 || got[0] != game {
+		t.Fatalf("got %v", got)
+	}
+}
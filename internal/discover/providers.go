@@ -0,0 +1,219 @@
+package discover
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"nmsmods/internal/steam"
+)
+
+// gogAppName is the GOG product id of No Man's Sky (Heroic's appName).
+const gogAppName = "1446213994"
+
+const gameDirName = "No Man's Sky"
+
+type steamProvider struct{}
+
+func (steamProvider) Name() string { return "steam" }
+
+func (steamProvider) Find(home string) []Candidate {
+	out := []Candidate{}
+	for _, root := range steam.KnownRoots(home) {
+		for _, p := range steam.FindInRoot(root.Path) {
+			out = append(out, Candidate{Path: p, Source: root.Label})
+		}
+	}
+	return out
+}
+
+// heroicProvider reads Heroic's GOG install list (native and Flatpak).
+type heroicProvider struct{}
+
+func (heroicProvider) Name() string { return "heroic-gog" }
+
+type heroicInstalled struct {
+	Installed []struct {
+		AppName     string `json:"appName"`
+		InstallPath string `json:"install_path"`
+	} `json:"installed"`
+}
+
+func (heroicProvider) Find(home string) []Candidate {
+	files := []string{
+		filepath.Join(home, ".config", "heroic", "gog_store", "installed.json"),
+		filepath.Join(home, ".var", "app", "com.heroicgameslauncher.hgl", "config", "heroic", "gog_store", "installed.json"),
+	}
+	out := []Candidate{}
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			continue
+		}
+		var inst heroicInstalled
+		if err := json.Unmarshal(b, &inst); err != nil {
+			continue
+		}
+		for _, g := range inst.Installed {
+			if g.InstallPath == "" {
+				continue
+			}
+			if g.AppName != gogAppName && !strings.EqualFold(filepath.Base(g.InstallPath), gameDirName) {
+				continue
+			}
+			if isDir(g.InstallPath) {
+				out = append(out, Candidate{Path: g.InstallPath, Source: "heroic-gog"})
+			}
+		}
+	}
+	return out
+}
+
+// lutrisProvider reads Lutris game configs (YAML) and, best-effort, paths stored in pga.db.
+type lutrisProvider struct{}
+
+func (lutrisProvider) Name() string { return "lutris" }
+
+func (lutrisProvider) Find(home string) []Candidate {
+	configDirs := []string{
+		filepath.Join(home, ".config", "lutris", "games"),
+		filepath.Join(home, ".local", "share", "lutris", "games"),
+		filepath.Join(home, ".var", "app", "net.lutris.Lutris", "config", "lutris", "games"),
+		filepath.Join(home, ".var", "app", "net.lutris.Lutris", "data", "lutris", "games"),
+	}
+	dbs := []string{
+		filepath.Join(home, ".local", "share", "lutris", "pga.db"),
+		filepath.Join(home, ".var", "app", "net.lutris.Lutris", "data", "lutris", "pga.db"),
+	}
+
+	out := []Candidate{}
+	add := func(p string) {
+		if p != "" && looksLikeNMS(p) {
+			out = append(out, Candidate{Path: p, Source: "lutris"})
+		}
+	}
+	for _, dir := range configDirs {
+		ents, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range ents {
+			n := strings.ToLower(e.Name())
+			if e.IsDir() || !(strings.HasSuffix(n, ".yml") || strings.HasSuffix(n, ".yaml")) {
+				continue
+			}
+			for _, p := range lutrisConfigPaths(filepath.Join(dir, e.Name())) {
+				add(p)
+			}
+		}
+	}
+	for _, db := range dbs {
+		for _, p := range pgaDBPaths(db) {
+			add(p)
+		}
+	}
+	return out
+}
+
+// lutrisConfigPaths extracts candidate game roots from a Lutris game YAML.
+// Only the flat `exe:`, `prefix:` and `working_dir:` keys are needed, so no YAML parser is used.
+func lutrisConfigPaths(path string) []string {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var exe, prefix, workdir string
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		k, v, ok := strings.Cut(strings.TrimSpace(s.Text()), ":")
+		if !ok {
+			continue
+		}
+		v = strings.Trim(strings.TrimSpace(v), `"'`)
+		switch strings.TrimSpace(k) {
+		case "exe":
+			exe = v
+		case "prefix":
+			prefix = v
+		case "working_dir":
+			workdir = v
+		}
+	}
+	if exe != "" && !filepath.IsAbs(exe) && prefix != "" {
+		exe = filepath.Join(prefix, exe)
+	}
+
+	out := []string{}
+	if exe != "" && strings.EqualFold(filepath.Base(strings.ReplaceAll(exe, "\\", "/")), "NMS.exe") {
+		out = append(out, gameRootFromExe(exe))
+	}
+	if workdir != "" {
+		out = append(out, workdir, filepath.Dir(workdir))
+	}
+	return out
+}
+
+// pgaPathRe matches absolute paths ending in a "No Man's Sky" directory inside pga.db text.
+var pgaPathRe = regexp.MustCompile(`/[^\x00-\x1f"]*No Man's Sky`)
+
+// pgaDBPaths scans Lutris' SQLite database for install paths without an SQLite driver:
+// the `directory` column is stored as plain text, so a byte scan is enough.
+func pgaDBPaths(path string) []string {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	out := []string{}
+	seen := map[string]struct{}{}
+	for _, m := range pgaPathRe.FindAll(b, -1) {
+		p := filepath.Clean(string(m))
+		if _, ok := seen[p]; ok {
+			continue
+		}
+		seen[p] = struct{}{}
+		out = append(out, p)
+	}
+	return out
+}
+
+// bottlesProvider probes the usual Steam/GOG locations inside every Bottles prefix.
+type bottlesProvider struct{}
+
+func (bottlesProvider) Name() string { return "bottles" }
+
+func (bottlesProvider) Find(home string) []Candidate {
+	roots := []string{
+		filepath.Join(home, ".local", "share", "bottles", "bottles"),
+		filepath.Join(home, ".var", "app", "com.usebottles.bottles", "data", "bottles", "bottles"),
+	}
+	inside := []string{
+		filepath.Join("drive_c", "Program Files (x86)", "Steam", "steamapps", "common", gameDirName),
+		filepath.Join("drive_c", "Program Files", "Steam", "steamapps", "common", gameDirName),
+		filepath.Join("drive_c", "GOG Games", gameDirName),
+		filepath.Join("drive_c", "Program Files (x86)", "GOG Galaxy", "Games", gameDirName),
+	}
+	out := []Candidate{}
+	for _, root := range roots {
+		ents, err := os.ReadDir(root)
+		if err != nil {
+			continue
+		}
+		for _, e := range ents {
+			if !e.IsDir() {
+				continue
+			}
+			for _, rel := range inside {
+				p := filepath.Join(root, e.Name(), rel)
+				if isDir(p) {
+					out = append(out, Candidate{Path: p, Source: "bottles"})
+				}
+			}
+		}
+	}
+	return out
+}
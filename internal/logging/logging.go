@@ -0,0 +1,236 @@
+// Package logging sets up nmsmods' structured logs (log/slog): a rotating JSON log file under
+// the state directory that keeps every level, plus optional stderr output for -v/-vv.
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Log file defaults: nmsmods.log plus DefaultKeep rotated generations of DefaultMaxBytes each.
+const (
+	FileName        = "nmsmods.log"
+	DefaultMaxBytes = 5 << 20
+	DefaultKeep     = 3
+)
+
+// Formats for stderr output.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Dir returns the log directory under a state root.
+func Dir(root string) string { return filepath.Join(root, "logs") }
+
+// Path returns the log file under a state root.
+func Path(root string) string { return filepath.Join(Dir(root), FileName) }
+
+// Options configures Setup.
+type Options struct {
+	// File is the log file ("" = no file). It always records debug level as JSON.
+	File string
+	// Verbosity selects stderr output: 0 none, 1 info, 2 or more debug.
+	Verbosity int
+	// Format is the stderr format: FormatText (default) or FormatJSON.
+	Format string
+	// Stderr receives the stderr output (default os.Stderr).
+	Stderr io.Writer
+}
+
+// Setup installs the default slog logger described by opts and returns a function that closes
+// the log file. A log file that cannot be opened is skipped (and reported in the error) so
+// logging never stops a command.
+func Setup(opts Options) (func() error, error) {
+	switch opts.Format {
+	case "", FormatText, FormatJSON:
+	default:
+		return nil, fmt.Errorf("invalid log format %q (use text or json)", opts.Format)
+	}
+
+	var handlers []slog.Handler
+	closeFn := func() error { return nil }
+	var fileErr error
+	if opts.File != "" {
+		w, err := OpenRotating(opts.File, DefaultMaxBytes, DefaultKeep)
+		if err != nil {
+			fileErr = fmt.Errorf("log file disabled: %w", err)
+		} else {
+			handlers = append(handlers, slog.NewJSONHandler(w, &slog.HandlerOptions{Level: slog.LevelDebug}))
+			closeFn = w.Close
+		}
+	}
+	if opts.Verbosity > 0 {
+		out := opts.Stderr
+		if out == nil {
+			out = os.Stderr
+		}
+		level := slog.LevelInfo
+		if opts.Verbosity > 1 {
+			level = slog.LevelDebug
+		}
+		ho := &slog.HandlerOptions{Level: level}
+		if opts.Format == FormatJSON {
+			handlers = append(handlers, slog.NewJSONHandler(out, ho))
+		} else {
+			handlers = append(handlers, slog.NewTextHandler(out, ho))
+		}
+	}
+	slog.SetDefault(slog.New(fanout(handlers)))
+	return closeFn, fileErr
+}
+
+// fanout sends each record to every handler that accepts its level.
+type fanout []slog.Handler
+
+func (f fanout) Enabled(ctx context.Context, l slog.Level) bool {
+	for _, h := range f {
+		if h.Enabled(ctx, l) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f fanout) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, h := range f {
+		if h.Enabled(ctx, r.Level) {
+			errs = append(errs, h.Handle(ctx, r.Clone()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (f fanout) WithAttrs(attrs []slog.Attr) slog.Handler {
+	out := make(fanout, len(f))
+	for i, h := range f {
+		out[i] = h.WithAttrs(attrs)
+	}
+	return out
+}
+
+func (f fanout) WithGroup(name string) slog.Handler {
+	out := make(fanout, len(f))
+	for i, h := range f {
+		out[i] = h.WithGroup(name)
+	}
+	return out
+}
+
+// secretParams are query parameters that authorize a request (nxm:// keys, signed CDN links).
+var secretParams = map[string]bool{
+	"key": true, "expires": true, "user_id": true, "apikey": true, "api_key": true,
+	"token": true, "signature": true, "sig": true, "md5": true,
+}
+
+// RedactURL returns raw safe to log: user info is dropped and secret query values are
+// replaced with "REDACTED".
+func RedactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "(unparseable url)"
+	}
+	u.User = nil
+	if u.RawQuery != "" {
+		q := u.Query()
+		for k := range q {
+			if secretParams[strings.ToLower(k)] {
+				q.Set(k, "REDACTED")
+			}
+		}
+		u.RawQuery = q.Encode()
+	}
+	return u.String()
+}
+
+// Rotating is an append-only log file that renames itself to <path>.1 (shifting older
+// generations up to <path>.<keep>) once it would grow past maxBytes.
+type Rotating struct {
+	mu       sync.Mutex
+	path     string
+	maxBytes int64
+	keep     int
+	f        *os.File
+	size     int64
+}
+
+// OpenRotating opens (or creates) path for appending.
+func OpenRotating(path string, maxBytes int64, keep int) (*Rotating, error) {
+	r := &Rotating{path: path, maxBytes: maxBytes, keep: keep}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *Rotating) open() error {
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	r.f, r.size = f, fi.Size()
+	return nil
+}
+
+func (r *Rotating) Write(b []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.f == nil {
+		return 0, os.ErrClosed
+	}
+	if r.size > 0 && r.size+int64(len(b)) > r.maxBytes {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.f.Write(b)
+	r.size += int64(n)
+	return n, err
+}
+
+func (r *Rotating) rotate() error {
+	if err := r.f.Close(); err != nil {
+		return err
+	}
+	r.f = nil
+	if r.keep < 1 {
+		_ = os.Remove(r.path)
+	} else {
+		for i := r.keep - 1; i >= 1; i-- {
+			_ = os.Rename(fmt.Sprintf("%s.%d", r.path, i), fmt.Sprintf("%s.%d", r.path, i+1))
+		}
+		if err := os.Rename(r.path, r.path+".1"); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return r.open()
+}
+
+// Close closes the file.
+func (r *Rotating) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.f == nil {
+		return nil
+	}
+	err := r.f.Close()
+	r.f = nil
+	return err
+}
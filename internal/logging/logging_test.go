@@ -0,0 +1,77 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRotating(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", FileName)
+	r, err := OpenRotating(path, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"aaaaaaaa\n", "bbbbbbbb\n", "cccccccc\n", "dddddddd\n"} {
+		if _, err := r.Write([]byte(s)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{"": "dddddddd\n", ".1": "cccccccc\n", ".2": "bbbbbbbb\n"} {
+		b, err := os.ReadFile(path + name)
+		if err != nil || string(b) != want {
+			t.Fatalf("%s: got %q, %v; want %q", path+name, b, err, want)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Fatalf("expected only 2 generations, got %v", err)
+	}
+}
+
+func TestSetup_FileAndVerbosity(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	path := filepath.Join(t.TempDir(), FileName)
+	var stderr bytes.Buffer
+	closeFn, err := Setup(Options{File: path, Verbosity: 1, Stderr: &stderr})
+	if err != nil {
+		t.Fatal(err)
+	}
+	slog.Debug("debug only in file")
+	slog.Info("info everywhere", "n", 1)
+	if err := closeFn(); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(stderr.String(), "debug only") || !strings.Contains(stderr.String(), "info everywhere") {
+		t.Fatalf("unexpected stderr: %q", stderr.String())
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 log lines, got %q", b)
+	}
+	var rec map[string]any
+	if err := json.Unmarshal([]byte(lines[1]), &rec); err != nil || rec["msg"] != "info everywhere" {
+		t.Fatalf("unexpected record %q: %v", lines[1], err)
+	}
+}
+
+func TestRedactURL(t *testing.T) {
+	got := RedactURL("nxm://nomanssky/mods/1/files/2?key=abc&expires=123&user_id=9&x=1")
+	if strings.Contains(got, "abc") || strings.Contains(got, "123") || !strings.Contains(got, "x=1") {
+		t.Fatalf("secrets not redacted: %s", got)
+	}
+	if got := RedactURL("https://u:p@example.com/a.zip"); strings.Contains(got, "u:p") {
+		t.Fatalf("user info not dropped: %s", got)
+	}
+}
@@ -2,6 +2,8 @@ package mods
 
 import (
 	"fmt"
+	"path/filepath"
+	"slices"
 	"strings"
 
 	"nmsmods/internal/app"
@@ -18,10 +20,33 @@ func ResolveFolderCollision(id string, desiredFolder string, profile string, st
 		if ok && pi.Installed && strings.EqualFold(pi.Folder, desiredFolder) && pi.Folder != "" {
 			return fmt.Sprintf("%s__%s", desiredFolder, id), true
 		}
-		// Back-compat: if state was not migrated for some reason.
-		if me.Installed && strings.EqualFold(me.Folder, desiredFolder) && me.Folder != "" {
-			return fmt.Sprintf("%s__%s", desiredFolder, id), true
-		}
 	}
 	return desiredFolder, false
 }
+
+// FileConflicts returns the game files the mod id, stored at store, shares with the other mods
+// enabled in profile (stores are relative to root, as recorded in state). Both sides are read
+// with ModFileSet, so files packed into .pak archives count like loose files.
+func FileConflicts(root string, st app.State, profile, id, store string) ([]Conflict, error) {
+	set, err := ModFileSet(store)
+	if err != nil {
+		return nil, err
+	}
+	sets := map[string]FileSet{id: set}
+	for otherID, me := range st.Mods {
+		pi, ok := me.Installations[profile]
+		if otherID == id || !ok || !pi.Installed || !pi.Enabled || pi.Store == "" {
+			continue
+		}
+		if other, err := ModFileSet(filepath.Join(root, filepath.FromSlash(pi.Store))); err == nil {
+			sets[otherID] = other
+		}
+	}
+	out := []Conflict{}
+	for _, c := range FindConflicts(sets) {
+		if slices.Contains(c.Mods, id) {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
@@ -1,6 +1,12 @@
 package mods
 
 import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"nmsmods/internal/app"
@@ -28,3 +34,74 @@ func TestResolveFolderCollision_ProfileScoped(t *testing.T) {
 		t.Fatalf("expected Foo, got %s", folder2)
 	}
 }
+
+// writePAK writes a minimal PSARC holding names, each stored uncompressed in one block.
+func writePAK(t *testing.T, path string, names ...string) {
+	t.Helper()
+	payloads := [][]byte{[]byte(strings.Join(names, "\n"))}
+	for range names {
+		payloads = append(payloads, []byte("mbin"))
+	}
+	be := binary.BigEndian
+	tocLen := 32 + len(payloads)*30 + len(payloads)*2
+	var toc, data bytes.Buffer
+	hdr := make([]byte, 32)
+	copy(hdr, "PSAR")
+	be.PutUint16(hdr[4:], 1)
+	be.PutUint16(hdr[6:], 4)
+	copy(hdr[8:], "zlib")
+	be.PutUint32(hdr[12:], uint32(tocLen))
+	be.PutUint32(hdr[16:], 30)
+	be.PutUint32(hdr[20:], uint32(len(payloads)))
+	be.PutUint32(hdr[24:], 65536)
+	be.PutUint32(hdr[28:], 2)
+	toc.Write(hdr)
+	for i, p := range payloads {
+		e := make([]byte, 30)
+		be.PutUint32(e[16:], uint32(i))
+		length, offset := uint64(len(p)), uint64(tocLen+data.Len())
+		for j := 4; j >= 0; j-- {
+			e[20+j], e[25+j] = byte(length), byte(offset)
+			length, offset = length>>8, offset>>8
+		}
+		toc.Write(e)
+		data.Write(p)
+	}
+	for _, p := range payloads {
+		_ = binary.Write(&toc, be, uint16(len(p)))
+	}
+	if err := os.WriteFile(path, append(toc.Bytes(), data.Bytes()...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFileConflicts_SeesIntoPAKs(t *testing.T) {
+	root := t.TempDir()
+	mk := func(rel string) string {
+		dir := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Join(dir, "METADATA"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		return dir
+	}
+	// The new mod ships loose files; the enabled one packs the same table into a PAK.
+	newStore := mk("profiles/p/mods/New")
+	if err := os.WriteFile(filepath.Join(newStore, "METADATA", "PRODUCTS.EXML"), []byte("<x/>"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	writePAK(t, filepath.Join(mk("profiles/p/mods/Old"), "Old.pak"), "METADATA/PRODUCTS.MBIN", "METADATA/OTHER.MBIN")
+	writePAK(t, filepath.Join(mk("profiles/p/mods/Off"), "Off.pak"), "METADATA/PRODUCTS.MBIN")
+
+	st := app.State{Mods: map[string]app.ModEntry{
+		"old": {Installations: map[string]app.ProfileInstall{"p": {Installed: true, Enabled: true, Store: "profiles/p/mods/Old"}}},
+		"off": {Installations: map[string]app.ProfileInstall{"p": {Installed: true, Store: "profiles/p/mods/Off"}}},
+	}}
+	got, err := FileConflicts(root, st, "p", "new", newStore)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Conflict{{Path: "METADATA/PRODUCTS.MBIN", Mods: []string{"new", "old"}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("conflicts = %+v, want %+v", got, want)
+	}
+}
@@ -3,13 +3,15 @@ package mods
 import (
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 )
 
-const managedMarkerFile = ".nmsmods.managed.json"
+// ManagedMarkerFile is the marker written into every folder nmsmods deploys.
+const ManagedMarkerFile = ".nmsmods.managed.json"
 
 // ManagedMarker is written into deployed folders inside the game's MODS directory.
 // It allows nmsmods to refuse clobbering/deleting folders it does not own.
@@ -19,6 +21,15 @@ type ManagedMarker struct {
 	Profile  string `json:"profile"`
 	Deployed string `json:"deployed_at"`
 	Tool     string `json:"tool"`
+	Target   string `json:"target,omitempty"` // game target the folder was deployed to
+}
+
+// DeployOptions carries optional Deploy settings.
+type DeployOptions struct {
+	// Target is the configured game target name, recorded in the managed marker.
+	Target string
+	// Hardlink links store files into GAMEDATA/MODS instead of copying them.
+	Hardlink bool
 }
 
 func managedTag(modID, profile string) string {
@@ -26,7 +37,7 @@ func managedTag(modID, profile string) string {
 }
 
 func ReadManagedMarker(dest string) (ManagedMarker, error) {
-	b, err := os.ReadFile(filepath.Join(dest, managedMarkerFile))
+	b, err := os.ReadFile(filepath.Join(dest, ManagedMarkerFile))
 	if err != nil {
 		return ManagedMarker{}, err
 	}
@@ -46,19 +57,20 @@ func readManagedTag(dest string) (string, error) {
 	return m.Tag, nil
 }
 
-func writeManagedMarker(dest, modID, profile string) error {
+func writeManagedMarker(dest, modID, profile, target string) error {
 	m := ManagedMarker{
 		Tag:      managedTag(modID, profile),
 		ModID:    modID,
 		Profile:  profile,
 		Deployed: time.Now().Format(time.RFC3339),
 		Tool:     "nmsmods",
+		Target:   target,
 	}
 	b, err := json.MarshalIndent(m, "", "  ")
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(filepath.Join(dest, managedMarkerFile), b, 0o644)
+	return os.WriteFile(filepath.Join(dest, ManagedMarkerFile), b, 0o644)
 }
 
 // uniquePath returns a non-existent path under dir with a given prefix.
@@ -82,7 +94,7 @@ func uniquePath(dir, prefix string) (string, error) {
 // - Refuses to overwrite an existing folder that is not managed by nmsmods.
 //
 // Returns the deployed path (<gameModsDir>/<folder>).
-func Deploy(storePath string, gameModsDir string, folder string, modID string, profile string) (string, error) {
+func Deploy(storePath string, gameModsDir string, folder string, modID string, profile string, opts DeployOptions) (string, error) {
 	folder, err := SanitizeFolderName(folder, modID)
 	if err != nil {
 		return "", err
@@ -114,11 +126,15 @@ func Deploy(storePath string, gameModsDir string, folder string, modID string, p
 		return "", err
 	}
 	_ = os.RemoveAll(stage)
-	if err := CopyDir(storePath, stage); err != nil {
+	populate := CopyDir
+	if opts.Hardlink {
+		populate = LinkDir
+	}
+	if err := populate(storePath, stage); err != nil {
 		_ = os.RemoveAll(stage)
 		return "", err
 	}
-	if err := writeManagedMarker(stage, modID, profile); err != nil {
+	if err := writeManagedMarker(stage, modID, profile, opts.Target); err != nil {
 		_ = os.RemoveAll(stage)
 		return "", err
 	}
@@ -148,6 +164,7 @@ func Deploy(storePath string, gameModsDir string, folder string, modID string, p
 		_ = os.RemoveAll(backup)
 	}
 
+	slog.Debug("deployed mod folder", "mod", modID, "profile", profile, "store", storePath, "dest", dest, "hardlink", opts.Hardlink)
 	return dest, nil
 }
 
@@ -182,5 +199,6 @@ func Undeploy(gameModsDir string, folder string, modID string, profile string) e
 		return fmt.Errorf("refusing to remove folder managed by different mod/profile: %s", dest)
 	}
 
+	slog.Debug("undeploying mod folder", "mod", modID, "profile", profile, "dest", dest)
 	return os.RemoveAll(dest)
 }
@@ -0,0 +1,49 @@
+package mods
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDeploy_HardlinkSharesStoreFiles(t *testing.T) {
+	root := t.TempDir()
+	store := filepath.Join(root, "store", "MyMod")
+	modsDir := filepath.Join(root, "MODS")
+	if err := os.MkdirAll(filepath.Join(store, "METADATA"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	src := filepath.Join(store, "METADATA", "A.MBIN")
+	if err := os.WriteFile(src, []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(modsDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	dest, err := Deploy(store, modsDir, "MyMod", "my-mod", "default", DeployOptions{Hardlink: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := os.Stat(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.Stat(filepath.Join(dest, "METADATA", "A.MBIN"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !os.SameFile(a, b) {
+		t.Fatal("expected deployed file to be a hard link to the store file")
+	}
+	if m, err := ReadManagedMarker(dest); err != nil || m.ModID != "my-mod" {
+		t.Fatalf("expected managed marker, got %+v, %v", m, err)
+	}
+	// Undeploy must remove the link only, never the store.
+	if err := Undeploy(modsDir, "MyMod", "my-mod", "default"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(src); err != nil {
+		t.Fatalf("store file removed by undeploy: %v", err)
+	}
+}
@@ -2,36 +2,60 @@ package mods
 
 import (
 	"archive/zip"
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"os"
 	"path/filepath"
 	"time"
+
+	"nmsmods/internal/logging"
 )
 
 const defaultMaxDownloadBytes = int64(20) * 1024 * 1024 * 1024 // 20GB
 
+// ErrNotZip is returned when a download completes but is not a zip archive.
+var ErrNotZip = errors.New("downloaded file is not a valid zip")
+
 func maxDownloadBytes() int64 {
 	return defaultMaxDownloadBytes
 }
 
 func DownloadURLToFile(url, dest string) error {
+	return DownloadURLToFileContext(context.Background(), url, dest)
+}
+
+// DownloadURLToFileContext is DownloadURLToFile with cancellation: ctx aborts the transfer and
+// the retry wait.
+func DownloadURLToFileContext(ctx context.Context, url, dest string) error {
 	var lastErr error
 
 	for attempt := 1; attempt <= 3; attempt++ {
-		err := downloadOnce(url, dest)
+		err := downloadOnce(ctx, url, dest)
 		if err == nil {
 			return nil
 		}
 		lastErr = err
-		time.Sleep(time.Duration(attempt) * time.Second)
+		slog.Warn("download attempt failed", "url", logging.RedactURL(url), "attempt", attempt, "error", err)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if errors.Is(err, ErrNotZip) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempt) * time.Second):
+		}
 	}
 	return fmt.Errorf("download failed after retries: %w", lastErr)
 }
 
-func downloadOnce(url, dest string) error {
+func downloadOnce(ctx context.Context, url, dest string) error {
 	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
 		return err
 	}
@@ -43,11 +67,18 @@ func downloadOnce(url, dest string) error {
 	}
 	defer out.Close()
 
-	resp, err := http.Get(url)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
+	slog.Info("http request", "method", http.MethodGet, "url", logging.RedactURL(url), "status", resp.StatusCode,
+		"content_length", resp.ContentLength)
 
 	if resp.StatusCode != http.StatusOK {
 		return fmt.Errorf("download failed: %s", resp.Status)
@@ -72,8 +103,13 @@ func downloadOnce(url, dest string) error {
 	// Validate it's actually a zip
 	if _, err := zip.OpenReader(tmp); err != nil {
 		_ = os.Remove(tmp)
-		return fmt.Errorf("downloaded file is not a valid zip")
+		return ErrNotZip
 	}
 
-	return os.Rename(tmp, dest)
+	if err := os.Rename(tmp, dest); err != nil {
+		return err
+	}
+	slog.Info("download complete", "url", logging.RedactURL(url), "dest", dest, "bytes", written,
+		"duration_ms", time.Since(start).Milliseconds())
+	return nil
 }
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -218,5 +219,6 @@ func ExtractZip(zipPath, destDir string) error {
 		}
 	}
 
+	slog.Debug("extracted zip", "zip", zipPath, "dest", destDir, "entries", len(r.File), "bytes", total)
 	return nil
 }
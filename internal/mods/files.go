@@ -0,0 +1,96 @@
+package mods
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"nmsmods/internal/nms/psarc"
+)
+
+// FileSet maps a normalized game path (upper case, slash separated, .EXML folded to .MBIN)
+// to where the file comes from inside a mod folder: either "REL/PATH" for loose files or
+// "Archive.pak:REL/PATH" for files packed into a PSARC.
+type FileSet map[string]string
+
+// GamePathKey normalizes a game-relative path so loose and packed files compare equal.
+// EXML files are compiled to MBIN by the game, so both map to the same key.
+func GamePathKey(rel string) string {
+	k := strings.ToUpper(strings.TrimLeft(filepath.ToSlash(rel), "/"))
+	if strings.HasSuffix(k, ".EXML") {
+		k = strings.TrimSuffix(k, ".EXML") + ".MBIN"
+	}
+	return k
+}
+
+// ModFileSet lists the game files a mod folder provides, looking inside .pak archives.
+// Top-level loose files (readmes, previews) and the managed marker are ignored because
+// the game only reads files below a directory (METADATA/, TEXTURES/, ...).
+func ModFileSet(root string) (FileSet, error) {
+	out := FileSet{}
+	err := filepath.WalkDir(root, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, rerr := filepath.Rel(root, p)
+		if rerr != nil {
+			return rerr
+		}
+		rel = filepath.ToSlash(rel)
+		if d.Name() == ManagedMarkerFile {
+			return nil
+		}
+		if strings.HasSuffix(strings.ToLower(d.Name()), ".pak") {
+			a, aerr := psarc.Open(p)
+			if aerr != nil {
+				// Keep going: a broken archive should not hide the rest of the mod.
+				out[GamePathKey(rel)] = rel
+				return nil
+			}
+			for _, f := range a.Files() {
+				out[GamePathKey(f)] = rel + ":" + f
+			}
+			_ = a.Close()
+			return nil
+		}
+		if !strings.Contains(rel, "/") {
+			return nil
+		}
+		out[GamePathKey(rel)] = rel
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Conflict is a game path provided by more than one mod.
+type Conflict struct {
+	Path string   `json:"path"`
+	Mods []string `json:"mods"`
+}
+
+// FindConflicts returns game paths provided by two or more of the given mods (keyed by mod id).
+func FindConflicts(sets map[string]FileSet) []Conflict {
+	owners := map[string][]string{}
+	for id, fsSet := range sets {
+		for k := range fsSet {
+			owners[k] = append(owners[k], id)
+		}
+	}
+	out := []Conflict{}
+	for k, ids := range owners {
+		if len(ids) < 2 {
+			continue
+		}
+		sort.Strings(ids)
+		out = append(out, Conflict{Path: k, Mods: ids})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+	return out
+}
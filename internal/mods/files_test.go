@@ -0,0 +1,18 @@
+package mods
+
+import "testing"
+
+func TestFindConflicts_FoldsEXMLAndCase(t *testing.T) {
+	sets := map[string]FileSet{
+		"a": {GamePathKey("METADATA/Reality/Tables/Products.EXML"): "x"},
+		"b": {GamePathKey("/METADATA/REALITY/TABLES/PRODUCTS.MBIN"): "Mod.pak:METADATA/REALITY/TABLES/PRODUCTS.MBIN"},
+		"c": {GamePathKey("TEXTURES/OTHER.DDS"): "y"},
+	}
+	got := FindConflicts(sets)
+	if len(got) != 1 {
+		t.Fatalf("expected 1 conflict, got %v", got)
+	}
+	if got[0].Path != "METADATA/REALITY/TABLES/PRODUCTS.MBIN" || len(got[0].Mods) != 2 || got[0].Mods[0] != "a" {
+		t.Fatalf("unexpected conflict: %+v", got[0])
+	}
+}
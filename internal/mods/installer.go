@@ -35,6 +35,35 @@ func CopyDir(src, dst string) error {
 	return nil
 }
 
+// LinkDir mirrors src into dst with hard links, copying any file that cannot be linked
+// (e.g. store and game on different filesystems).
+func LinkDir(src, dst string) error {
+	if err := os.MkdirAll(dst, 0o755); err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		sPath := filepath.Join(src, e.Name())
+		dPath := filepath.Join(dst, e.Name())
+		if e.IsDir() {
+			if err := LinkDir(sPath, dPath); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := os.Link(sPath, dPath); err == nil {
+			continue
+		}
+		if err := copyFile(sPath, dPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func copyFile(src, dst string) error {
 	in, err := os.Open(src)
 	if err != nil {
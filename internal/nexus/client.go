@@ -5,9 +5,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
+	"sync"
 	"time"
+
+	"nmsmods/internal/logging"
 )
 
 const DefaultBaseURL = "https://api.nexusmods.com/v1"
@@ -20,6 +24,10 @@ type Client struct {
 	appVer    string
 	httpc     *http.Client
 	userAgent string
+	logger    *slog.Logger
+
+	mu         sync.Mutex
+	categories map[string]map[int]string // game domain -> category id -> name
 }
 
 type ClientOption func(*Client)
@@ -42,6 +50,13 @@ func WithUserAgent(ua string) ClientOption {
 	}
 }
 
+// WithLogger logs requests to l instead of slog.Default(). The API key is never logged.
+func WithLogger(l *slog.Logger) ClientOption {
+	return func(c *Client) {
+		c.logger = l
+	}
+}
+
 func NewClient(apiKey, appName, appVersion string, opts ...ClientOption) *Client {
 	c := &Client{
 		baseURL: DefaultBaseURL,
@@ -79,13 +94,21 @@ func (c *Client) doJSON(ctx context.Context, method, fullURL string, out any) er
 		req.Header.Set("User-Agent", c.userAgent)
 	}
 
+	log := c.logger
+	if log == nil {
+		log = slog.Default()
+	}
+	start := time.Now()
 	resp, err := c.httpc.Do(req)
 	if err != nil {
+		log.Warn("http request failed", "method", method, "url", logging.RedactURL(fullURL), "error", err)
 		return err
 	}
 	defer resp.Body.Close()
 
 	b, _ := io.ReadAll(resp.Body)
+	log.Info("http request", "method", method, "url", logging.RedactURL(fullURL), "status", resp.StatusCode,
+		"bytes", len(b), "duration_ms", time.Since(start).Milliseconds())
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		return &APIError{
@@ -139,6 +162,40 @@ func (c *Client) GetMod(ctx context.Context, gameDomain string, modID int) (*Mod
 	return &out, nil
 }
 
+// GetGame returns a game's details, including its mod categories.
+// Endpoint: GET /v1/games/{game_domain}.json
+func (c *Client) GetGame(ctx context.Context, gameDomain string) (*GameInfo, error) {
+	u := fmt.Sprintf("%s/games/%s.json", c.baseURL, url.PathEscape(gameDomain))
+	var out GameInfo
+	if err := c.doJSON(ctx, http.MethodGet, u, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ModCategory returns the name of a mod category (ModInfo.CategoryID) of gameDomain. The
+// game's category list is fetched once per Client.
+func (c *Client) ModCategory(ctx context.Context, gameDomain string, categoryID int) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	names, ok := c.categories[gameDomain]
+	if !ok {
+		g, err := c.GetGame(ctx, gameDomain)
+		if err != nil {
+			return "", err
+		}
+		names = map[int]string{}
+		for _, cat := range g.Categories {
+			names[cat.CategoryID] = cat.Name
+		}
+		if c.categories == nil {
+			c.categories = map[string]map[int]string{}
+		}
+		c.categories[gameDomain] = names
+	}
+	return names[categoryID], nil
+}
+
 // ListFiles returns file list for a mod.
 // Endpoint: GET /v1/games/{game_domain}/mods/{mod_id}/files.json
 func (c *Client) ListFiles(ctx context.Context, gameDomain string, modID int) ([]FileInfo, error) {
@@ -23,6 +23,8 @@ type ModInfo struct {
 	Version string `json:"version,omitempty"`
 	Author  string `json:"author,omitempty"`
 
+	CategoryID int `json:"category_id,omitempty"` // name via Client.ModCategory
+
 	CreatedTime string `json:"created_time,omitempty"`
 	UpdatedTime string `json:"updated_time,omitempty"`
 
@@ -31,6 +33,20 @@ type ModInfo struct {
 	Downloads        int `json:"downloads,omitempty"`
 }
 
+// GameInfo is returned by the game endpoint; only the category list is used.
+type GameInfo struct {
+	ID         int        `json:"id"`
+	Name       string     `json:"name,omitempty"`
+	DomainName string     `json:"domain_name,omitempty"`
+	Categories []Category `json:"categories,omitempty"`
+}
+
+// Category is a mod category of a game. parent_category (false or an id) is not decoded.
+type Category struct {
+	CategoryID int    `json:"category_id"`
+	Name       string `json:"name"`
+}
+
 // NOTE: The "files" endpoint returns { "files": [ ... ] } and the file payload uses
 // fields like: file_id, file_name, uploaded_timestamp, size, etc.
 type FileInfo struct {
@@ -4,15 +4,27 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 )
 
 type Game struct {
-	Path    string
-	DataDir string
-	ModsDir string
+	Path     string
+	DataDir  string
+	BanksDir string // GAMEDATA/PCBANKS (base game content)
+	ModsDir  string
+
+	// ModsDisabled is true when DISABLEMODS.TXT exists in ModsDir.
+	// While it exists, the game ignores everything in GAMEDATA/MODS.
+	ModsDisabled bool
+
+	// Target is the configured target name this install was resolved from ("" when ad hoc).
+	Target string
 }
 
+// DisableModsFileName is the marker file the game checks for in GAMEDATA/MODS.
+const DisableModsFileName = "DISABLEMODS.TXT"
+
 // ValidateGamePath checks whether the given path looks like a real, installed No Man's Sky directory.
 // This is intentionally stricter than "folder exists" because Steam can leave empty directories after uninstall.
 //
@@ -65,11 +77,62 @@ func ValidateGamePath(gamePath string) (*Game, error) {
 		return nil, fmt.Errorf("no .pak files found in PCBANKS (game likely not installed): %s", pcbanks)
 	}
 
-	return &Game{
-		Path:    root,
-		DataDir: dataDir,
-		ModsDir: filepath.Join(dataDir, "MODS"),
-	}, nil
+	g := &Game{
+		Path:     root,
+		DataDir:  dataDir,
+		BanksDir: pcbanks,
+		ModsDir:  filepath.Join(dataDir, "MODS"),
+	}
+	_, g.ModsDisabled = FindDisableModsFile(g)
+	return g, nil
+}
+
+// FindDisableModsFile returns the path of DISABLEMODS.TXT in the MODS dir (matched case-insensitively).
+func FindDisableModsFile(g *Game) (string, bool) {
+	if g == nil {
+		return "", false
+	}
+	ents, err := os.ReadDir(g.ModsDir)
+	if err != nil {
+		return "", false
+	}
+	for _, e := range ents {
+		if !e.IsDir() && strings.EqualFold(e.Name(), DisableModsFileName) {
+			return filepath.Join(g.ModsDir, e.Name()), true
+		}
+	}
+	return "", false
+}
+
+// SetModsEnabled toggles global mod loading by removing or creating DISABLEMODS.TXT.
+// Deployed mod folders are left untouched. Returns whether anything changed.
+func SetModsEnabled(g *Game, enabled bool) (bool, error) {
+	if g == nil {
+		return false, fmt.Errorf("nil game")
+	}
+	existing, found := FindDisableModsFile(g)
+	if enabled {
+		if !found {
+			return false, nil
+		}
+		if err := os.Remove(existing); err != nil {
+			return false, err
+		}
+		g.ModsDisabled = false
+		return true, nil
+	}
+	if found {
+		return false, nil
+	}
+	if err := EnsureModsDir(g); err != nil {
+		return false, err
+	}
+	body := []byte("Created by nmsmods (nmsmods game mods off). Delete this file to load mods again.\n")
+	if err := os.WriteFile(filepath.Join(g.ModsDir, DisableModsFileName), body, 0o644); err != nil {
+		return false, err
+	}
+	g.ModsDisabled = true
+	return true, nil
 }
 
 // EnsureModsDir ensures the mods directory exists.
@@ -105,6 +168,26 @@ func ListInstalledModFolders(g *Game) ([]string, error) {
 	return out, nil
 }
 
+// ListBankArchives returns the absolute paths of the .pak archives in GAMEDATA/PCBANKS, sorted.
+func ListBankArchives(g *Game) ([]string, error) {
+	if g == nil {
+		return nil, fmt.Errorf("nil game")
+	}
+	ents, err := os.ReadDir(g.BanksDir)
+	if err != nil {
+		return nil, err
+	}
+	out := []string{}
+	for _, e := range ents {
+		if e.IsDir() || !strings.HasSuffix(strings.ToLower(e.Name()), ".pak") {
+			continue
+		}
+		out = append(out, filepath.Join(g.BanksDir, e.Name()))
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
 func isDir(p string) bool {
 	st, err := os.Stat(p)
 	return err == nil && st.IsDir()
@@ -0,0 +1,105 @@
+package nms
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakeGame lays out the minimum ValidateGamePath accepts.
+func fakeGame(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	for _, d := range []string{"Binaries", "GAMEDATA/PCBANKS", "GAMEDATA/MODS/SomeMod"} {
+		if err := os.MkdirAll(filepath.Join(root, filepath.FromSlash(d)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, "GAMEDATA", "PCBANKS", "NMSARC.pak"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func TestValidateGamePath_DetectsDisableModsCaseInsensitively(t *testing.T) {
+	root := fakeGame(t)
+	g, err := ValidateGamePath(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.ModsDisabled {
+		t.Fatal("ModsDisabled without DISABLEMODS.TXT")
+	}
+
+	lower := filepath.Join(g.ModsDir, "disablemods.txt")
+	if err := os.WriteFile(lower, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if g, err = ValidateGamePath(root); err != nil {
+		t.Fatal(err)
+	}
+	if !g.ModsDisabled {
+		t.Fatal("disablemods.txt not detected")
+	}
+	if path, ok := FindDisableModsFile(g); !ok || path != lower {
+		t.Fatalf("FindDisableModsFile = %q, %v", path, ok)
+	}
+}
+
+func TestSetModsEnabled_TogglesFileAndKeepsMods(t *testing.T) {
+	g, err := ValidateGamePath(fakeGame(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	marker := filepath.Join(g.ModsDir, DisableModsFileName)
+
+	for _, step := range []struct {
+		enabled, wantChanged, wantFile bool
+	}{
+		{enabled: true, wantChanged: false, wantFile: false}, // already on
+		{enabled: false, wantChanged: true, wantFile: true},
+		{enabled: false, wantChanged: false, wantFile: true}, // already off
+		{enabled: true, wantChanged: true, wantFile: false},
+	} {
+		changed, err := SetModsEnabled(g, step.enabled)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, statErr := os.Stat(marker)
+		if changed != step.wantChanged || (statErr == nil) != step.wantFile || g.ModsDisabled != step.wantFile {
+			t.Fatalf("SetModsEnabled(%v): changed=%v file=%v ModsDisabled=%v", step.enabled, changed, statErr == nil, g.ModsDisabled)
+		}
+		if _, err := os.Stat(filepath.Join(g.ModsDir, "SomeMod")); err != nil {
+			t.Fatalf("deployed mod touched: %v", err)
+		}
+	}
+
+	// A lower-case file written by hand is removed too.
+	if err := os.WriteFile(filepath.Join(g.ModsDir, "DisableMods.txt"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	g.ModsDisabled = true
+	if changed, err := SetModsEnabled(g, true); err != nil || !changed {
+		t.Fatalf("enable with DisableMods.txt: %v, %v", changed, err)
+	}
+	if _, ok := FindDisableModsFile(g); ok {
+		t.Fatal("DisableMods.txt still present")
+	}
+}
+
+func TestSetModsEnabled_CreatesMissingModsDir(t *testing.T) {
+	root := fakeGame(t)
+	if err := os.RemoveAll(filepath.Join(root, "GAMEDATA", "MODS")); err != nil {
+		t.Fatal(err)
+	}
+	g, err := ValidateGamePath(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed, err := SetModsEnabled(g, false); err != nil || !changed {
+		t.Fatalf("disable without MODS dir: %v, %v", changed, err)
+	}
+	if !g.ModsDisabled {
+		t.Fatal("ModsDisabled not set")
+	}
+}
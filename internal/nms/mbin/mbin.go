@@ -0,0 +1,90 @@
+// Package mbin reads the fixed-size header at the start of No Man's Sky .MBIN files.
+//
+// Layout (little endian, 0x60 bytes):
+//
+//	0x00 uint32   magic (0xCCCCCCCC)
+//	0x04 uint32   format id (e.g. 2500)
+//	0x08 uint64   timestamp / MBINCompiler version
+//	0x10 uint64   template GUID (changes when the struct layout changes)
+//	0x18 [64]byte template class name (NUL padded)
+//	0x58 uint64   end padding
+//
+// Mods compiled against an older game release carry a template GUID (or format id)
+// that no longer matches the game's own file for the same template.
+package mbin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+const HeaderSize = 0x60
+
+const (
+	MagicMBIN   uint32 = 0xCCCCCCCC
+	MagicMBINPC uint32 = 0xDDDDDDDD // older "MBIN.PC" files
+)
+
+var ErrNotMBIN = errors.New("not an MBIN file (bad magic)")
+
+type Header struct {
+	Magic        uint32 `json:"-"`
+	FormatID     uint32 `json:"format_id"`
+	Timestamp    uint64 `json:"timestamp"`
+	TemplateGUID uint64 `json:"template_guid"`
+	TemplateName string `json:"template_name"`
+}
+
+// Version returns a short, comparable representation of the header version.
+func (h Header) Version() string {
+	return fmt.Sprintf("%d/%016x", h.FormatID, h.TemplateGUID)
+}
+
+// ReadHeader parses an MBIN header from the start of r.
+func ReadHeader(r io.Reader) (Header, error) {
+	var buf [HeaderSize]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+			return Header{}, fmt.Errorf("short MBIN header: %w", err)
+		}
+		return Header{}, err
+	}
+	return ParseHeader(buf[:])
+}
+
+// ParseHeader parses an MBIN header from b (at least HeaderSize bytes).
+func ParseHeader(b []byte) (Header, error) {
+	if len(b) < HeaderSize {
+		return Header{}, fmt.Errorf("short MBIN header: %d bytes", len(b))
+	}
+	le := binary.LittleEndian
+	h := Header{
+		Magic:        le.Uint32(b[0x00:]),
+		FormatID:     le.Uint32(b[0x04:]),
+		Timestamp:    le.Uint64(b[0x08:]),
+		TemplateGUID: le.Uint64(b[0x10:]),
+	}
+	if h.Magic != MagicMBIN && h.Magic != MagicMBINPC {
+		return Header{}, ErrNotMBIN
+	}
+	name := b[0x18:0x58]
+	if i := bytes.IndexByte(name, 0); i >= 0 {
+		name = name[:i]
+	}
+	h.TemplateName = string(name)
+	return h, nil
+}
+
+// ReadFile reads the MBIN header of the file at path.
+func ReadFile(path string) (Header, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Header{}, err
+	}
+	defer f.Close()
+	return ReadHeader(f)
+}
@@ -0,0 +1,79 @@
+package mbin
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func testHeader(guid uint64, name string) []byte {
+	b := make([]byte, HeaderSize+8)
+	binary.LittleEndian.PutUint32(b[0x00:], MagicMBIN)
+	binary.LittleEndian.PutUint32(b[0x04:], 2500)
+	binary.LittleEndian.PutUint64(b[0x10:], guid)
+	copy(b[0x18:], name)
+	return b
+}
+
+func TestParseHeader(t *testing.T) {
+	h, err := ParseHeader(testHeader(0xABCD, "GcProductTable"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.FormatID != 2500 || h.TemplateGUID != 0xABCD || h.TemplateName != "GcProductTable" {
+		t.Fatalf("unexpected header: %+v", h)
+	}
+	if _, err := ParseHeader(make([]byte, HeaderSize)); err != ErrNotMBIN {
+		t.Fatalf("expected ErrNotMBIN, got %v", err)
+	}
+}
+
+func TestCheckDir_FlagsStale(t *testing.T) {
+	game := fstest.MapFS{
+		"METADATA/A.MBIN": {Data: testHeader(1, "GcA")},
+		"METADATA/B.MBIN": {Data: testHeader(2, "GcB")},
+	}
+	mod := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(mod, "METADATA"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string][]byte{
+		"A.MBIN": testHeader(1, "GcA"),
+		"B.MBIN": testHeader(99, "GcB"),
+		"C.MBIN": testHeader(3, "GcC"),
+		"D.MBIN": []byte("not an mbin"),
+	}
+	for n, b := range files {
+		if err := os.WriteFile(filepath.Join(mod, "METADATA", n), b, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reps, err := CheckDir(mod, NewReference(game))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]Status{
+		"METADATA/A.MBIN": StatusOK,
+		"METADATA/B.MBIN": StatusStale,
+		"METADATA/C.MBIN": StatusUnknown,
+		"METADATA/D.MBIN": StatusInvalid,
+	}
+	if len(reps) != len(want) {
+		t.Fatalf("expected %d reports, got %d", len(want), len(reps))
+	}
+	for _, r := range reps {
+		if want[r.Path] != r.Status {
+			t.Fatalf("%s: expected %s, got %s", r.Path, want[r.Path], r.Status)
+		}
+	}
+	var stale []string
+	for _, r := range Stale(reps) {
+		stale = append(stale, r.Path)
+	}
+	if len(stale) != 2 || stale[0] != "METADATA/B.MBIN" || stale[1] != "METADATA/D.MBIN" {
+		t.Fatalf("Stale: %v (want B changed and D unreadable)", stale)
+	}
+}
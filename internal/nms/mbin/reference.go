@@ -0,0 +1,161 @@
+package mbin
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+type Status string
+
+const (
+	StatusOK      Status = "ok"      // same header version as the game's file
+	StatusStale   Status = "stale"   // header differs from the game's file
+	StatusUnknown Status = "unknown" // no matching game file to compare against
+	StatusInvalid Status = "invalid" // not a readable MBIN header
+)
+
+// FileReport describes one MBIN inside a mod folder compared against the game.
+type FileReport struct {
+	Path   string  `json:"path"` // relative to the mod folder, slash separated
+	Status Status  `json:"status"`
+	Mod    *Header `json:"mod,omitempty"`
+	Game   *Header `json:"game,omitempty"`
+	Reason string  `json:"reason,omitempty"`
+}
+
+// Reference looks up the game's own MBIN headers by game-relative path
+// (e.g. METADATA/REALITY/TABLES/NMS_REALITY_GCPRODUCTTABLE.MBIN).
+//
+// Sources are searched in order; the first one containing the path wins.
+type Reference struct {
+	sources []fs.FS
+	cache   map[string]*Header
+}
+
+func NewReference(sources ...fs.FS) *Reference {
+	return &Reference{sources: sources, cache: map[string]*Header{}}
+}
+
+// Empty reports whether the reference has no sources to compare against.
+func (r *Reference) Empty() bool {
+	return r == nil || len(r.sources) == 0
+}
+
+// Lookup returns the game's header for a game-relative path.
+func (r *Reference) Lookup(rel string) (Header, bool) {
+	if r.Empty() {
+		return Header{}, false
+	}
+	rel = strings.TrimPrefix(path.Clean(filepath.ToSlash(rel)), "/")
+	key := strings.ToUpper(rel)
+	if h, ok := r.cache[key]; ok {
+		if h == nil {
+			return Header{}, false
+		}
+		return *h, true
+	}
+	var found *Header
+	for _, src := range r.sources {
+		for _, cand := range []string{rel, key} {
+			f, err := src.Open(cand)
+			if err != nil {
+				continue
+			}
+			h, herr := ReadHeader(f)
+			_ = f.Close()
+			if herr == nil {
+				found = &h
+			}
+			break
+		}
+		if found != nil {
+			break
+		}
+	}
+	r.cache[key] = found
+	if found == nil {
+		return Header{}, false
+	}
+	return *found, true
+}
+
+// Compare classifies a mod header against the game's header for the same file.
+func Compare(mod, game Header) (Status, string) {
+	if mod.TemplateName != game.TemplateName {
+		return StatusStale, "template changed: " + mod.TemplateName + " -> " + game.TemplateName
+	}
+	if mod.FormatID != game.FormatID {
+		return StatusStale, "format id differs"
+	}
+	if mod.TemplateGUID != game.TemplateGUID {
+		return StatusStale, "template guid differs"
+	}
+	return StatusOK, ""
+}
+
+// CheckDir walks a mod folder and compares every .MBIN against the reference.
+// Results are sorted by path.
+func CheckDir(root string, ref *Reference) ([]FileReport, error) {
+	out := []FileReport{}
+	err := filepath.WalkDir(root, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(strings.ToLower(d.Name()), ".mbin") {
+			return nil
+		}
+		rel, rerr := filepath.Rel(root, p)
+		if rerr != nil {
+			return rerr
+		}
+		rep := FileReport{Path: filepath.ToSlash(rel)}
+
+		h, herr := ReadFile(p)
+		if herr != nil {
+			rep.Status = StatusInvalid
+			rep.Reason = herr.Error()
+			out = append(out, rep)
+			return nil
+		}
+		rep.Mod = &h
+
+		gh, ok := ref.Lookup(rep.Path)
+		if !ok {
+			rep.Status = StatusUnknown
+			out = append(out, rep)
+			return nil
+		}
+		rep.Game = &gh
+		rep.Status, rep.Reason = Compare(h, gh)
+		out = append(out, rep)
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+	return out, nil
+}
+
+// IsStale reports whether the game is unlikely to load the file as it is: its header differs
+// from the game's, or it is not a readable MBIN at all. Every command reporting stale MBINs
+// (scan-stale, verify, installed) uses this definition.
+func (r FileReport) IsStale() bool {
+	return r.Status == StatusStale || r.Status == StatusInvalid
+}
+
+// Stale filters reports down to stale entries (see FileReport.IsStale).
+func Stale(reps []FileReport) []FileReport {
+	out := []FileReport{}
+	for _, r := range reps {
+		if r.IsStale() {
+			out = append(out, r)
+		}
+	}
+	return out
+}
@@ -0,0 +1,76 @@
+package nms
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// SteamAppID is the Steam application id of No Man's Sky.
+const SteamAppID = 275850
+
+// GameExecutable is the Windows binary name (run natively or through Proton/Wine).
+const GameExecutable = "NMS.exe"
+
+type Process struct {
+	PID     int    `json:"pid"`
+	Command string `json:"command"`
+}
+
+// RunningGameProcesses scans /proc for processes running NMS.exe.
+// Under Proton the executable shows up as a Windows path in the cmdline (Z:\...\Binaries\NMS.exe),
+// so both comm and every argv element are checked by basename.
+// On systems without /proc it returns no processes.
+func RunningGameProcesses() ([]Process, error) {
+	return runningGameProcesses("/proc")
+}
+
+func runningGameProcesses(procRoot string) ([]Process, error) {
+	ents, err := os.ReadDir(procRoot)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	self := os.Getpid()
+	var out []Process
+	for _, e := range ents {
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil || pid == self {
+			continue
+		}
+		dir := filepath.Join(procRoot, e.Name())
+		comm, _ := os.ReadFile(filepath.Join(dir, "comm"))
+		cmdline, _ := os.ReadFile(filepath.Join(dir, "cmdline"))
+
+		match := isGameExe(strings.TrimSpace(string(comm)))
+		args := bytes.Split(bytes.TrimRight(cmdline, "\x00"), []byte{0})
+		if !match {
+			for _, a := range args {
+				if isGameExe(string(a)) {
+					match = true
+					break
+				}
+			}
+		}
+		if !match {
+			continue
+		}
+		out = append(out, Process{PID: pid, Command: strings.TrimSpace(string(bytes.Join(args, []byte(" "))))})
+	}
+	return out, nil
+}
+
+func isGameExe(s string) bool {
+	if s == "" {
+		return false
+	}
+	s = strings.ReplaceAll(s, "\\", "/")
+	if i := strings.LastIndex(s, "/"); i >= 0 {
+		s = s[i+1:]
+	}
+	return strings.EqualFold(s, GameExecutable)
+}
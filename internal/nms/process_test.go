@@ -0,0 +1,33 @@
+package nms
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunningGameProcesses_MatchesProtonCmdline(t *testing.T) {
+	root := t.TempDir()
+	procs := map[string][2]string{
+		"100": {"NMS.exe", "Z:\\games\\No Man's Sky\\Binaries\\NMS.exe\x00-dx12\x00"},
+		"200": {"wine64-preload", "/usr/bin/wine64\x00C:\\NMS\\Binaries\\nms.exe\x00"},
+		"300": {"bash", "/bin/bash\x00-c\x00echo NMS.exe.bak\x00"},
+		"abc": {"NMS.exe", ""},
+	}
+	for pid, v := range procs {
+		dir := filepath.Join(root, pid)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		_ = os.WriteFile(filepath.Join(dir, "comm"), []byte(v[0]+"\n"), 0o644)
+		_ = os.WriteFile(filepath.Join(dir, "cmdline"), []byte(v[1]), 0o644)
+	}
+
+	got, err := runningGameProcesses(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 matches, got %+v", got)
+	}
+}
@@ -0,0 +1,333 @@
+// Package psarc is a read-only reader for PlayStation ARChive (.pak) files,
+// the container format used by No Man's Sky for GAMEDATA/PCBANKS and PAK-era mods.
+//
+// Layout (big endian):
+//
+//	header (32 bytes): "PSAR", version major/minor, compression ("zlib"|"lzma"),
+//	                   toc length, toc entry size, entry count, block size, flags
+//	toc entries:       md5[16], first block index u32, length u40, offset u40
+//	block size table:  one u16/u24/u32 per block (0 = full uncompressed block)
+//
+// Entry 0 is the manifest: newline-separated paths for entries 1..n-1.
+//
+// Archive implements fs.FS and fs.ReadDirFS, so it can be walked with fs.WalkDir.
+package psarc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+const (
+	headerSize = 32
+
+	// maxBlockSize bounds the header's block size, which sizes every block buffer. Real
+	// archives use 64 KiB.
+	maxBlockSize = 16 << 20
+
+	// Archive flags 1 (ignore case) and 2 (absolute paths) only affect how names are
+	// written; lookups here are normalized and case-insensitive either way.
+	flagEncryptedTOC = 4
+)
+
+var ErrNotPSARC = errors.New("not a PSARC archive (bad magic)")
+
+type header struct {
+	Major        uint16
+	Minor        uint16
+	Compression  string
+	TOCLength    uint32
+	TOCEntrySize uint32
+	Entries      uint32
+	BlockSize    uint32
+	Flags        uint32
+}
+
+type entry struct {
+	blockIndex uint32
+	length     int64
+	offset     int64
+}
+
+// Archive is an opened PSARC file.
+type Archive struct {
+	r      io.ReaderAt
+	closer io.Closer
+	size   int64
+
+	hdr        header
+	entries    []entry // entries[0] is the manifest
+	blockSizes []uint32
+
+	names  []string       // manifest order, normalized (no leading slash)
+	byName map[string]int // normalized and upper-cased names -> entry index
+	dirs   map[string][]string
+}
+
+// Open opens the archive at path. Close releases the file handle.
+func Open(name string) (*Archive, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	a, err := NewReader(f, fi.Size())
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	a.closer = f
+	return a, nil
+}
+
+// NewReader parses the header, TOC and manifest from r, which holds size bytes.
+func NewReader(r io.ReaderAt, size int64) (*Archive, error) {
+	var hb [headerSize]byte
+	if _, err := r.ReadAt(hb[:], 0); err != nil {
+		return nil, err
+	}
+	if string(hb[0:4]) != "PSAR" {
+		return nil, ErrNotPSARC
+	}
+	be := binary.BigEndian
+	h := header{
+		Major:        be.Uint16(hb[4:]),
+		Minor:        be.Uint16(hb[6:]),
+		Compression:  string(hb[8:12]),
+		TOCLength:    be.Uint32(hb[12:]),
+		TOCEntrySize: be.Uint32(hb[16:]),
+		Entries:      be.Uint32(hb[20:]),
+		BlockSize:    be.Uint32(hb[24:]),
+		Flags:        be.Uint32(hb[28:]),
+	}
+	if h.Flags&flagEncryptedTOC != 0 {
+		return nil, errors.New("encrypted PSARC TOC is not supported")
+	}
+	if h.Compression != "zlib" && h.Compression != "lzma" {
+		return nil, fmt.Errorf("unsupported PSARC compression %q", h.Compression)
+	}
+	if h.TOCEntrySize < 30 || h.Entries == 0 || h.BlockSize == 0 {
+		return nil, errors.New("corrupt PSARC header")
+	}
+	if h.BlockSize > maxBlockSize {
+		return nil, fmt.Errorf("corrupt PSARC header (block size %d above %d)", h.BlockSize, maxBlockSize)
+	}
+	entriesEnd := uint64(headerSize) + uint64(h.Entries)*uint64(h.TOCEntrySize)
+	if uint64(h.TOCLength) < entriesEnd {
+		return nil, errors.New("corrupt PSARC header (toc too small)")
+	}
+	// The TOC length sizes an allocation: it must fit in the archive.
+	if int64(h.TOCLength) > size {
+		return nil, errors.New("corrupt PSARC header (toc larger than the archive)")
+	}
+
+	toc := make([]byte, int(h.TOCLength)-headerSize)
+	if _, err := r.ReadAt(toc, headerSize); err != nil {
+		return nil, fmt.Errorf("read toc: %w", err)
+	}
+
+	a := &Archive{r: r, size: size, hdr: h, byName: map[string]int{}, dirs: map[string][]string{".": {}}}
+	for i := 0; i < int(h.Entries); i++ {
+		e := toc[i*int(h.TOCEntrySize):]
+		a.entries = append(a.entries, entry{
+			blockIndex: be.Uint32(e[16:]),
+			length:     int64(uint40(e[20:])),
+			offset:     int64(uint40(e[25:])),
+		})
+	}
+
+	width := blockSizeWidth(h.BlockSize)
+	table := toc[int(entriesEnd)-headerSize:]
+	for i := 0; i+width <= len(table); i += width {
+		var v uint32
+		for _, b := range table[i : i+width] {
+			v = v<<8 | uint32(b)
+		}
+		a.blockSizes = append(a.blockSizes, v)
+	}
+
+	manifest, err := a.readEntry(0)
+	if err != nil {
+		return nil, fmt.Errorf("read manifest: %w", err)
+	}
+	a.indexManifest(manifest)
+	return a, nil
+}
+
+func uint40(b []byte) uint64 {
+	return uint64(b[0])<<32 | uint64(b[1])<<24 | uint64(b[2])<<16 | uint64(b[3])<<8 | uint64(b[4])
+}
+
+func blockSizeWidth(blockSize uint32) int {
+	switch {
+	case blockSize <= 1<<16:
+		return 2
+	case blockSize <= 1<<24:
+		return 3
+	default:
+		return 4
+	}
+}
+
+func (a *Archive) indexManifest(manifest []byte) {
+	lines := strings.Split(strings.ReplaceAll(string(manifest), "\r", ""), "\n")
+	for i, n := range lines {
+		idx := i + 1
+		if idx >= len(a.entries) {
+			break
+		}
+		n = normalizeName(n)
+		if n == "" {
+			continue
+		}
+		a.names = append(a.names, n)
+		a.byName[n] = idx
+		if _, ok := a.byName[strings.ToUpper(n)]; !ok {
+			a.byName[strings.ToUpper(n)] = idx
+		}
+		// Register parent directories for ReadDir/WalkDir.
+		child := n
+		for {
+			dir := path.Dir(child)
+			a.dirs[dir] = append(a.dirs[dir], path.Base(child))
+			if dir == "." || len(a.dirs[dir]) > 1 {
+				// Root reached, or dir already existed (its ancestors are registered).
+				break
+			}
+			child = dir
+		}
+	}
+	for d, kids := range a.dirs {
+		sort.Strings(kids)
+		a.dirs[d] = dedupeSorted(kids)
+	}
+}
+
+func normalizeName(n string) string {
+	n = strings.TrimSpace(strings.ReplaceAll(n, "\\", "/"))
+	n = strings.TrimLeft(n, "/")
+	if n == "" {
+		return ""
+	}
+	n = path.Clean(n)
+	if !fs.ValidPath(n) || n == "." {
+		return ""
+	}
+	return n
+}
+
+func dedupeSorted(in []string) []string {
+	out := in[:0]
+	for i, s := range in {
+		if i == 0 || s != in[i-1] {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// Close releases the underlying file if the archive was opened with Open.
+func (a *Archive) Close() error {
+	if a.closer == nil {
+		return nil
+	}
+	return a.closer.Close()
+}
+
+// Compression returns "zlib" or "lzma".
+func (a *Archive) Compression() string { return a.hdr.Compression }
+
+// Files returns the archive paths in manifest order (no leading slash).
+func (a *Archive) Files() []string {
+	out := make([]string, len(a.names))
+	copy(out, a.names)
+	return out
+}
+
+// Size returns the uncompressed size of a file, if present.
+func (a *Archive) Size(name string) (int64, bool) {
+	idx, ok := a.lookup(normalizeName(name))
+	if !ok {
+		return 0, false
+	}
+	return a.entries[idx].length, true
+}
+
+// lookup finds an entry by (already normalized) name, falling back to upper case.
+func (a *Archive) lookup(n string) (int, bool) {
+	if idx, ok := a.byName[n]; ok {
+		return idx, true
+	}
+	idx, ok := a.byName[strings.ToUpper(n)]
+	return idx, ok
+}
+
+func (a *Archive) readEntry(idx int) ([]byte, error) {
+	rd := a.entryReader(idx)
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, rd); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Open implements fs.FS.
+func (a *Archive) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if idx, ok := a.lookup(name); ok {
+		return &file{
+			info:   fileInfo{name: path.Base(name), size: a.entries[idx].length},
+			reader: a.entryReader(idx),
+		}, nil
+	}
+	if kids, ok := a.dirs[name]; ok {
+		return &dir{a: a, name: name, kids: kids}, nil
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+// ReadDir implements fs.ReadDirFS.
+func (a *Archive) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+	kids, ok := a.dirs[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	return a.dirEntries(name, kids), nil
+}
+
+func (a *Archive) dirEntries(name string, kids []string) []fs.DirEntry {
+	out := make([]fs.DirEntry, 0, len(kids))
+	for _, k := range kids {
+		full := k
+		if name != "." {
+			full = name + "/" + k
+		}
+		if _, isDir := a.dirs[full]; isDir {
+			out = append(out, fs.FileInfoToDirEntry(fileInfo{name: k, dir: true}))
+			continue
+		}
+		var size int64
+		if idx, ok := a.byName[full]; ok {
+			size = a.entries[idx].length
+		}
+		out = append(out, fs.FileInfoToDirEntry(fileInfo{name: k, size: size}))
+	}
+	return out
+}
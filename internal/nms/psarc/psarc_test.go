@@ -0,0 +1,197 @@
+package psarc
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// buildPSARC writes a minimal zlib PSARC with the given files (in order).
+func buildPSARC(t *testing.T, blockSize int, names []string, contents map[string][]byte) []byte {
+	t.Helper()
+
+	payloads := [][]byte{[]byte(strings.Join(names, "\n"))}
+	for _, n := range names {
+		payloads = append(payloads, contents[n])
+	}
+
+	type tocEntry struct {
+		block  uint32
+		length uint64
+		offset uint64
+	}
+	var entries []tocEntry
+	var blockSizes []uint16
+	var data bytes.Buffer
+	for _, p := range payloads {
+		e := tocEntry{block: uint32(len(blockSizes)), length: uint64(len(p)), offset: uint64(data.Len())}
+		for off := 0; off < len(p); off += blockSize {
+			end := off + blockSize
+			if end > len(p) {
+				end = len(p)
+			}
+			chunk := p[off:end]
+			var z bytes.Buffer
+			zw := zlib.NewWriter(&z)
+			_, _ = zw.Write(chunk)
+			_ = zw.Close()
+			if z.Len() >= len(chunk) {
+				// Store uncompressed, like real writers do.
+				data.Write(chunk)
+				if len(chunk) == blockSize {
+					blockSizes = append(blockSizes, 0)
+				} else {
+					blockSizes = append(blockSizes, uint16(len(chunk)))
+				}
+				continue
+			}
+			data.Write(z.Bytes())
+			blockSizes = append(blockSizes, uint16(z.Len()))
+		}
+		entries = append(entries, e)
+	}
+
+	tocLen := headerSize + len(entries)*30 + len(blockSizes)*2
+	var out bytes.Buffer
+	be := binary.BigEndian
+	hdr := make([]byte, headerSize)
+	copy(hdr, "PSAR")
+	be.PutUint16(hdr[4:], 1)
+	be.PutUint16(hdr[6:], 4)
+	copy(hdr[8:], "zlib")
+	be.PutUint32(hdr[12:], uint32(tocLen))
+	be.PutUint32(hdr[16:], 30)
+	be.PutUint32(hdr[20:], uint32(len(entries)))
+	be.PutUint32(hdr[24:], uint32(blockSize))
+	be.PutUint32(hdr[28:], 2)
+	out.Write(hdr)
+	for _, e := range entries {
+		b := make([]byte, 30)
+		be.PutUint32(b[16:], e.block)
+		put40 := func(dst []byte, v uint64) {
+			for i := 4; i >= 0; i-- {
+				dst[i] = byte(v)
+				v >>= 8
+			}
+		}
+		put40(b[20:], e.length)
+		put40(b[25:], e.offset+uint64(tocLen))
+		out.Write(b)
+	}
+	for _, s := range blockSizes {
+		_ = binary.Write(&out, be, s)
+	}
+	out.Write(data.Bytes())
+	return out.Bytes()
+}
+
+func TestArchive_ReadAndWalk(t *testing.T) {
+	big := bytes.Repeat([]byte("NMS-MBIN-PAYLOAD-"), 40) // spans several blocks
+	contents := map[string][]byte{
+		"/METADATA/REALITY/TABLES/PRODUCTS.MBIN": big,
+		"/METADATA/GAMESTATE/X.MBIN":             []byte("x"),
+		"/TEXTURES/A.DDS":                        []byte("0123456789abcdef"), // stored block
+	}
+	names := []string{"/METADATA/REALITY/TABLES/PRODUCTS.MBIN", "/METADATA/GAMESTATE/X.MBIN", "/TEXTURES/A.DDS"}
+	raw := buildPSARC(t, 16, names, contents)
+
+	a, err := NewReader(bytes.NewReader(raw), int64(len(raw)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := a.Files(); len(got) != 3 || got[0] != "METADATA/REALITY/TABLES/PRODUCTS.MBIN" {
+		t.Fatalf("unexpected files: %v", got)
+	}
+
+	f, err := a.Open("metadata/reality/tables/products.mbin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, big) {
+		t.Fatalf("content mismatch: got %d bytes", len(b))
+	}
+
+	if err := fstest.TestFS(a, "METADATA/GAMESTATE/X.MBIN", "TEXTURES/A.DDS"); err != nil {
+		t.Fatal(err)
+	}
+
+	n := 0
+	_ = fs.WalkDir(a, ".", func(p string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			n++
+		}
+		return err
+	})
+	if n != 3 {
+		t.Fatalf("expected 3 files from WalkDir, got %d", n)
+	}
+}
+
+func TestNewReader_RejectsNonPSARC(t *testing.T) {
+	if _, err := NewReader(bytes.NewReader(make([]byte, 64)), 64); err != ErrNotPSARC {
+		t.Fatalf("expected ErrNotPSARC, got %v", err)
+	}
+}
+
+func TestNewReader_RejectsBadTOCLength(t *testing.T) {
+	raw := buildPSARC(t, 64, []string{"A.MBIN"}, map[string][]byte{"A.MBIN": []byte("x")})
+	for name, tocLen := range map[string]uint32{
+		"below header":    headerSize - 1,
+		"beyond the file": uint32(len(raw)) + 1,
+		"about 4 GiB":     0xFFFFFFF0,
+	} {
+		b := bytes.Clone(raw)
+		binary.BigEndian.PutUint32(b[12:], tocLen)
+		if _, err := NewReader(bytes.NewReader(b), int64(len(b))); err == nil {
+			t.Errorf("%s: accepted TOC length %d", name, tocLen)
+		}
+	}
+}
+
+func TestNewReader_RejectsHugeBlockSize(t *testing.T) {
+	raw := buildPSARC(t, 64, []string{"A.MBIN"}, map[string][]byte{"A.MBIN": []byte("x")})
+	binary.BigEndian.PutUint32(raw[24:], maxBlockSize+1)
+	if _, err := NewReader(bytes.NewReader(raw), int64(len(raw))); err == nil || !strings.Contains(err.Error(), "block size") {
+		t.Fatalf("accepted block size %d: %v", maxBlockSize+1, err)
+	}
+}
+
+func TestArchive_RejectsBadBlockSizes(t *testing.T) {
+	big := bytes.Repeat([]byte("NMS-MBIN-PAYLOAD-"), 40)
+	raw := buildPSARC(t, 64, []string{"A.MBIN"}, map[string][]byte{"A.MBIN": big})
+	tocLen := int(binary.BigEndian.Uint32(raw[12:]))
+	// The block-size table follows the two 30-byte TOC entries; its first entry is the manifest's.
+	table := headerSize + 2*30
+
+	b := bytes.Clone(raw)
+	binary.BigEndian.PutUint16(b[table:], 0xFFFF)
+	if _, err := NewReader(bytes.NewReader(b), int64(len(b))); err == nil || !strings.Contains(err.Error(), "above the block size") {
+		t.Fatalf("accepted a compressed block larger than the block size: %v", err)
+	}
+
+	// A truncated archive: the file's last block runs past the end.
+	b = raw[:len(raw)-4]
+	if len(b) <= tocLen {
+		t.Fatal("archive too small to truncate")
+	}
+	a, err := NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := a.Open("A.MBIN")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.ReadAll(f); err == nil || !strings.Contains(err.Error(), "past the end") {
+		t.Fatalf("read past the end of the archive: %v", err)
+	}
+}
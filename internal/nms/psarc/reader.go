@@ -0,0 +1,192 @@
+package psarc
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"time"
+
+	"github.com/ulikunitz/xz/lzma"
+)
+
+// blockReader decompresses an entry one block at a time, so reading a small
+// prefix (e.g. an MBIN header) does not inflate the whole file.
+type blockReader struct {
+	a         *Archive
+	block     uint32
+	offset    int64
+	remaining int64
+	buf       []byte
+	err       error
+}
+
+func (a *Archive) entryReader(idx int) *blockReader {
+	e := a.entries[idx]
+	return &blockReader{a: a, block: e.blockIndex, offset: e.offset, remaining: e.length}
+}
+
+func (br *blockReader) Read(p []byte) (int, error) {
+	for len(br.buf) == 0 {
+		if br.err != nil {
+			return 0, br.err
+		}
+		if br.remaining <= 0 {
+			return 0, io.EOF
+		}
+		br.buf, br.err = br.next()
+		if br.err != nil && len(br.buf) == 0 {
+			return 0, br.err
+		}
+	}
+	n := copy(p, br.buf)
+	br.buf = br.buf[n:]
+	return n, nil
+}
+
+func (br *blockReader) next() ([]byte, error) {
+	a := br.a
+	if int(br.block) >= len(a.blockSizes) {
+		return nil, fmt.Errorf("psarc: block index %d out of range", br.block)
+	}
+	want := int64(a.hdr.BlockSize)
+	if br.remaining < want {
+		want = br.remaining
+	}
+	zsize := int64(a.blockSizes[br.block])
+	if zsize == 0 {
+		zsize = int64(a.hdr.BlockSize)
+	}
+	// Both sizes come from the archive and size the buffer below.
+	if zsize > int64(a.hdr.BlockSize) {
+		return nil, fmt.Errorf("psarc: block %d: compressed size %d above the block size %d", br.block, zsize, a.hdr.BlockSize)
+	}
+	if br.offset < 0 || zsize > a.size-br.offset {
+		return nil, fmt.Errorf("psarc: block %d: %d bytes at offset %d run past the end of the archive", br.block, zsize, br.offset)
+	}
+	raw := make([]byte, zsize)
+	if _, err := a.r.ReadAt(raw, br.offset); err != nil && err != io.EOF {
+		return nil, err
+	}
+	br.offset += zsize
+	br.block++
+
+	var out []byte
+	if zsize == want {
+		// Stored block (compression did not help).
+		out = raw
+	} else {
+		var err error
+		out, err = a.inflate(raw, want)
+		if err != nil {
+			return nil, fmt.Errorf("psarc: block %d: %w", br.block-1, err)
+		}
+	}
+	if int64(len(out)) > want {
+		out = out[:want]
+	}
+	br.remaining -= int64(len(out))
+	if len(out) == 0 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return out, nil
+}
+
+func (a *Archive) inflate(raw []byte, want int64) ([]byte, error) {
+	var rd io.Reader
+	switch {
+	case isZlib(raw):
+		zr, err := zlib.NewReader(bytes.NewReader(raw))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		rd = zr
+	case a.hdr.Compression == "lzma":
+		lr, err := lzma.NewReader(bytes.NewReader(raw))
+		if err != nil {
+			return nil, err
+		}
+		rd = lr
+	default:
+		// Some writers store blocks uncompressed even when sizes differ slightly.
+		return raw, nil
+	}
+	out := make([]byte, 0, want)
+	buf := bytes.NewBuffer(out)
+	if _, err := io.Copy(buf, io.LimitReader(rd, want)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func isZlib(b []byte) bool {
+	if len(b) < 2 || b[0]&0x0f != 8 {
+		return false
+	}
+	return (uint16(b[0])<<8|uint16(b[1]))%31 == 0
+}
+
+type fileInfo struct {
+	name string
+	size int64
+	dir  bool
+}
+
+func (fi fileInfo) Name() string { return fi.name }
+func (fi fileInfo) Size() int64  { return fi.size }
+func (fi fileInfo) Mode() fs.FileMode {
+	if fi.dir {
+		return fs.ModeDir | 0o555
+	}
+	return 0o444
+}
+func (fi fileInfo) ModTime() time.Time { return time.Time{} }
+func (fi fileInfo) IsDir() bool        { return fi.dir }
+func (fi fileInfo) Sys() any           { return nil }
+
+type file struct {
+	info   fileInfo
+	reader io.Reader
+}
+
+func (f *file) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *file) Read(p []byte) (int, error) { return f.reader.Read(p) }
+func (f *file) Close() error               { return nil }
+
+type dir struct {
+	a    *Archive
+	name string
+	kids []string
+	pos  int
+}
+
+func (d *dir) Stat() (fs.FileInfo, error) {
+	return fileInfo{name: path.Base(d.name), dir: true}, nil
+}
+
+func (d *dir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: fs.ErrInvalid}
+}
+
+func (d *dir) Close() error { return nil }
+
+// ReadDir implements fs.ReadDirFile.
+func (d *dir) ReadDir(n int) ([]fs.DirEntry, error) {
+	all := d.a.dirEntries(d.name, d.kids)
+	rest := all[d.pos:]
+	if n <= 0 {
+		d.pos = len(all)
+		return rest, nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(rest) {
+		n = len(rest)
+	}
+	d.pos += n
+	return rest[:n], nil
+}
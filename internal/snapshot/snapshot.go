@@ -0,0 +1,410 @@
+// Package snapshot records the contents of GAMEDATA/MODS so it can be restored later.
+//
+// File contents live in a content-addressed object store shared by every snapshot
+// (<root>/snapshots/objects/<sha256[:2]>/<sha256>), so taking another snapshot of an unchanged
+// MODS directory only costs a small JSON manifest.
+package snapshot
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"nmsmods/internal/mods"
+)
+
+// managedMarkerFile mirrors the marker written by mods.Deploy; it is re-created on restore.
+const managedMarkerFile = ".nmsmods.managed.json"
+
+// ErrNotFound is returned for unknown snapshot names.
+var ErrNotFound = errors.New("snapshot not found")
+
+var nameRe = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]{0,63}$`)
+
+// ValidateName ensures a snapshot name is safe to use as a file name.
+func ValidateName(name string) error {
+	if !nameRe.MatchString(name) {
+		return fmt.Errorf("invalid snapshot name %q (allowed: letters, digits, ., _, -, max 64 chars)", name)
+	}
+	return nil
+}
+
+type File struct {
+	Path   string `json:"path"` // slash separated, relative to the folder
+	SHA256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+}
+
+// Folder is one top-level folder of GAMEDATA/MODS.
+type Folder struct {
+	Folder  string `json:"folder"`
+	Managed bool   `json:"managed"`
+	ModID   string `json:"mod_id,omitempty"`
+	Profile string `json:"profile,omitempty"`
+	Hash    string `json:"hash"` // see TreeHash
+	Files   []File `json:"files"`
+}
+
+// Snapshot is the manifest stored as <root>/snapshots/<name>.json.
+type Snapshot struct {
+	Name      string `json:"name"`
+	CreatedAt string `json:"created_at"`
+
+	GamePath      string `json:"game_path"`
+	ActiveProfile string `json:"active_profile"`
+	ModsDisabled  bool   `json:"mods_disabled,omitempty"`
+
+	// StateSHA256 identifies the state revision (rendered as state.json) at snapshot time; it is
+	// kept in the object store under the same hash.
+	StateSHA256 string `json:"state_sha256,omitempty"`
+
+	IncludesExternal bool     `json:"includes_external"`
+	Folders          []Folder `json:"folders"`
+}
+
+// Store manages snapshot manifests and the shared object store below Dir.
+type Store struct {
+	Dir string
+}
+
+func (s Store) manifestPath(name string) string { return filepath.Join(s.Dir, name+".json") }
+func (s Store) objectsDir() string              { return filepath.Join(s.Dir, "objects") }
+
+// ObjectPath returns where content with the given hash is kept.
+func (s Store) ObjectPath(sum string) string {
+	if len(sum) < 2 {
+		return filepath.Join(s.objectsDir(), sum)
+	}
+	return filepath.Join(s.objectsDir(), sum[:2], sum)
+}
+
+// PutBytes stores b in the object store and returns its hash.
+func (s Store) PutBytes(b []byte) (string, error) {
+	sum := sha256.Sum256(b)
+	hexSum := hex.EncodeToString(sum[:])
+	dst := s.ObjectPath(hexSum)
+	if _, err := os.Stat(dst); err == nil {
+		return hexSum, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return "", err
+	}
+	tmp := dst + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return "", err
+	}
+	return hexSum, os.Rename(tmp, dst)
+}
+
+// PutFile copies src into the object store (if not already present) and returns its hash.
+func (s Store) PutFile(src string) (string, int64, error) {
+	f, err := os.Open(src)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+
+	if err := os.MkdirAll(s.objectsDir(), 0o755); err != nil {
+		return "", 0, err
+	}
+	tmp, err := os.CreateTemp(s.objectsDir(), ".obj-*")
+	if err != nil {
+		return "", 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(tmp, h), f)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "", 0, err
+	}
+	sum := hex.EncodeToString(h.Sum(nil))
+	dst := s.ObjectPath(sum)
+	if _, err := os.Stat(dst); err == nil {
+		return sum, n, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return "", 0, err
+	}
+	if err := os.Rename(tmp.Name(), dst); err != nil {
+		return "", 0, err
+	}
+	return sum, n, nil
+}
+
+// TreeHash hashes a folder's sorted (path, content hash) list; equal trees hash equal.
+func TreeHash(files []File) string {
+	sorted := append([]File(nil), files...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Path < sorted[j].Path })
+	h := sha256.New()
+	for _, f := range sorted {
+		fmt.Fprintf(h, "%s\x00%s\n", f.Path, f.SHA256)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// HashFolder computes the TreeHash of dir without touching the object store.
+func HashFolder(dir string) (string, error) {
+	files := []File{}
+	err := filepath.WalkDir(dir, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == managedMarkerFile {
+			return nil
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		h := sha256.New()
+		_, err = io.Copy(h, f)
+		_ = f.Close()
+		if err != nil {
+			return err
+		}
+		files = append(files, File{Path: rel, SHA256: hex.EncodeToString(h.Sum(nil))})
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return TreeHash(files), nil
+}
+
+// CaptureFolder stores every file of dir (except the managed marker) and returns the manifest.
+func (s Store) CaptureFolder(dir string) (Folder, error) {
+	out := Folder{Folder: filepath.Base(dir), Files: []File{}}
+	err := filepath.WalkDir(dir, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == managedMarkerFile || !d.Type().IsRegular() {
+			return nil
+		}
+		sum, n, err := s.PutFile(p)
+		if err != nil {
+			return err
+		}
+		out.Files = append(out.Files, File{Path: rel, SHA256: sum, Size: n})
+		return nil
+	})
+	if err != nil {
+		return Folder{}, err
+	}
+	sort.Slice(out.Files, func(i, j int) bool { return out.Files[i].Path < out.Files[j].Path })
+	out.Hash = TreeHash(out.Files)
+	return out, nil
+}
+
+// Capture records the folders of modsDir. Managed folders (with a deploy marker) are always
+// captured; unmanaged ones only with includeExternal. Hidden entries (deploy temp dirs) are skipped.
+func (s Store) Capture(modsDir string, includeExternal bool) ([]Folder, error) {
+	ents, err := os.ReadDir(modsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Folder{}, nil
+		}
+		return nil, err
+	}
+	out := []Folder{}
+	for _, e := range ents {
+		if !e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		dir := filepath.Join(modsDir, e.Name())
+		m, merr := mods.ReadManagedMarker(dir)
+		managed := merr == nil && m.Tag != ""
+		if !managed && !includeExternal {
+			continue
+		}
+		f, err := s.CaptureFolder(dir)
+		if err != nil {
+			return nil, fmt.Errorf("capture %s: %w", e.Name(), err)
+		}
+		f.Managed = managed
+		if managed {
+			f.ModID = m.ModID
+			f.Profile = m.Profile
+		}
+		out = append(out, f)
+	}
+	return out, nil
+}
+
+// Materialize writes a captured folder's files into dst (which must not exist).
+func (s Store) Materialize(f Folder, dst string) error {
+	if _, err := os.Stat(dst); err == nil {
+		return fmt.Errorf("destination exists: %s", dst)
+	}
+	for _, file := range f.Files {
+		target := filepath.Join(dst, filepath.FromSlash(file.Path))
+		if rel, err := filepath.Rel(dst, target); err != nil || strings.HasPrefix(rel, "..") {
+			return fmt.Errorf("invalid path in snapshot: %s", file.Path)
+		}
+		if err := copyObject(s.ObjectPath(file.SHA256), target); err != nil {
+			return fmt.Errorf("restore %s/%s: %w", f.Folder, file.Path, err)
+		}
+	}
+	return os.MkdirAll(dst, 0o755)
+}
+
+func copyObject(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// Save writes the manifest atomically.
+func (s Store) Save(snap Snapshot) error {
+	if err := ValidateName(snap.Name); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.manifestPath(snap.Name) + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.manifestPath(snap.Name))
+}
+
+func (s Store) Exists(name string) bool {
+	_, err := os.Stat(s.manifestPath(name))
+	return err == nil
+}
+
+func (s Store) Load(name string) (Snapshot, error) {
+	if err := ValidateName(name); err != nil {
+		return Snapshot{}, err
+	}
+	b, err := os.ReadFile(s.manifestPath(name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Snapshot{}, fmt.Errorf("%w: %s", ErrNotFound, name)
+		}
+		return Snapshot{}, err
+	}
+	var snap Snapshot
+	if err := json.Unmarshal(b, &snap); err != nil {
+		return Snapshot{}, fmt.Errorf("snapshot %s: %w", name, err)
+	}
+	return snap, nil
+}
+
+// List returns every snapshot, oldest first.
+func (s Store) List() ([]Snapshot, error) {
+	ents, err := os.ReadDir(s.Dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Snapshot{}, nil
+		}
+		return nil, err
+	}
+	out := []Snapshot{}
+	for _, e := range ents {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		snap, err := s.Load(strings.TrimSuffix(e.Name(), ".json"))
+		if err != nil {
+			continue
+		}
+		out = append(out, snap)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt < out[j].CreatedAt })
+	return out, nil
+}
+
+// Delete removes a manifest and garbage-collects objects no other snapshot references.
+// It returns the number of objects removed.
+func (s Store) Delete(name string) (int, error) {
+	if _, err := s.Load(name); err != nil {
+		return 0, err
+	}
+	if err := os.Remove(s.manifestPath(name)); err != nil {
+		return 0, err
+	}
+	return s.GC()
+}
+
+// GC removes objects not referenced by any snapshot.
+func (s Store) GC() (int, error) {
+	snaps, err := s.List()
+	if err != nil {
+		return 0, err
+	}
+	keep := map[string]bool{}
+	for _, snap := range snaps {
+		keep[snap.StateSHA256] = true
+		for _, f := range snap.Folders {
+			for _, file := range f.Files {
+				keep[file.SHA256] = true
+			}
+		}
+	}
+	removed := 0
+	err = filepath.WalkDir(s.objectsDir(), func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || keep[d.Name()] {
+			return nil
+		}
+		if err := os.Remove(p); err != nil {
+			return err
+		}
+		removed++
+		return nil
+	})
+	return removed, err
+}
@@ -0,0 +1,71 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, p, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCaptureMaterializeAndGC(t *testing.T) {
+	root := t.TempDir()
+	modsDir := filepath.Join(root, "MODS")
+	writeFile(t, filepath.Join(modsDir, "managed", ".nmsmods.managed.json"), `{"tag":"nmsmods:a:default","mod_id":"a","profile":"default"}`)
+	writeFile(t, filepath.Join(modsDir, "managed", "METADATA", "X.MBIN"), "same")
+	writeFile(t, filepath.Join(modsDir, "external", "TEXTURES", "Y.DDS"), "same")
+
+	s := Store{Dir: filepath.Join(root, "snapshots")}
+	folders, err := s.Capture(modsDir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(folders) != 1 || folders[0].ModID != "a" || len(folders[0].Files) != 1 {
+		t.Fatalf("managed-only capture: %+v", folders)
+	}
+
+	folders, err = s.Capture(modsDir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(folders) != 2 {
+		t.Fatalf("expected 2 folders, got %+v", folders)
+	}
+	if err := s.Save(Snapshot{Name: "one", Folders: folders}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Identical content is stored once.
+	obj := s.ObjectPath(folders[0].Files[0].SHA256)
+	if _, err := os.Stat(obj); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := filepath.Join(root, "restored")
+	if err := s.Materialize(folders[0], dst); err != nil {
+		t.Fatal(err)
+	}
+	h, err := HashFolder(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h != folders[0].Hash {
+		t.Fatalf("hash mismatch after materialize")
+	}
+
+	n, err := s.Delete("one")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Fatalf("expected 1 object collected, got %d", n)
+	}
+}
@@ -7,6 +7,22 @@ import (
 	"strings"
 )
 
+// Root is a Steam installation directory and the flavour of Steam it belongs to.
+type Root struct {
+	Label string // steam, steam-flatpak, steam-snap
+	Path  string
+}
+
+// KnownRoots lists the Steam roots nmsmods probes below home (native, Flatpak and Snap).
+func KnownRoots(home string) []Root {
+	return []Root{
+		{Label: "steam", Path: filepath.Join(home, ".local", "share", "Steam")},
+		{Label: "steam", Path: filepath.Join(home, ".steam", "steam")},
+		{Label: "steam-flatpak", Path: filepath.Join(home, ".var", "app", "com.valvesoftware.Steam", ".local", "share", "Steam")},
+		{Label: "steam-snap", Path: filepath.Join(home, "snap", "steam", "common", ".local", "share", "Steam")},
+	}
+}
+
 // GuessNMSPaths tries to locate No Man's Sky on Linux Steam installations.
 //
 // It checks common Steam roots and also parses steamapps/libraryfolders.vdf
@@ -17,26 +33,30 @@ func GuessNMSPaths() ([]string, error) {
 		return nil, err
 	}
 
-	steamRoots := []string{
-		filepath.Join(home, ".local", "share", "Steam"),
-		filepath.Join(home, ".steam", "steam"),
-		filepath.Join(home, ".var", "app", "com.valvesoftware.Steam", ".local", "share", "Steam"),
-	}
-
-	libs := map[string]struct{}{}
-	for _, root := range steamRoots {
-		if st, err := os.Stat(root); err == nil && st.IsDir() {
-			libs[root] = struct{}{}
-			for _, lp := range readLibraryFolders(root) {
-				libs[lp] = struct{}{}
+	out := []string{}
+	seen := map[string]struct{}{}
+	for _, root := range KnownRoots(home) {
+		for _, cand := range FindInRoot(root.Path) {
+			if _, ok := seen[cand]; !ok {
+				seen[cand] = struct{}{}
+				out = append(out, cand)
 			}
 		}
 	}
+	return out, nil
+}
+
+// FindInRoot returns No Man's Sky directories found in steamRoot and in every library
+// listed in its steamapps/libraryfolders.vdf.
+func FindInRoot(steamRoot string) []string {
+	if st, err := os.Stat(steamRoot); err != nil || !st.IsDir() {
+		return nil
+	}
+	libs := append([]string{steamRoot}, readLibraryFolders(steamRoot)...)
 
-	// Probe each library for the game.
 	out := []string{}
 	seen := map[string]struct{}{}
-	for lib := range libs {
+	for _, lib := range libs {
 		cand := filepath.Join(lib, "steamapps", "common", "No Man's Sky")
 		if st, err := os.Stat(cand); err == nil && st.IsDir() {
 			cand = filepath.Clean(cand)
@@ -46,8 +66,7 @@ func GuessNMSPaths() ([]string, error) {
 			}
 		}
 	}
-
-	return out, nil
+	return out
 }
 
 func readLibraryFolders(steamRoot string) []string {
@@ -0,0 +1,82 @@
+package tui
+
+import "unicode/utf8"
+
+// KeyCode names the non-printable keys the UI uses; printable input is KeyRune.
+type KeyCode int
+
+const (
+	KeyRune KeyCode = iota
+	KeyUp
+	KeyDown
+	KeyPgUp
+	KeyPgDn
+	KeyHome
+	KeyEnd
+	KeyEnter
+	KeyEsc
+	KeyBackspace
+	KeyTab
+	KeyCtrlC
+)
+
+// Key is one decoded keypress.
+type Key struct {
+	Code KeyCode
+	Rune rune // set for KeyRune
+}
+
+// escSeqs maps the CSI/SS3 sequences common terminals send for navigation keys.
+var escSeqs = map[string]KeyCode{
+	"[A": KeyUp, "[B": KeyDown, "OA": KeyUp, "OB": KeyDown,
+	"[5~": KeyPgUp, "[6~": KeyPgDn,
+	"[H": KeyHome, "[F": KeyEnd, "OH": KeyHome, "OF": KeyEnd,
+	"[1~": KeyHome, "[4~": KeyEnd, "[7~": KeyHome, "[8~": KeyEnd,
+}
+
+// ParseKeys decodes one read from a raw-mode terminal. Unknown escape sequences are dropped;
+// a lone ESC is KeyEsc.
+func ParseKeys(b []byte) []Key {
+	var out []Key
+	for len(b) > 0 {
+		switch c := b[0]; {
+		case c == 0x1b:
+			if len(b) == 1 || (b[1] != '[' && b[1] != 'O') {
+				out = append(out, Key{Code: KeyEsc})
+				b = b[1:]
+				continue
+			}
+			// A sequence ends at the first byte in 0x40..0x7e after the introducer.
+			end := 2
+			for end < len(b) && (b[end] < 0x40 || b[end] > 0x7e) {
+				end++
+			}
+			if end >= len(b) {
+				return out
+			}
+			if code, ok := escSeqs[string(b[1:end+1])]; ok {
+				out = append(out, Key{Code: code})
+			}
+			b = b[end+1:]
+		case c == '\r' || c == '\n':
+			out = append(out, Key{Code: KeyEnter})
+			b = b[1:]
+		case c == 0x7f || c == 0x08:
+			out = append(out, Key{Code: KeyBackspace})
+			b = b[1:]
+		case c == '\t':
+			out = append(out, Key{Code: KeyTab})
+			b = b[1:]
+		case c == 0x03:
+			out = append(out, Key{Code: KeyCtrlC})
+			b = b[1:]
+		case c < 0x20:
+			b = b[1:]
+		default:
+			r, size := utf8.DecodeRune(b)
+			out = append(out, Key{Code: KeyRune, Rune: r})
+			b = b[size:]
+		}
+	}
+	return out
+}
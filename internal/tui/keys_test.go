@@ -0,0 +1,37 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseKeys(t *testing.T) {
+	got := ParseKeys([]byte("j\x1b[A\x1b[6~\x1b\rK\x1b[99Xé"))
+	want := []Key{
+		{Code: KeyRune, Rune: 'j'},
+		{Code: KeyUp},
+		{Code: KeyPgDn},
+		{Code: KeyEsc},
+		{Code: KeyEnter},
+		{Code: KeyRune, Rune: 'K'},
+		{Code: KeyRune, Rune: 'é'},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestFitAndPad(t *testing.T) {
+	if got := Fit("abcdef", 3); got != "abc" {
+		t.Fatalf("Fit plain: %q", got)
+	}
+	if got := Fit(Reverse+"ab", 4); got != Reverse+"ab  "+reset {
+		t.Fatalf("Fit styled: %q", got)
+	}
+	if got := Pad("abcdef", 4); got != "abc…" {
+		t.Fatalf("Pad cut: %q", got)
+	}
+	if got := Pad("ab", 4); got != "ab  " {
+		t.Fatalf("Pad fill: %q", got)
+	}
+}
@@ -0,0 +1,155 @@
+// Package tui is the small terminal layer behind `nmsmods tui`: raw mode, key decoding and
+// full-screen redraws with ANSI escapes. It holds no mod logic.
+package tui
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/sys/unix"
+)
+
+// ErrNotTerminal is returned by Open when stdin or stdout is not a terminal.
+var ErrNotTerminal = errors.New("not a terminal")
+
+// IsTerminal reports whether f is a terminal.
+func IsTerminal(f *os.File) bool {
+	_, err := unix.IoctlGetTermios(int(f.Fd()), unix.TCGETS)
+	return err == nil
+}
+
+// Terminal is stdin/stdout switched to raw mode and the alternate screen.
+type Terminal struct {
+	in   *os.File
+	out  *bufio.Writer
+	fd   int
+	orig unix.Termios
+}
+
+// Open puts the terminal into raw mode and switches to the alternate screen. Always call Close.
+func Open() (*Terminal, error) {
+	fd := int(os.Stdin.Fd())
+	orig, err := unix.IoctlGetTermios(fd, unix.TCGETS)
+	if err != nil {
+		return nil, ErrNotTerminal
+	}
+	if _, err := unix.IoctlGetWinsize(int(os.Stdout.Fd()), unix.TIOCGWINSZ); err != nil {
+		return nil, ErrNotTerminal
+	}
+	raw := *orig
+	raw.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP | unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON
+	raw.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+	raw.Cflag &^= unix.CSIZE | unix.PARENB
+	raw.Cflag |= unix.CS8
+	raw.Cc[unix.VMIN] = 1
+	raw.Cc[unix.VTIME] = 0
+	if err := unix.IoctlSetTermios(fd, unix.TCSETS, &raw); err != nil {
+		return nil, err
+	}
+	t := &Terminal{in: os.Stdin, out: bufio.NewWriter(os.Stdout), fd: fd, orig: *orig}
+	t.out.WriteString("\x1b[?1049h\x1b[?25l")
+	t.out.Flush()
+	return t, nil
+}
+
+// Close leaves the alternate screen and restores the original terminal mode.
+func (t *Terminal) Close() error {
+	t.out.WriteString("\x1b[?25h\x1b[?1049l")
+	t.out.Flush()
+	return unix.IoctlSetTermios(t.fd, unix.TCSETS, &t.orig)
+}
+
+// Size returns the terminal width and height (80x24 if unknown).
+func (t *Terminal) Size() (int, int) {
+	ws, err := unix.IoctlGetWinsize(int(os.Stdout.Fd()), unix.TIOCGWINSZ)
+	if err != nil || ws.Col == 0 || ws.Row == 0 {
+		return 80, 24
+	}
+	return int(ws.Col), int(ws.Row)
+}
+
+// ReadKeys blocks until input arrives and returns the keys it decodes to.
+func (t *Terminal) ReadKeys() ([]Key, error) {
+	buf := make([]byte, 64)
+	n, err := t.in.Read(buf)
+	if err != nil {
+		return nil, err
+	}
+	return ParseKeys(buf[:n]), nil
+}
+
+// Draw repaints the whole screen with lines, each cut to the terminal width. Lines may carry
+// the Bold/Reverse/Dim markers below.
+func (t *Terminal) Draw(lines []string) {
+	w, h := t.Size()
+	t.out.WriteString("\x1b[H")
+	for i := 0; i < h; i++ {
+		if i > 0 {
+			t.out.WriteString("\r\n")
+		}
+		t.out.WriteString("\x1b[2K")
+		if i < len(lines) {
+			t.out.WriteString(Fit(lines[i], w))
+		}
+	}
+	t.out.Flush()
+}
+
+// Style markers understood by Fit and Draw: they wrap a whole line.
+const (
+	Reverse = "\x1b[7m"
+	Bold    = "\x1b[1m"
+	Dim     = "\x1b[2m"
+	reset   = "\x1b[0m"
+)
+
+// Fit cuts s to width runes (escape sequences do not count) and resets styling at the end.
+func Fit(s string, width int) string {
+	var b strings.Builder
+	cols := 0
+	styled := false
+	for i := 0; i < len(s); {
+		if s[i] == 0x1b {
+			j := strings.IndexByte(s[i:], 'm')
+			if j < 0 {
+				break
+			}
+			b.WriteString(s[i : i+j+1])
+			styled = true
+			i += j + 1
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if cols >= width {
+			break
+		}
+		if r == '\t' {
+			r = ' '
+		}
+		b.WriteRune(r)
+		cols++
+		i += size
+	}
+	if styled {
+		// Pad so reverse-video rows span the full width.
+		b.WriteString(strings.Repeat(" ", max(0, width-cols)))
+		b.WriteString(reset)
+	}
+	return b.String()
+}
+
+// Pad left-aligns s in a column of width runes, cutting it with "…" when too long.
+func Pad(s string, width int) string {
+	n := utf8.RuneCountInString(s)
+	if n > width {
+		r := []rune(s)
+		if width <= 1 {
+			return string(r[:width])
+		}
+		return string(r[:width-1]) + "…"
+	}
+	return s + strings.Repeat(" ", width-n)
+}
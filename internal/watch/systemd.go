@@ -0,0 +1,66 @@
+package watch
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// UnitName is the systemd user unit `nmsmods watch unit --install` writes.
+const UnitName = "nmsmods-watch.service"
+
+// Unit describes the systemd user service that runs `nmsmods watch`.
+type Unit struct {
+	Exe  string            // absolute path of the nmsmods binary
+	Args []string          // arguments after the binary, e.g. ["watch", "/home/me/Downloads"]
+	Env  map[string]string // extra environment (e.g. NMSMODS_HOME)
+	Dir  string            // watched directory, for the description
+}
+
+// Render returns the unit file text.
+func (u Unit) Render() string {
+	var b strings.Builder
+	b.WriteString("[Unit]\n")
+	fmt.Fprintf(&b, "Description=nmsmods: import mod archives dropped into %s\n", escapeSpecifiers(u.Dir))
+	b.WriteString("\n[Service]\n")
+	b.WriteString("Type=simple\n")
+	for _, k := range sortedKeys(u.Env) {
+		fmt.Fprintf(&b, "Environment=%s\n", quoteSystemd(k+"="+u.Env[k], false))
+	}
+	cmd := []string{quoteSystemd(u.Exe, true)}
+	for _, a := range u.Args {
+		cmd = append(cmd, quoteSystemd(a, true))
+	}
+	fmt.Fprintf(&b, "ExecStart=%s\n", strings.Join(cmd, " "))
+	b.WriteString("Restart=on-failure\n")
+	b.WriteString("RestartSec=10\n")
+	b.WriteString("\n[Install]\n")
+	b.WriteString("WantedBy=default.target\n")
+	return b.String()
+}
+
+// quoteSystemd quotes one word for ExecStart= (exec) or Environment=: double quotes with
+// C-style escapes, % doubled so systemd does not expand it as a specifier, and in ExecStart=
+// $ doubled so it is not expanded as a variable.
+func quoteSystemd(s string, exec bool) string {
+	s = escapeSpecifiers(s)
+	if exec {
+		s = strings.ReplaceAll(s, "$", "$$")
+	}
+	if s != "" && !strings.ContainsAny(s, " \t\"'\\;") {
+		return s
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	return `"` + r.Replace(s) + `"`
+}
+
+func escapeSpecifiers(s string) string { return strings.ReplaceAll(s, "%", "%%") }
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
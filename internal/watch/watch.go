@@ -0,0 +1,135 @@
+// Package watch reports archives that finish arriving in a directory (inotify), for
+// `nmsmods watch`, and renders the systemd user unit that runs it in the background.
+package watch
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// ErrDirGone is returned by Next when the watched directory is deleted or moved away.
+var ErrDirGone = errors.New("watched directory was removed or moved")
+
+// Watcher reports files closed after writing in, or moved into, one directory. Browsers
+// download to a temporary name (.part, .crdownload) and rename it when complete, so a
+// finished download shows up as a move.
+type Watcher struct {
+	dir     string
+	fd      int
+	pending []string
+	buf     [64 * 1024]byte
+}
+
+// New starts watching dir.
+func New(dir string) (*Watcher, error) {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("inotify: %w", err)
+	}
+	mask := uint32(unix.IN_CLOSE_WRITE | unix.IN_MOVED_TO | unix.IN_DELETE_SELF | unix.IN_MOVE_SELF | unix.IN_ONLYDIR)
+	if _, err := unix.InotifyAddWatch(fd, dir, mask); err != nil {
+		unix.Close(fd)
+		return nil, fmt.Errorf("watch %s: %w", dir, err)
+	}
+	return &Watcher{dir: dir, fd: fd}, nil
+}
+
+// Dir returns the watched directory.
+func (w *Watcher) Dir() string { return w.dir }
+
+// Close stops watching.
+func (w *Watcher) Close() error { return unix.Close(w.fd) }
+
+// Next blocks until a file is complete in the directory and returns its path. It returns
+// ctx.Err() once ctx is done and ErrDirGone when the directory disappears.
+func (w *Watcher) Next(ctx context.Context) (string, error) {
+	for len(w.pending) == 0 {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		fds := []unix.PollFd{{Fd: int32(w.fd), Events: unix.POLLIN}}
+		n, err := unix.Poll(fds, 250)
+		if err != nil {
+			if errors.Is(err, unix.EINTR) {
+				continue
+			}
+			return "", err
+		}
+		if n == 0 {
+			continue
+		}
+		if err := w.read(); err != nil {
+			return "", err
+		}
+	}
+	path := w.pending[0]
+	w.pending = w.pending[1:]
+	return path, nil
+}
+
+func (w *Watcher) read() error {
+	n, err := unix.Read(w.fd, w.buf[:])
+	if err != nil {
+		if errors.Is(err, unix.EAGAIN) || errors.Is(err, unix.EINTR) {
+			return nil
+		}
+		return err
+	}
+	for off := 0; off+unix.SizeofInotifyEvent <= n; {
+		// struct inotify_event: wd int32, mask uint32, cookie uint32, len uint32, name [len]byte.
+		mask := binary.NativeEndian.Uint32(w.buf[off+4:])
+		nameLen := binary.NativeEndian.Uint32(w.buf[off+12:])
+		nameStart := off + unix.SizeofInotifyEvent
+		nameEnd := nameStart + int(nameLen)
+		if nameEnd > n {
+			break
+		}
+		name := strings.TrimRight(string(w.buf[nameStart:nameEnd]), "\x00")
+		off = nameEnd
+
+		switch {
+		case mask&(unix.IN_DELETE_SELF|unix.IN_MOVE_SELF|unix.IN_IGNORED) != 0:
+			return ErrDirGone
+		case mask&unix.IN_ISDIR != 0 || name == "":
+		case mask&(unix.IN_CLOSE_WRITE|unix.IN_MOVED_TO) != 0:
+			w.pending = append(w.pending, filepath.Join(w.dir, name))
+		}
+	}
+	return nil
+}
+
+// IsArchive reports whether name is a mod archive nmsmods can import (.zip), ignoring hidden
+// files and partial downloads.
+func IsArchive(name string) bool {
+	if strings.HasPrefix(name, ".") {
+		return false
+	}
+	return strings.EqualFold(filepath.Ext(name), ".zip")
+}
+
+var (
+	nexusSuffix = regexp.MustCompile(`(-\d+){3,}$`)
+	nonAlnum    = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// GuessID derives a mod id from an archive name. Nexus names downloads
+// "<name>-<mod id>-<version>-<timestamp>.zip"; that suffix is dropped so a newer version of
+// the same mod gets the same id.
+func GuessID(name string) string {
+	base := strings.TrimSuffix(name, filepath.Ext(name))
+	if trimmed := nexusSuffix.ReplaceAllString(base, ""); trimmed != "" {
+		base = trimmed
+	}
+	id := strings.Trim(nonAlnum.ReplaceAllString(strings.ToLower(base), "-"), "-")
+	if id == "" {
+		return "mod"
+	}
+	return id
+}
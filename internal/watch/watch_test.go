@@ -0,0 +1,77 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatcher_ReportsRenamedAndWrittenFiles(t *testing.T) {
+	dir := t.TempDir()
+	w, err := New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	part := filepath.Join(dir, "mod.zip.part")
+	if err := os.WriteFile(part, []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(part, filepath.Join(dir, "mod.zip")); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var got []string
+	for len(got) < 2 {
+		path, err := w.Next(ctx)
+		if err != nil {
+			t.Fatalf("after %v: %v", got, err)
+		}
+		got = append(got, filepath.Base(path))
+	}
+	// The partial file is reported when written; callers filter it with IsArchive.
+	if got[0] != "mod.zip.part" || got[1] != "mod.zip" {
+		t.Fatalf("unexpected events: %v", got)
+	}
+	if IsArchive(got[0]) || !IsArchive(got[1]) {
+		t.Fatalf("IsArchive: %v", got)
+	}
+
+	cancel()
+	if _, err := w.Next(ctx); err == nil {
+		t.Fatal("expected an error after cancel")
+	}
+}
+
+func TestGuessID(t *testing.T) {
+	for name, want := range map[string]string{
+		"Better Flight-1234-1-2-1700000000.zip": "better-flight",
+		"Pack-2.zip":                            "pack-2",
+		"1234-1-0.zip":                          "1234-1-0",
+		"___.zip":                               "mod",
+	} {
+		if got := GuessID(name); got != want {
+			t.Errorf("GuessID(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestUnitRender_Quotes(t *testing.T) {
+	u := Unit{Exe: "/opt/nms mods/nmsmods", Dir: "/home/me/100% Downloads", Args: []string{"watch", "/home/me/100% Downloads", "--profile", "a$b"}}
+	got := u.Render()
+	for _, want := range []string{
+		`ExecStart="/opt/nms mods/nmsmods" watch "/home/me/100%% Downloads" --profile a$$b`,
+		"Description=nmsmods: import mod archives dropped into /home/me/100%% Downloads",
+		"WantedBy=default.target",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("unit lacks %q:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,347 @@
+package nmsmods
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"nmsmods/internal/app"
+	"nmsmods/internal/mods"
+)
+
+// DeployOptions applies the game target and the profile's deploy strategy.
+func (m *Manager) DeployOptions(profile string, game *Game) DeployOptions {
+	opts := DeployOptions{Target: game.Target}
+	if s, err := app.LoadProfileSettings(m.paths, profile); err == nil {
+		opts.Hardlink = s.DeployStrategy == app.DeployHardlink
+	}
+	return opts
+}
+
+// UndeployInstall removes a profile's deployed folder from the game. For a non-active profile
+// only a recorded deployment (e.g. one left in another game target) is removed.
+func (m *Manager) UndeployInstall(cfg Config, game *Game, id, profile string, pi ProfileInstall) error {
+	if m.ProfileDeploys(cfg) {
+		return mods.Undeploy(game.ModsDir, pi.Folder, id, profile)
+	}
+	if pi.DeployedPath == "" {
+		return nil
+	}
+	return mods.Undeploy(filepath.Dir(pi.DeployedPath), filepath.Base(pi.DeployedPath), id, profile)
+}
+
+// DeployInstalled deploys a freshly installed store folder unless the profile installs new
+// mods disabled (profile set --new-mods disabled) or is not the active profile. Reinstalls of a
+// mod the profile already had stay enabled. It returns the deployed path ("" when skipped) and
+// the enabled flag.
+func (m *Manager) DeployInstalled(cfg Config, game *Game, storePath, folder, id, profile string, prev ProfileInstall) (string, bool, error) {
+	enabled := true
+	if !prev.Installed {
+		if s, err := app.LoadProfileSettings(m.paths, profile); err == nil && s.NewModsDisabled {
+			enabled = false
+		}
+	}
+	if !enabled || !m.ProfileDeploys(cfg) {
+		return "", enabled, nil
+	}
+	deployed, err := mods.Deploy(storePath, game.ModsDir, folder, id, profile, m.DeployOptions(profile, game))
+	if err != nil {
+		return "", false, err
+	}
+	return deployed, true, nil
+}
+
+// DeployProfile makes GAMEDATA/MODS of game match the Manager's profile: folders nmsmods
+// deployed there (for any profile) are removed, then the profile's enabled mods are deployed.
+// Call it under the lock (Locked or Update).
+func (m *Manager) DeployProfile(cfg Config, game *Game) error {
+	st, err := m.LoadState()
+	if err != nil {
+		return err
+	}
+	active := m.Profile(cfg)
+	modsDir := game.ModsDir
+
+	// 1) Undeploy anything we previously deployed (any profile) into this game, but only folders
+	// we track. Deployments into other game targets stay where they are.
+	for id, me := range st.Mods {
+		changed := false
+		for prof, pi := range me.Installations {
+			if pi.DeployedPath != "" && filepath.Dir(pi.DeployedPath) != filepath.Clean(modsDir) {
+				continue
+			}
+			if pi.Enabled && pi.Folder != "" {
+				// Another profile's mod may use the same folder name and be the one deployed.
+				name, _ := mods.SanitizeFolderName(pi.Folder, id)
+				if mk, err := mods.ReadManagedMarker(filepath.Join(modsDir, name)); err == nil && (mk.ModID != id || mk.Profile != prof) {
+					pi.DeployedPath = ""
+					me.Installations[prof] = pi
+					changed = true
+					continue
+				}
+				if err := mods.Undeploy(modsDir, pi.Folder, id, prof); err != nil {
+					return fmt.Errorf("failed to undeploy %s (%s): %w", id, prof, err)
+				}
+				pi.DeployedPath = ""
+				// Keep enabled state; we'll redeploy active ones below.
+				me.Installations[prof] = pi
+				changed = true
+			}
+		}
+		if changed {
+			st.Mods[id] = me
+		}
+	}
+
+	// 2) Deploy active profile enabled mods.
+	for id, me := range st.Mods {
+		pi, ok := me.Installations[active]
+		if !ok || !pi.Installed || !pi.Enabled || pi.Folder == "" || pi.Store == "" {
+			continue
+		}
+		storeAbs := m.abs(pi.Store)
+		if _, err := os.Stat(storeAbs); err != nil {
+			continue
+		}
+		deployed, err := mods.Deploy(storeAbs, modsDir, pi.Folder, id, active, m.DeployOptions(active, game))
+		if err != nil {
+			return err
+		}
+		pi.DeployedPath = deployed
+		me.Installations[active] = pi
+		st.Mods[id] = me
+	}
+
+	return m.SaveState(st)
+}
+
+// DeployResult describes a Deploy.
+type DeployResult struct {
+	Profile string `json:"profile"`
+	Game    *Game  `json:"-"`
+}
+
+// Deploy redeploys the active profile's enabled mods into the game. A Manager scoped to another
+// profile fails with *ProfileNotActiveError.
+func (m *Manager) Deploy(ctx context.Context) (DeployResult, error) {
+	var res DeployResult
+	err := m.Locked(func() error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		cfg, game, err := m.RequireGame()
+		if err != nil {
+			return err
+		}
+		res.Profile = m.Profile(*cfg)
+		if !m.ProfileDeploys(*cfg) {
+			return &ProfileNotActiveError{Profile: res.Profile}
+		}
+		if err := CheckGameNotRunning(); err != nil {
+			return err
+		}
+		if _, err := m.EnsureProfileDirs(*cfg); err != nil {
+			return err
+		}
+		res.Game = game
+		return m.DeployProfile(*cfg, game)
+	})
+	return res, err
+}
+
+// ToggleResult describes an Enable or Disable.
+type ToggleResult struct {
+	ID      string `json:"id"`
+	Profile string `json:"profile"`
+	Enabled bool   `json:"enabled"`
+	// Unchanged is true when the mod already was in the requested state.
+	Unchanged    bool   `json:"unchanged,omitempty"`
+	StorePath    string `json:"store_path"`
+	DeployedPath string `json:"deployed_path,omitempty"`
+	Game         *Game  `json:"-"`
+}
+
+// Enable enables an installed mod (id or handle) in the Manager's profile and deploys it when
+// the profile is active.
+func (m *Manager) Enable(ctx context.Context, mod string) (ToggleResult, error) {
+	return m.toggle(ctx, "enable", mod, true)
+}
+
+// Disable disables an installed mod: it stays in the profile store and leaves the game.
+func (m *Manager) Disable(ctx context.Context, mod string) (ToggleResult, error) {
+	return m.toggle(ctx, "disable", mod, false)
+}
+
+func (m *Manager) toggle(ctx context.Context, op, mod string, enable bool) (ToggleResult, error) {
+	var res ToggleResult
+	err := m.Update(m.command(op, mod), func(tx *Tx) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		cfg, game, err := m.RequireGame()
+		if err != nil {
+			return err
+		}
+		if err := CheckGameNotRunning(); err != nil {
+			return err
+		}
+		profile, err := m.EnsureProfileDirs(*cfg)
+		if err != nil {
+			return err
+		}
+		st, err := m.LoadState()
+		if err != nil {
+			return err
+		}
+		id, err := m.Resolve(st, mod)
+		if err != nil {
+			return err
+		}
+
+		me := st.Mods[id]
+		pi, ok := me.Installations[profile]
+		if !ok || !pi.Installed || pi.Folder == "" {
+			return &NotInstalledError{ID: id, Profile: profile}
+		}
+		storeAbs := m.abs(pi.Store)
+		res = ToggleResult{ID: id, Profile: profile, Enabled: enable, StorePath: storeAbs, Game: game}
+		if pi.Enabled == enable {
+			res.Unchanged = true
+			res.DeployedPath = pi.DeployedPath
+			return nil
+		}
+
+		if enable {
+			if _, err := os.Stat(storeAbs); err != nil {
+				return fmt.Errorf("stored mod folder not found: %s", storeAbs)
+			}
+			if m.ProfileDeploys(*cfg) {
+				if res.DeployedPath, err = mods.Deploy(storeAbs, game.ModsDir, pi.Folder, id, profile, m.DeployOptions(profile, game)); err != nil {
+					return err
+				}
+			}
+		} else if err := m.UndeployInstall(*cfg, game, id, profile, pi); err != nil {
+			return err
+		}
+
+		pi.Enabled = enable
+		pi.DeployedPath = res.DeployedPath
+		me.Installations[profile] = pi
+		st.Mods[id] = me
+		return m.SaveState(st)
+	})
+	return res, err
+}
+
+// EnableMods enables the mods sel selects as one operation: one lock, one history entry and,
+// when the profile is active, one redeploy at the end. Nothing changes if a named mod is
+// unknown or not installed.
+func (m *Manager) EnableMods(ctx context.Context, sel Selector) ([]ToggleResult, error) {
+	return m.toggleMany(ctx, "enable", sel, true)
+}
+
+// DisableMods disables the mods sel selects as one operation (see EnableMods).
+func (m *Manager) DisableMods(ctx context.Context, sel Selector) ([]ToggleResult, error) {
+	return m.toggleMany(ctx, "disable", sel, false)
+}
+
+func (m *Manager) toggleMany(ctx context.Context, op string, sel Selector, enable bool) ([]ToggleResult, error) {
+	out := []ToggleResult{}
+	err := m.Update(m.command(op, sel.Args...), func(tx *Tx) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		cfg, game, err := m.RequireGame()
+		if err != nil {
+			return err
+		}
+		if err := CheckGameNotRunning(); err != nil {
+			return err
+		}
+		profile, err := m.EnsureProfileDirs(*cfg)
+		if err != nil {
+			return err
+		}
+		st, err := m.LoadState()
+		if err != nil {
+			return err
+		}
+
+		ids, err := m.Select(st, sel)
+		if err != nil {
+			return err
+		}
+
+		// Validate everything before touching the game.
+		type change struct {
+			id string
+			pi ProfileInstall
+		}
+		var changes []change
+		for _, id := range ids {
+			pi, ok := st.Mods[id].Installations[profile]
+			if !ok || !pi.Installed || pi.Folder == "" {
+				return &NotInstalledError{ID: id, Profile: profile}
+			}
+			res := ToggleResult{ID: id, Profile: profile, Enabled: enable, StorePath: m.abs(pi.Store), Game: game}
+			if pi.Enabled == enable {
+				res.Unchanged = true
+				res.DeployedPath = pi.DeployedPath
+			} else {
+				if enable {
+					if _, err := os.Stat(res.StorePath); err != nil {
+						return fmt.Errorf("stored mod folder not found: %s", res.StorePath)
+					}
+				}
+				changes = append(changes, change{id: id, pi: pi})
+			}
+			out = append(out, res)
+		}
+		if len(changes) == 0 {
+			return nil
+		}
+
+		for _, c := range changes {
+			if !enable {
+				if err := m.UndeployInstall(*cfg, game, c.id, profile, c.pi); err != nil {
+					return err
+				}
+				c.pi.DeployedPath = ""
+			}
+			c.pi.Enabled = enable
+			me := st.Mods[c.id]
+			me.Installations[profile] = c.pi
+			st.Mods[c.id] = me
+		}
+		if err := m.SaveState(st); err != nil {
+			return err
+		}
+		if !enable || !m.ProfileDeploys(*cfg) {
+			return nil
+		}
+
+		if err := m.DeployProfile(*cfg, game); err != nil {
+			// Put the entries back as they were so state does not claim mods the game lacks.
+			for _, c := range changes {
+				_ = mods.Undeploy(game.ModsDir, c.pi.Folder, c.id, profile)
+				me := st.Mods[c.id]
+				me.Installations[profile] = c.pi
+				st.Mods[c.id] = me
+			}
+			if serr := m.SaveState(st); serr != nil {
+				return errors.Join(err, serr)
+			}
+			return err
+		}
+		if st, err = m.LoadState(); err != nil {
+			return err
+		}
+		for i, r := range out {
+			out[i].DeployedPath = st.Mods[r.ID].Installations[profile].DeployedPath
+		}
+		return nil
+	})
+	return out, err
+}
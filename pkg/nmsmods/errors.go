@@ -0,0 +1,116 @@
+package nmsmods
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"nmsmods/internal/app"
+)
+
+// ErrLockBusy means another nmsmods process (CLI, TUI or API server) holds the state lock.
+var ErrLockBusy = app.ErrLockBusy
+
+// ErrNoAPIKey means config.json has no Nexus API key.
+var ErrNoAPIKey = errors.New("nexus api key not set (run: nmsmods nexus login)")
+
+// UnknownModError is returned for an id or handle that is not in state. Suggestions lists
+// display-name matches that were not confirmed (see ResolveModConfirm).
+type UnknownModError struct {
+	ID          string
+	Suggestions []string
+}
+
+func (e *UnknownModError) Error() string {
+	if len(e.Suggestions) > 0 {
+		return fmt.Sprintf("unknown id: %s (did you mean: %s?)", e.ID, strings.Join(e.Suggestions, ", "))
+	}
+	return fmt.Sprintf("unknown id: %s (run: nmsmods downloads)", e.ID)
+}
+
+// AmbiguousModError is returned when a mod argument (an id prefix, nx:<mod id> or a display
+// name) matches several mods.
+type AmbiguousModError struct {
+	Arg        string
+	Candidates []string
+}
+
+func (e *AmbiguousModError) Error() string {
+	return fmt.Sprintf("%q matches several mods: %s (use the full id or handle)", e.Arg, strings.Join(e.Candidates, ", "))
+}
+
+// SelectorError is returned by Select for a selector that cannot be applied (both ids and
+// --all, an invalid filter value or glob, or nothing to select by), and for invalid tags and
+// mod ids.
+type SelectorError struct {
+	Err error
+}
+
+func (e *SelectorError) Error() string { return e.Err.Error() }
+func (e *SelectorError) Unwrap() error { return e.Err }
+
+// NotInstalledError is returned when a mod has no installation in the profile.
+type NotInstalledError struct {
+	ID      string
+	Profile string
+}
+
+func (e *NotInstalledError) Error() string {
+	return fmt.Sprintf("mod %s is not installed in profile %q", e.ID, e.Profile)
+}
+
+// NoDownloadError is returned by Install when the mod's zip is not recorded (Path "") or
+// missing from downloads/.
+type NoDownloadError struct {
+	ID   string
+	Path string
+}
+
+func (e *NoDownloadError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("no zip recorded for %s. Use: nmsmods download <url-or-zip> [--id %s]", e.ID, e.ID)
+	}
+	return fmt.Sprintf("zip not found: %s", e.Path)
+}
+
+// GameRunningError is returned by operations that would change GAMEDATA/MODS while the game runs.
+type GameRunningError struct {
+	PIDs []int
+}
+
+func (e *GameRunningError) Error() string {
+	pids := make([]string, 0, len(e.PIDs))
+	for _, pid := range e.PIDs {
+		pids = append(pids, fmt.Sprint(pid))
+	}
+	return fmt.Sprintf("No Man's Sky is running (pid %s); refusing to modify GAMEDATA/MODS. Quit the game and try again", strings.Join(pids, ", "))
+}
+
+// ProfileNotActiveError is returned by Deploy for a Manager scoped to a non-active profile.
+type ProfileNotActiveError struct {
+	Profile string
+}
+
+func (e *ProfileNotActiveError) Error() string {
+	return fmt.Sprintf("profile %s is not active (run: nmsmods profile use %s)", e.Profile, e.Profile)
+}
+
+// GameNotFoundError is returned when the game target is not configured or its path is not a
+// No Man's Sky install.
+type GameNotFoundError struct {
+	Target string
+	Err    error
+}
+
+func (e *GameNotFoundError) Error() string { return e.Err.Error() }
+func (e *GameNotFoundError) Unwrap() error { return e.Err }
+
+// NetworkError is returned when a download or a Nexus API call fails in transit or with an
+// HTTP error status.
+type NetworkError struct {
+	Op  string // "download", "nexus"
+	Err error
+}
+
+func (e *NetworkError) Error() string { return e.Err.Error() }
+func (e *NetworkError) Unwrap() error { return e.Err }
@@ -0,0 +1,322 @@
+package nmsmods
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"nmsmods/internal/app"
+	"nmsmods/internal/mods"
+	"nmsmods/internal/nms"
+)
+
+// Inconsistency kinds reported by Fsck.
+const (
+	FsckMissingZip     = "missing_zip"      // ModEntry.ZIP points at a file that is gone
+	FsckOrphanDownload = "orphan_download"  // file in downloads/ no entry references
+	FsckMissingStore   = "missing_store"    // installed in a profile but the store folder is gone
+	FsckNotDeployed    = "not_deployed"     // enabled in the active profile but absent from GAMEDATA/MODS
+	FsckStaleDeployed  = "stale_deployed"   // DeployedPath recorded but the folder is gone
+	FsckOrphanMarker   = "orphan_marker"    // managed folder in GAMEDATA/MODS that state does not deploy
+	FsckMarkerMismatch = "marker_mismatch"  // deployed folder is unmanaged or owned by another mod
+	FsckGameSkipped    = "game_unavailable" // GAMEDATA/MODS checks skipped
+)
+
+// FsckIssue is one inconsistency found by Fsck (stable JSON shape).
+type FsckIssue struct {
+	Kind     string `json:"kind"`
+	Severity string `json:"severity"` // "error" | "warning"
+	ModID    string `json:"mod_id,omitempty"`
+	Profile  string `json:"profile,omitempty"`
+	Path     string `json:"path,omitempty"`
+	Message  string `json:"message"`
+	Repair   string `json:"repair,omitempty"` // what a fix does; empty when it needs a manual decision
+	Fixed    bool   `json:"fixed,omitempty"`
+	FixError string `json:"fix_error,omitempty"`
+
+	fix func(st *State) error
+}
+
+// FsckReport describes a Fsck (stable JSON shape). OK is false while any issue other than
+// FsckGameSkipped is left unfixed.
+type FsckReport struct {
+	OK     bool         `json:"ok"`
+	Fix    bool         `json:"fix"`
+	Issues []*FsckIssue `json:"issues"`
+}
+
+// Fsck compares the state with downloads/, the profile stores and the managed markers in
+// GAMEDATA/MODS. It always checks the configured active profile; WithProfile does not apply.
+// With fix, safe repairs are applied under the lock and recorded in history: dangling references
+// are cleared, stores are rebuilt from a matching deployed copy, missing deployments are
+// redeployed and orphan files go to the trash. Folders nmsmods does not own are never touched.
+func (m *Manager) Fsck(ctx context.Context, fix bool) (FsckReport, error) {
+	am := *m
+	am.profile = ""
+	rep := FsckReport{Fix: fix}
+
+	run := func(command string) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		cfg, err := am.LoadConfig()
+		if err != nil {
+			return err
+		}
+		st, err := am.LoadState()
+		if err != nil {
+			return err
+		}
+		game, gameErr := am.ResolveGame(cfg)
+		if fix && game != nil {
+			if err := CheckGameNotRunning(); err != nil {
+				return err
+			}
+		}
+
+		rep.Issues = am.fsckCheck(st, app.ActiveProfile(cfg), game, gameErr, command)
+		if !fix {
+			return nil
+		}
+		fixed := false
+		for _, is := range rep.Issues {
+			if is.fix == nil {
+				continue
+			}
+			if err := is.fix(&st); err != nil {
+				is.FixError = err.Error()
+				continue
+			}
+			is.Fixed = true
+			fixed = true
+		}
+		if fixed {
+			return am.SaveState(st)
+		}
+		return nil
+	}
+
+	var err error
+	if fix {
+		command := am.command("fsck", "--fix")
+		err = am.Update(command, func(tx *Tx) error { return run(command) })
+	} else {
+		err = run("")
+	}
+	if err != nil {
+		return rep, err
+	}
+	rep.OK = true
+	for _, is := range rep.Issues {
+		if !is.Fixed && is.Kind != FsckGameSkipped {
+			rep.OK = false
+		}
+	}
+	return rep, nil
+}
+
+// fsckCheck classifies every inconsistency. Repairs are closures run in order on the same
+// state; command is what trashed files record.
+func (m *Manager) fsckCheck(st State, active string, game *Game, gameErr error, command string) []*FsckIssue {
+	p := m.paths
+	var issues []*FsckIssue
+	add := func(is *FsckIssue) { issues = append(issues, is) }
+	ids := SortedModIDs(st)
+
+	// downloads/ <-> ModEntry.ZIP
+	referenced := map[string]bool{}
+	for _, id := range ids {
+		me := st.Mods[id]
+		if me.ZIP == "" {
+			continue
+		}
+		zipAbs := m.abs(me.ZIP)
+		referenced[filepath.Clean(zipAbs)] = true
+		if fileExists(zipAbs) {
+			continue
+		}
+		add(&FsckIssue{
+			Kind: FsckMissingZip, Severity: "warning", ModID: id, Path: zipAbs,
+			Message: "tracked download is missing",
+			Repair:  "forget the download (reinstall will need it again)",
+			fix: func(st *State) error {
+				me := st.Mods[id]
+				me.ZIP = ""
+				st.Mods[id] = me
+				return nil
+			},
+		})
+	}
+	if ents, err := os.ReadDir(p.Downloads); err == nil {
+		for _, e := range ents {
+			abs := filepath.Join(p.Downloads, e.Name())
+			if e.IsDir() || strings.HasSuffix(e.Name(), ".part") || referenced[filepath.Clean(abs)] {
+				continue
+			}
+			add(&FsckIssue{
+				Kind: FsckOrphanDownload, Severity: "warning", Path: abs,
+				Message: "download is not tracked by any mod",
+				Repair:  "move it to the trash",
+				fix: func(*State) error {
+					_, err := m.Trash(abs, app.TrashDownload, "", command)
+					return err
+				},
+			})
+		}
+	}
+
+	// Profile stores and deployments.
+	deployedBy := map[string]string{} // active-profile folder -> mod id
+	for _, id := range ids {
+		me := st.Mods[id]
+		profiles := make([]string, 0, len(me.Installations))
+		for prof := range me.Installations {
+			profiles = append(profiles, prof)
+		}
+		sort.Strings(profiles)
+
+		for _, prof := range profiles {
+			pi := me.Installations[prof]
+			if !pi.Installed {
+				continue
+			}
+			storeAbs := ""
+			if pi.Store != "" {
+				storeAbs = m.abs(pi.Store)
+			}
+			storeOK := storeAbs != "" && fileExists(storeAbs)
+			isActive := prof == active && game != nil
+			folderAbs := ""
+			if game != nil && pi.Folder != "" {
+				folderAbs = filepath.Join(game.ModsDir, pi.Folder)
+			}
+			if isActive && pi.Enabled && pi.Folder != "" {
+				deployedBy[pi.Folder] = id
+			}
+
+			if !storeOK {
+				is := &FsckIssue{Kind: FsckMissingStore, Severity: "error", ModID: id, Profile: prof, Path: storeAbs,
+					Message: "profile store folder is missing"}
+				if storeAbs != "" && isActive && pi.Enabled && deployedCopyOwnedBy(folderAbs, id, prof) {
+					is.Repair = "rebuild the store from the deployed copy in GAMEDATA/MODS"
+					is.fix = func(*State) error { return rebuildStore(folderAbs, storeAbs) }
+				} else {
+					is.Repair = "drop the profile install record (the mod stays downloaded)"
+					is.fix = func(st *State) error {
+						if game != nil && pi.Folder != "" {
+							if err := mods.Undeploy(game.ModsDir, pi.Folder, id, prof); err != nil {
+								return err
+							}
+						}
+						me := st.Mods[id]
+						delete(me.Installations, prof)
+						st.Mods[id] = me
+						return nil
+					}
+				}
+				add(is)
+				continue
+			}
+
+			if isActive && pi.Enabled && pi.Folder != "" {
+				if !fileExists(folderAbs) {
+					add(&FsckIssue{Kind: FsckNotDeployed, Severity: "error", ModID: id, Profile: prof, Path: folderAbs,
+						Message: "enabled but not present in GAMEDATA/MODS",
+						Repair:  "redeploy from the profile store",
+						fix: func(st *State) error {
+							deployed, err := mods.Deploy(storeAbs, game.ModsDir, pi.Folder, id, prof, m.DeployOptions(prof, game))
+							if err != nil {
+								return err
+							}
+							me := st.Mods[id]
+							cur := me.Installations[prof]
+							cur.DeployedPath = deployed
+							me.Installations[prof] = cur
+							st.Mods[id] = me
+							return nil
+						},
+					})
+				} else if mk, err := mods.ReadManagedMarker(folderAbs); err != nil || mk.ModID != id || mk.Profile != prof {
+					msg := "deployed folder has no nmsmods marker (external folder with the same name?)"
+					if err == nil {
+						msg = fmt.Sprintf("deployed folder belongs to %s (%s)", mk.ModID, mk.Profile)
+					}
+					add(&FsckIssue{Kind: FsckMarkerMismatch, Severity: "error", ModID: id, Profile: prof, Path: folderAbs, Message: msg})
+				}
+				continue
+			}
+
+			if pi.DeployedPath != "" && !fileExists(pi.DeployedPath) {
+				add(&FsckIssue{Kind: FsckStaleDeployed, Severity: "warning", ModID: id, Profile: prof, Path: pi.DeployedPath,
+					Message: "recorded deploy path no longer exists",
+					Repair:  "clear the deploy path",
+					fix: func(st *State) error {
+						me := st.Mods[id]
+						cur := me.Installations[prof]
+						cur.DeployedPath = ""
+						me.Installations[prof] = cur
+						st.Mods[id] = me
+						return nil
+					},
+				})
+			}
+		}
+	}
+
+	// GAMEDATA/MODS markers -> state.
+	if game == nil {
+		add(&FsckIssue{Kind: FsckGameSkipped, Severity: "warning", Message: fmt.Sprintf("GAMEDATA/MODS checks skipped: %v", gameErr)})
+	} else if folders, err := nms.ListInstalledModFolders(game); err == nil {
+		for _, f := range folders {
+			abs := filepath.Join(game.ModsDir, f)
+			mk, err := mods.ReadManagedMarker(abs)
+			if err != nil || deployedBy[f] == mk.ModID && mk.Profile == active {
+				continue
+			}
+			why := "mod is no longer tracked in state"
+			if me, ok := st.Mods[mk.ModID]; ok {
+				pi := me.Installations[mk.Profile]
+				switch {
+				case mk.Profile != active && pi.Installed:
+					why = fmt.Sprintf("deployed for inactive profile %q", mk.Profile)
+				case !pi.Installed:
+					why = fmt.Sprintf("mod is not installed in profile %q", mk.Profile)
+				default:
+					why = "mod is disabled or deployed under another folder"
+				}
+			}
+			add(&FsckIssue{Kind: FsckOrphanMarker, Severity: "warning", ModID: mk.ModID, Profile: mk.Profile, Path: abs,
+				Message: "managed folder " + f + ": " + why,
+				Repair:  "move the folder to the trash",
+				fix: func(*State) error {
+					_, err := m.Trash(abs, app.TrashOther, mk.ModID, command)
+					return err
+				},
+			})
+		}
+	}
+
+	return issues
+}
+
+func deployedCopyOwnedBy(folderAbs, modID, profile string) bool {
+	if folderAbs == "" {
+		return false
+	}
+	mk, err := mods.ReadManagedMarker(folderAbs)
+	return err == nil && mk.ModID == modID && mk.Profile == profile
+}
+
+// rebuildStore copies a deployed folder back into its profile store, without the marker.
+func rebuildStore(deployedAbs, storeAbs string) error {
+	if err := mods.CopyDir(deployedAbs, storeAbs); err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(storeAbs, mods.ManagedMarkerFile)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,325 @@
+package nmsmods
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"nmsmods/internal/app"
+	"nmsmods/internal/mods"
+)
+
+// ModStatus is one tracked mod as seen from the Manager's profile.
+type ModStatus struct {
+	Handle  int // permanent, accepted wherever a mod id is
+	Index   int // Deprecated: same as Handle (positional indexes were replaced by handles)
+	ID      string
+	Profile string
+	Entry   ModEntry
+	Install ProfileInstall // zero when the mod is not installed in Profile
+	ZIPPath string         // absolute path of the download ("" when none is recorded)
+}
+
+// ListMods returns every tracked mod in id order.
+func (m *Manager) ListMods(ctx context.Context) ([]ModStatus, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	st, err := m.LoadState()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := m.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	profile := m.Profile(cfg)
+	out := []ModStatus{}
+	for _, id := range SortedModIDs(st) {
+		me := st.Mods[id]
+		ms := ModStatus{Handle: me.Handle, Index: me.Handle, ID: id, Profile: profile, Entry: me, Install: me.Installations[profile]}
+		if me.ZIP != "" {
+			ms.ZIPPath = m.abs(me.ZIP)
+		}
+		out = append(out, ms)
+	}
+	return out, nil
+}
+
+// DownloadResult describes a Download.
+type DownloadResult struct {
+	ID     string `json:"id"`
+	Source string `json:"source"` // "local" (imported zip) or "url"
+	Path   string `json:"path"`   // the zip in downloads/
+}
+
+// Download fetches a mod zip from an http(s) URL, or imports a local zip file, into downloads/
+// and records it under id ("" = a slug of the URL or file name). The new entry is recorded in
+// history, so undo forgets it again.
+func (m *Manager) Download(ctx context.Context, source, id string) (DownloadResult, error) {
+	var res DownloadResult
+	id = strings.TrimSpace(id)
+	if id == "" {
+		id = mods.SlugFromURL(source)
+	}
+	if err := app.ValidateModID(id); err != nil {
+		return res, &SelectorError{Err: err}
+	}
+	err := m.Update(m.command("download", source, "--id="+id), func(tx *Tx) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		st, err := m.LoadState()
+		if err != nil {
+			return err
+		}
+
+		if st.Mods == nil {
+			st.Mods = map[string]ModEntry{}
+		}
+		me := st.Mods[id]
+		if me.DisplayName == "" {
+			me.DisplayName = id
+		}
+		res.ID = id
+
+		if fileExists(source) {
+			if !strings.HasSuffix(strings.ToLower(source), ".zip") {
+				return fmt.Errorf("not a zip file: %s", source)
+			}
+			base := filepath.Base(source)
+			dest := filepath.Join(m.paths.Downloads, base)
+			if err := copyFile(source, dest); err != nil {
+				return err
+			}
+			me.URL = "file://" + source
+			me.Source = "local"
+			me.ZIP = filepath.ToSlash(filepath.Join("downloads", base))
+			res.Source, res.Path = "local", dest
+		} else {
+			outName := id + ".zip"
+			dest := filepath.Join(m.paths.Downloads, outName)
+			m.progressf("Downloading to: %s", dest)
+			if err := mods.DownloadURLToFileContext(ctx, source, dest); err != nil {
+				if errors.Is(err, mods.ErrNotZip) || ctx.Err() != nil {
+					return err
+				}
+				return &NetworkError{Op: "download", Err: err}
+			}
+			me.URL = source
+			me.Source = "url"
+			me.ZIP = filepath.ToSlash(filepath.Join("downloads", outName))
+			res.Source, res.Path = "url", dest
+		}
+
+		me.DownloadedAt = app.NowRFC3339()
+		st.Mods[id] = me
+		return m.SaveState(st)
+	})
+	return res, err
+}
+
+// InstallOptions tunes Install.
+type InstallOptions struct {
+	// NoOverwrite fails instead of replacing an existing store folder.
+	NoOverwrite bool
+	// DryRun only plans the install: nothing is extracted, copied or deployed.
+	DryRun bool
+	// Disabled installs the mod without enabling it, whatever the profile's new-mods setting.
+	// A deployed earlier install is removed from the game.
+	Disabled bool
+}
+
+// InstallResult describes an Install (or, with DryRun, what it would do).
+type InstallResult struct {
+	ID        string `json:"id"`
+	Profile   string `json:"profile"`
+	ZIPPath   string `json:"zip_path"`
+	Folder    string `json:"folder"`
+	StorePath string `json:"store_path"`
+	// DeployPath is where the folder goes in GAMEDATA/MODS ("" when the profile is not active).
+	DeployPath string `json:"deploy_path,omitempty"`
+	// DeployedPath is set once deployed; "" when the profile installs new mods disabled or is
+	// not active.
+	DeployedPath string `json:"deployed_path,omitempty"`
+	Enabled      bool   `json:"enabled"`
+	Collided     bool   `json:"collided,omitempty"`      // folder renamed to avoid another mod's
+	StoreExisted bool   `json:"store_existed,omitempty"` // an existing store folder was (or would be) replaced
+	Health       string `json:"health,omitempty"`
+	// Conflicts lists game files the mod shares with other mods enabled in the profile,
+	// including files packed into .pak archives.
+	Conflicts []FileConflict `json:"conflicts,omitempty"`
+	DryRun    bool           `json:"dry_run,omitempty"`
+	Game      *Game          `json:"-"`
+}
+
+// Install extracts a downloaded mod (id or handle) into the Manager's profile store and deploys
+// it when the profile is active. A replaced store folder goes to the trash and the operation is
+// recorded in history.
+func (m *Manager) Install(ctx context.Context, mod string, opts InstallOptions) (InstallResult, error) {
+	var res InstallResult
+	err := m.Update(m.command("install", mod), func(tx *Tx) error {
+		cfg, game, err := m.RequireGame()
+		if err != nil {
+			return err
+		}
+		if err := CheckGameNotRunning(); err != nil {
+			return err
+		}
+		profile, err := m.EnsureProfileDirs(*cfg)
+		if err != nil {
+			return err
+		}
+		st, err := m.LoadState()
+		if err != nil {
+			return err
+		}
+		id, err := m.Resolve(st, mod)
+		if err != nil {
+			return err
+		}
+
+		me := st.Mods[id]
+		if me.ZIP == "" {
+			return &NoDownloadError{ID: id}
+		}
+		zipAbs := m.abs(me.ZIP)
+		if _, err := os.Stat(zipAbs); err != nil {
+			return &NoDownloadError{ID: id, Path: zipAbs}
+		}
+
+		// Predict folder name without extracting (useful for DryRun).
+		folder, err := mods.ProposedInstallFolderFromZip(zipAbs, id)
+		if err != nil {
+			return err
+		}
+		if me.Installations == nil {
+			me.Installations = map[string]ProfileInstall{}
+		}
+		pi := me.Installations[profile]
+
+		// Avoid clobbering another mod folder within this profile.
+		folder, collided := mods.ResolveFolderCollision(id, folder, profile, st)
+		storeDir := app.ProfileModsDir(m.paths, profile)
+		res = InstallResult{
+			ID: id, Profile: profile, ZIPPath: zipAbs, Folder: folder,
+			StorePath: filepath.Join(storeDir, folder), Collided: collided, DryRun: opts.DryRun, Game: game,
+		}
+		res.StoreExisted = fileExists(res.StorePath)
+		if m.ProfileDeploys(*cfg) {
+			res.DeployPath = filepath.Join(game.ModsDir, folder)
+		}
+		if opts.DryRun {
+			return nil
+		}
+
+		// Extract -> choose folder -> copy into profile store -> deploy into game MODS
+		stageDir := filepath.Join(m.paths.Staging, id)
+		_ = os.RemoveAll(stageDir)
+		if err := os.MkdirAll(stageDir, 0o755); err != nil {
+			return err
+		}
+		m.progressf("Extracting to: %s", stageDir)
+		if err := mods.ExtractZip(zipAbs, stageDir); err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// Choose folder based on extracted layout (authoritative)
+		folder, srcPath, err := mods.ChooseInstallFolder(stageDir, id)
+		if err != nil {
+			return err
+		}
+		folder, _ = mods.ResolveFolderCollision(id, folder, profile, st)
+		storePath := filepath.Join(storeDir, folder)
+		res.Folder, res.StorePath = folder, storePath
+		res.StoreExisted = fileExists(storePath)
+
+		if res.StoreExisted {
+			if opts.NoOverwrite {
+				return fmt.Errorf("destination exists in profile store: %s (run without --no-overwrite to replace it)", storePath)
+			}
+			m.progressf("Replacing existing profile install: %s", storePath)
+			if err := tx.TrashStore(storePath, id); err != nil {
+				return err
+			}
+		}
+
+		m.progressf("Installing into profile store: %s", storePath)
+		if err := mods.CopyDir(srcPath, storePath); err != nil {
+			return err
+		}
+
+		ok, verr := mods.HasRelevantFiles(storePath)
+		if verr != nil || !ok {
+			m.progressf("Warning: installed folder contains no .EXML/.MBIN files (or health check failed)")
+			me.Health = "warning"
+		} else {
+			me.Health = "ok"
+		}
+		res.Health = me.Health
+		if conflicts, err := mods.FileConflicts(m.paths.Root, st, profile, id, storePath); err == nil && len(conflicts) > 0 {
+			res.Conflicts = conflicts
+			m.warnf("%s shares %d game file(s) with other enabled mods (see nmsmods inspect %s)", id, len(conflicts), id)
+		}
+
+		// Enabled by default: deploy to game
+		var deployed string
+		enabled := false
+		if opts.Disabled {
+			if pi.Enabled {
+				if err := m.UndeployInstall(*cfg, game, id, profile, pi); err != nil {
+					return err
+				}
+			}
+		} else if deployed, enabled, err = m.DeployInstalled(*cfg, game, storePath, folder, id, profile, pi); err != nil {
+			return err
+		}
+		res.DeployedPath, res.Enabled = deployed, enabled
+		if m.ProfileDeploys(*cfg) {
+			res.DeployPath = filepath.Join(game.ModsDir, folder)
+		}
+
+		pi.Installed = true
+		pi.Enabled = enabled
+		pi.Folder = folder
+		pi.Store = filepath.ToSlash(filepath.Join("profiles", profile, "mods", folder))
+		pi.DeployedPath = deployed
+		pi.InstalledAt = app.NowRFC3339()
+		me.Installations[profile] = pi
+		if me.DisplayName == "" {
+			me.DisplayName = id
+		}
+		st.Mods[id] = me
+		return m.SaveState(st)
+	})
+	return res, err
+}
+
+// copyFile copies src -> dst (overwrites).
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
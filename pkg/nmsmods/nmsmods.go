@@ -0,0 +1,291 @@
+// Package nmsmods is the embeddable API behind the nmsmods command: a Manager that downloads,
+// installs, enables and deploys No Man's Sky mods in a data directory, with the same state
+// files, profiles, lock and history as the CLI. The cobra commands are thin wrappers over it.
+//
+// Every operation that changes state takes the data directory's lock and records an entry in
+// history.jsonl, so 'nmsmods undo' works on changes made through the library too.
+package nmsmods
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"nmsmods/internal/app"
+	"nmsmods/internal/mods"
+	"nmsmods/internal/nms"
+)
+
+// Types shared with the on-disk format. These are aliases of types declared in internal
+// packages, not types of their own: callers can name and use them without importing those
+// packages, but they follow every change to the file format, and their fields are documented
+// on the internal declarations.
+type (
+	Paths           = app.Paths
+	Config          = app.Config
+	State           = app.State
+	ModEntry        = app.ModEntry
+	ProfileInstall  = app.ProfileInstall
+	NexusInfo       = app.NexusInfo
+	ProfileSettings = app.ProfileSettings
+	ResolvedProfile = app.ResolvedProfile
+	TrashItem       = app.TrashItem
+	Game            = nms.Game
+	DeployOptions   = mods.DeployOptions
+	FileConflict    = mods.Conflict
+)
+
+// DefaultPaths returns the data directory layout (home "" = NMSMODS_HOME or the XDG default)
+// and creates its directories.
+func DefaultPaths(home string) (*Paths, error) {
+	p, err := app.DefaultPathsWithOverride(home)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.Ensure(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Manager runs operations against one data directory.
+type Manager struct {
+	paths       *Paths
+	profile     string
+	target      string
+	commandLine string
+	confirm     ConfirmFunc
+	progress    func(string)
+	warn        func(string)
+	logger      *slog.Logger
+}
+
+// Option configures a Manager.
+type Option func(*Manager)
+
+// WithProfile scopes the Manager to a profile other than the active one. Changes then stay in
+// that profile's store and state; only the active profile deploys to GAMEDATA/MODS.
+func WithProfile(name string) Option {
+	return func(m *Manager) { m.profile = strings.TrimSpace(name) }
+}
+
+// WithGameTarget uses a named game target instead of the profile's or the default one.
+func WithGameTarget(name string) Option {
+	return func(m *Manager) { m.target = strings.TrimSpace(name) }
+}
+
+// WithProgress receives one line per step of long operations ("Extracting to: ...").
+func WithProgress(fn func(string)) Option {
+	return func(m *Manager) { m.progress = fn }
+}
+
+// WithWarnings receives non-fatal problems (a recovered state file, trash retention failures).
+func WithWarnings(fn func(string)) Option {
+	return func(m *Manager) { m.warn = fn }
+}
+
+// WithLogger sends the Manager's structured logs (progress, warnings, Nexus requests) to l
+// instead of slog.Default(). Lower layers (file operations, state saves) log to slog.Default().
+func WithLogger(l *slog.Logger) Option {
+	return func(m *Manager) { m.logger = l }
+}
+
+// WithConfirm lets operations accept a mod argument that only loosely matches a display name
+// when fn confirms it (see ResolveModConfirm). Without it such matches fail with suggestions.
+func WithConfirm(fn ConfirmFunc) Option {
+	return func(m *Manager) { m.confirm = fn }
+}
+
+// WithCommandLine sets the text history records for operations (default: "nmsmods <op> <args>").
+func WithCommandLine(s string) Option {
+	return func(m *Manager) { m.commandLine = s }
+}
+
+// New returns a Manager for p. p must exist (see DefaultPaths).
+func New(p *Paths, opts ...Option) *Manager {
+	m := &Manager{paths: p}
+	for _, o := range opts {
+		o(m)
+	}
+	return m
+}
+
+// Paths returns the data directory layout the Manager works on.
+func (m *Manager) Paths() *Paths { return m.paths }
+
+func (m *Manager) log() *slog.Logger {
+	if m.logger != nil {
+		return m.logger
+	}
+	return slog.Default()
+}
+
+func (m *Manager) progressf(format string, a ...any) {
+	msg := fmt.Sprintf(format, a...)
+	m.log().Debug(msg)
+	if m.progress != nil {
+		m.progress(msg)
+	}
+}
+
+func (m *Manager) warnf(format string, a ...any) {
+	msg := fmt.Sprintf(format, a...)
+	m.log().Warn(msg)
+	if m.warn != nil {
+		m.warn(msg)
+	}
+}
+
+// command returns the history text for an operation.
+func (m *Manager) command(op string, args ...string) string {
+	if m.commandLine != "" {
+		return m.commandLine
+	}
+	return strings.Join(append([]string{"nmsmods", op}, args...), " ")
+}
+
+// LoadConfig reads config.json.
+func (m *Manager) LoadConfig() (Config, error) {
+	return app.LoadConfig(m.paths.Config)
+}
+
+// LoadState reads the full state from the active backend (state.json or state.db).
+func (m *Manager) LoadState() (State, error) {
+	s, err := app.OpenStateStore(m.paths)
+	if err != nil {
+		return State{}, err
+	}
+	defer s.Close()
+	st, err := s.Load()
+	if js, ok := s.(*app.JSONStore); ok && js.Recovered != nil {
+		r := js.Recovered
+		m.warnf("%v", r.Cause)
+		m.warnf("restored state from %s; the unreadable file was kept as %s", r.From, r.Corrupt)
+	}
+	return st, err
+}
+
+// SaveState writes st to the active backend in one transaction.
+func (m *Manager) SaveState(st State) error {
+	s, err := app.OpenStateStore(m.paths)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	return s.Save(st)
+}
+
+// Profile is the profile the Manager operates on: WithProfile, else active_profile.
+func (m *Manager) Profile(cfg Config) string {
+	if m.profile != "" {
+		return m.profile
+	}
+	return app.ActiveProfile(cfg)
+}
+
+// ResolveProfile returns the Manager's profile with its profile.json settings.
+func (m *Manager) ResolveProfile(cfg Config) (ResolvedProfile, error) {
+	return app.ResolveProfile(m.paths, cfg, m.profile)
+}
+
+// ProfileDeploys reports whether the Manager operates on the active profile. Only then do
+// changes reach GAMEDATA/MODS.
+func (m *Manager) ProfileDeploys(cfg Config) bool {
+	return m.Profile(cfg) == app.ActiveProfile(cfg)
+}
+
+// ResolveGame validates the game target for the Manager's profile (see app.ResolveTarget)
+// without creating GAMEDATA/MODS.
+func (m *Manager) ResolveGame(cfg Config) (*Game, error) {
+	prof, err := m.ResolveProfile(cfg)
+	if err != nil {
+		return nil, err
+	}
+	name, err := app.ResolveTarget(cfg, prof.Settings.Target, m.target)
+	if err != nil {
+		return nil, &GameNotFoundError{Target: name, Err: err}
+	}
+	path, _ := app.TargetPath(cfg, name)
+	game, err := nms.ValidateGamePath(path)
+	if err != nil {
+		if name == app.DefaultTargetName {
+			return nil, &GameNotFoundError{Target: name, Err: fmt.Errorf("invalid game path: %w", err)}
+		}
+		return nil, &GameNotFoundError{Target: name, Err: fmt.Errorf("invalid game path for target %q: %w", name, err)}
+	}
+	game.Target = name
+	return game, nil
+}
+
+// PrepareGame is ResolveGame plus creating GAMEDATA/MODS. Call it again after switching
+// profiles: the new profile may be bound to another target.
+func (m *Manager) PrepareGame(cfg Config) (*Game, error) {
+	game, err := m.ResolveGame(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := nms.EnsureModsDir(game); err != nil {
+		return nil, err
+	}
+	return game, nil
+}
+
+// RequireGame loads config and prepares its game.
+func (m *Manager) RequireGame() (*Config, *Game, error) {
+	cfg, err := m.LoadConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	game, err := m.PrepareGame(cfg)
+	if err != nil {
+		return &cfg, nil, err
+	}
+	return &cfg, game, nil
+}
+
+// EnsureProfileDirs creates the Manager's profile directories and returns its name.
+func (m *Manager) EnsureProfileDirs(cfg Config) (string, error) {
+	prof := m.Profile(cfg)
+	if err := app.EnsureProfileDirs(m.paths, prof); err != nil {
+		return "", err
+	}
+	return prof, nil
+}
+
+// CheckGameNotRunning refuses changes to GAMEDATA/MODS while No Man's Sky is running:
+// swapping files mid-session corrupts loads. It returns a *GameRunningError.
+func CheckGameNotRunning() error {
+	procs, err := nms.RunningGameProcesses()
+	if err != nil || len(procs) == 0 {
+		// Detection is best-effort; never block on a /proc read error.
+		return nil
+	}
+	pids := make([]int, 0, len(procs))
+	for _, pr := range procs {
+		pids = append(pids, pr.PID)
+	}
+	return &GameRunningError{PIDs: pids}
+}
+
+// SortedModIDs returns state ids in the order ListMods lists them.
+func SortedModIDs(st State) []string {
+	ids := make([]string, 0, len(st.Mods))
+	for id := range st.Mods {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// abs turns a root-relative state path into an absolute one.
+func (m *Manager) abs(rel string) string {
+	return filepath.Join(m.paths.Root, filepath.FromSlash(rel))
+}
+
+func fileExists(p string) bool {
+	_, err := os.Stat(p)
+	return err == nil
+}
@@ -0,0 +1,536 @@
+package nmsmods
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"nmsmods/internal/app"
+	"nmsmods/internal/mods"
+)
+
+func newTestManager(t *testing.T) (*Manager, string) {
+	t.Helper()
+	root := t.TempDir()
+	game := filepath.Join(root, "game")
+	for _, d := range []string{"Binaries", "GAMEDATA/PCBANKS"} {
+		if err := os.MkdirAll(filepath.Join(game, d), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(game, "GAMEDATA", "PCBANKS", "NMSARC.Base.pak"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := DefaultPaths(filepath.Join(root, "home"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := app.SaveConfig(p.Config, Config{GamePath: game}); err != nil {
+		t.Fatal(err)
+	}
+
+	zipPath := filepath.Join(root, "MyMod.zip")
+	f, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	w, err := zw.Create("MyMod/METADATA/A.MBIN")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("x"))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	return New(p), zipPath
+}
+
+func TestManager_DownloadInstallToggle(t *testing.T) {
+	ctx := context.Background()
+	m, zipPath := newTestManager(t)
+
+	dl, err := m.Download(ctx, zipPath, "my-mod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dl.ID != "my-mod" || dl.Source != "local" {
+		t.Fatalf("unexpected download result: %+v", dl)
+	}
+
+	res, err := m.Install(ctx, "1", InstallOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Folder == "" || res.DeployedPath == "" || !res.Enabled || res.Health != "ok" {
+		t.Fatalf("unexpected install result: %+v", res)
+	}
+	if _, err := os.Stat(res.DeployedPath); err != nil {
+		t.Fatalf("expected deployed folder: %v", err)
+	}
+
+	off, err := m.Disable(ctx, "my-mod")
+	if err != nil || off.Unchanged {
+		t.Fatalf("disable: %+v, %v", off, err)
+	}
+	if _, err := os.Stat(res.DeployedPath); !os.IsNotExist(err) {
+		t.Fatalf("expected deployed folder to be removed, got %v", err)
+	}
+	if again, err := m.Disable(ctx, "my-mod"); err != nil || !again.Unchanged {
+		t.Fatalf("second disable: %+v, %v", again, err)
+	}
+
+	list, err := m.ListMods(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || !list[0].Install.Installed || list[0].Install.Enabled {
+		t.Fatalf("unexpected list: %+v", list)
+	}
+
+	entries, err := app.ReadHistory(m.Paths())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 || !strings.HasPrefix(entries[0].Command, "nmsmods download ") || entries[1].Command != "nmsmods install 1" {
+		t.Fatalf("expected download, install and disable in history, got %+v", entries)
+	}
+
+	if _, err := m.Download(ctx, zipPath, "../escape"); !errors.As(err, new(*SelectorError)) {
+		t.Fatalf("download with an unsafe id: %v", err)
+	}
+}
+
+func TestManager_TypedErrors(t *testing.T) {
+	ctx := context.Background()
+	m, zipPath := newTestManager(t)
+
+	var unknown *UnknownModError
+	if _, err := m.Enable(ctx, "nope"); !errors.As(err, &unknown) || unknown.ID != "nope" {
+		t.Fatalf("expected UnknownModError, got %v", err)
+	}
+
+	if _, err := m.Download(ctx, zipPath, "my-mod"); err != nil {
+		t.Fatal(err)
+	}
+	var notInstalled *NotInstalledError
+	if _, err := m.Enable(ctx, "my-mod"); !errors.As(err, &notInstalled) || notInstalled.Profile != "default" {
+		t.Fatalf("expected NotInstalledError, got %v", err)
+	}
+
+	var noGame *GameNotFoundError
+	if _, err := New(m.Paths(), WithGameTarget("nope")).Enable(ctx, "my-mod"); !errors.As(err, &noGame) || noGame.Target != "nope" {
+		t.Fatalf("expected GameNotFoundError, got %v", err)
+	}
+
+	l, err := app.AcquireLock(m.Paths().Root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Release()
+	if _, err := m.Install(ctx, "my-mod", InstallOptions{}); !errors.Is(err, ErrLockBusy) {
+		t.Fatalf("expected ErrLockBusy, got %v", err)
+	}
+}
+
+func TestManager_SelectAndBatchToggle(t *testing.T) {
+	ctx := context.Background()
+	m, zipPath := newTestManager(t)
+	for _, id := range []string{"a-mod", "b-mod"} {
+		if _, err := m.Download(ctx, zipPath, id); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := m.Install(ctx, id, InstallOptions{Disabled: id == "b-mod"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	res, err := m.EnableMods(ctx, Selector{Args: []string{"*-mod"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 2 || !res[0].Unchanged || res[1].Unchanged || res[1].DeployedPath == "" {
+		t.Fatalf("unexpected batch enable: %+v", res)
+	}
+	if _, err := m.DisableMods(ctx, Selector{All: true, Installed: true}); err != nil {
+		t.Fatal(err)
+	}
+	st, err := m.LoadState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for id, me := range st.Mods {
+		if pi := me.Installations["default"]; pi.Enabled || pi.DeployedPath != "" {
+			t.Fatalf("%s still enabled: %+v", id, pi)
+		}
+	}
+	entries, err := app.ReadHistory(m.Paths())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last := entries[len(entries)-1]; len(last.Changes) != 2 {
+		t.Fatalf("expected one history entry for the batch, got %+v", last)
+	}
+
+	if ids, err := m.Select(st, Selector{Source: "url"}); err != nil || len(ids) != 0 {
+		t.Fatalf("source filter: %v, %v", ids, err)
+	}
+	var selErr *SelectorError
+	if _, err := m.Select(st, Selector{All: true, Args: []string{"a-mod"}}); !errors.As(err, &selErr) {
+		t.Fatalf("expected SelectorError, got %v", err)
+	}
+}
+
+func TestManager_TagsAndNotes(t *testing.T) {
+	ctx := context.Background()
+	m, zipPath := newTestManager(t)
+	if _, err := m.Download(ctx, zipPath, "a-mod"); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := m.AddTags(ctx, "a-mod", "Visual", "qol", "visual")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(res.Tags, ",") != "qol,visual" {
+		t.Fatalf("tags not normalized: %v", res.Tags)
+	}
+	if res, err = m.RemoveTags(ctx, "1", "qol", "missing"); err != nil || strings.Join(res.Tags, ",") != "visual" {
+		t.Fatalf("remove: %+v, %v", res, err)
+	}
+	var selErr *SelectorError
+	if _, err := m.AddTags(ctx, "a-mod", "two words"); !errors.As(err, &selErr) {
+		t.Fatalf("expected SelectorError for an invalid tag, got %v", err)
+	}
+	if res, err = m.SetNote(ctx, "a-mod", "  needs the 5.0 patch "); err != nil || res.Notes != "needs the 5.0 patch" {
+		t.Fatalf("note: %+v, %v", res, err)
+	}
+
+	st, err := m.LoadState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	me := st.Mods["a-mod"]
+	me.Nexus = &NexusInfo{ModCategory: "Visuals"}
+	st.Mods["a-mod"] = me
+	if ids, err := m.Select(st, Selector{Tags: []string{"VISUAL"}, Category: "visuals"}); err != nil || len(ids) != 1 {
+		t.Fatalf("tag and category filter: %v, %v", ids, err)
+	}
+	if ids, _ := m.Select(st, Selector{Category: "Gameplay"}); len(ids) != 0 {
+		t.Fatalf("category filter matched %v", ids)
+	}
+}
+
+func TestResolveModConfirm(t *testing.T) {
+	st := State{Mods: map[string]ModEntry{
+		"better-flight": {Handle: 4, DisplayName: "Better Flight", Nexus: &NexusInfo{ModID: 1234}},
+		"better-fog":    {Handle: 9, DisplayName: "Better Fog"},
+		"42":            {Handle: 1, DisplayName: "Answer"},
+	}}
+	for arg, want := range map[string]string{
+		"42":        "42", // an exact id beats a handle
+		"4":         "better-flight",
+		"nx:1234":   "better-flight",
+		"NX:1234":   "better-flight",
+		"better-fl": "better-flight",
+	} {
+		if got, err := ResolveMod(st, arg); err != nil || got != want {
+			t.Errorf("ResolveMod(%q) = %q, %v; want %q", arg, got, err, want)
+		}
+	}
+
+	var ambiguous *AmbiguousModError
+	if _, err := ResolveMod(st, "better"); !errors.As(err, &ambiguous) || len(ambiguous.Candidates) != 2 {
+		t.Fatalf("expected AmbiguousModError, got %v", err)
+	}
+	var unknown *UnknownModError
+	if _, err := ResolveMod(st, "2"); !errors.As(err, &unknown) {
+		t.Fatalf("unused handle: %v", err)
+	}
+
+	// Display-name matches need confirmation.
+	asked := ""
+	yes := func(arg, id, name string) bool { asked = id; return true }
+	if got, err := ResolveModConfirm(st, "Better  FOG", yes); err != nil || got != "better-fog" || asked != "better-fog" {
+		t.Fatalf("confirmed fuzzy match: %q, %v (asked %q)", got, err, asked)
+	}
+	if _, err := ResolveModConfirm(st, "flight", nil); !errors.As(err, &unknown) || len(unknown.Suggestions) != 1 {
+		t.Fatalf("unconfirmed fuzzy match: %v", err)
+	}
+}
+
+func TestManager_FsckFindsAndRepairsDrift(t *testing.T) {
+	ctx := context.Background()
+	m, zipPath := newTestManager(t)
+	if _, err := m.Download(ctx, zipPath, "my-mod"); err != nil {
+		t.Fatal(err)
+	}
+	res, err := m.Install(ctx, "my-mod", InstallOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := m.Paths()
+	st, err := m.LoadState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	me := st.Mods["my-mod"]
+
+	// Drift: the download and the store are gone, an untracked download appeared, and a copy
+	// of the deployed folder sits under another name.
+	if err := os.Remove(m.abs(me.ZIP)); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll(res.StorePath); err != nil {
+		t.Fatal(err)
+	}
+	stray := filepath.Join(p.Downloads, "stray.zip")
+	if err := os.WriteFile(stray, []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	strayFolder := filepath.Join(filepath.Dir(res.DeployedPath), "Stray")
+	if err := mods.CopyDir(res.DeployedPath, strayFolder); err != nil {
+		t.Fatal(err)
+	}
+
+	rep, err := m.Fsck(ctx, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var kinds []string
+	for _, is := range rep.Issues {
+		kinds = append(kinds, is.Kind)
+	}
+	want := []string{FsckMissingZip, FsckOrphanDownload, FsckMissingStore, FsckOrphanMarker}
+	if rep.OK || strings.Join(kinds, ",") != strings.Join(want, ",") {
+		t.Fatalf("findings: ok=%v %v, want %v", rep.OK, kinds, want)
+	}
+	if rep.Issues[2].Repair != "rebuild the store from the deployed copy in GAMEDATA/MODS" {
+		t.Fatalf("missing store repair: %q", rep.Issues[2].Repair)
+	}
+
+	rep, err = m.Fsck(ctx, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, is := range rep.Issues {
+		if !is.Fixed {
+			t.Errorf("%s not fixed: %s", is.Kind, is.FixError)
+		}
+	}
+	if !rep.OK {
+		t.Fatal("report not OK after fix")
+	}
+
+	st, err = m.LoadState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := st.Mods["my-mod"]; got.ZIP != "" || !got.Installations["default"].Installed {
+		t.Fatalf("state after fix: %+v", got)
+	}
+	if _, err := os.Stat(filepath.Join(res.StorePath, "A.MBIN")); err != nil {
+		t.Fatalf("store not rebuilt: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(res.StorePath, mods.ManagedMarkerFile)); !os.IsNotExist(err) {
+		t.Fatalf("rebuilt store keeps the marker: %v", err)
+	}
+	for _, gone := range []string{stray, strayFolder} {
+		if _, err := os.Stat(gone); !os.IsNotExist(err) {
+			t.Fatalf("%s not moved to the trash: %v", gone, err)
+		}
+	}
+	if hist, err := app.ReadHistory(p); err != nil || len(hist) == 0 || hist[len(hist)-1].Command != "nmsmods fsck --fix" {
+		t.Fatalf("fix not recorded in history: %v", err)
+	}
+
+	if rep, err = m.Fsck(ctx, false); err != nil || !rep.OK || len(rep.Issues) != 0 {
+		t.Fatalf("fsck after fix: %+v, %v", rep, err)
+	}
+}
+
+// installZip downloads and installs a one-file mod archive with the given top folder.
+// writeModZip writes a zip holding top/<ID>.MBIN to zipPath.
+func writeModZip(t *testing.T, zipPath, id, top string) {
+	t.Helper()
+	f, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	w, err := zw.Create(top + "/" + strings.ToUpper(id) + ".MBIN")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte(id))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+}
+
+func installZip(t *testing.T, m *Manager, id, top string) InstallResult {
+	t.Helper()
+	zipPath := filepath.Join(t.TempDir(), top+".zip")
+	writeModZip(t, zipPath, id, top)
+	if _, err := m.Download(context.Background(), zipPath, id); err != nil {
+		t.Fatal(err)
+	}
+	res, err := m.Install(context.Background(), id, InstallOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func TestManager_MoveInLoadOrder(t *testing.T) {
+	ctx := context.Background()
+	m, _ := newTestManager(t)
+	for _, id := range []string{"a", "b", "c", "d"} {
+		installZip(t, m, id, id+"mod")
+	}
+	folders := func() []string {
+		st, err := m.LoadState()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out []string
+		for _, id := range LoadOrder(st, "default") {
+			pi := st.Mods[id].Installations["default"]
+			if !fileExists(m.abs(pi.Store)) || !fileExists(pi.DeployedPath) || filepath.Base(pi.DeployedPath) != pi.Folder {
+				t.Fatalf("%s: state does not match its folders: %+v", id, pi)
+			}
+			out = append(out, pi.Folder)
+		}
+		return out
+	}
+
+	// Moving d to the front renames d and the mods it passes; unprefixed names no longer sort
+	// behind the prefixed ones, so everything is renamed the first time.
+	if _, err := m.MoveInLoadOrder(ctx, "d", 1); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Join(folders(), ","), "01-dmod,02-amod,03-bmod,04-cmod"; got != want {
+		t.Fatalf("folders = %s, want %s", got, want)
+	}
+
+	// Swapping b and c only touches those two.
+	res, err := m.MoveInLoadOrder(ctx, "c", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(res.Renamed, ","); got != "c,b" {
+		t.Fatalf("renamed %s, want c,b", got)
+	}
+	if got, want := strings.Join(folders(), ","), "01-dmod,02-amod,03-cmod,04-bmod"; got != want {
+		t.Fatalf("folders = %s, want %s", got, want)
+	}
+
+	// Staying in place changes nothing.
+	if res, err := m.MoveInLoadOrder(ctx, "a", 2); err != nil || len(res.Renamed) != 0 {
+		t.Fatalf("no-op move: %+v, %v", res, err)
+	}
+
+	// A folder in the way of the second rename fails the move part way: the first mod is saved
+	// renamed, the second keeps its old folder and deployment.
+	st, _ := m.LoadState()
+	modsDir := filepath.Dir(st.Mods["a"].Installations["default"].DeployedPath)
+	if err := os.MkdirAll(filepath.Join(modsDir, "02-dmod"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.MoveInLoadOrder(ctx, "a", 1); err == nil {
+		t.Fatal("move into an unmanaged folder succeeded")
+	}
+	if err := os.Remove(filepath.Join(modsDir, "02-dmod")); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Join(folders(), ","), "01-amod,01-dmod,03-cmod,04-bmod"; got != want {
+		t.Fatalf("folders after failed move = %s, want %s", got, want)
+	}
+}
+
+func TestManager_ReinstallAndUninstall(t *testing.T) {
+	ctx := context.Background()
+	m, _ := newTestManager(t)
+	a := installZip(t, m, "a", "amod")
+	installZip(t, m, "b", "bmod")
+	installZip(t, m, "c", "cmod")
+
+	re, err := m.Reinstall(ctx, "a", ReinstallOptions{})
+	if err != nil || re.DeployedPath != a.DeployedPath || !re.Enabled {
+		t.Fatalf("reinstall: %+v, %v", re, err)
+	}
+	if all, err := m.ReinstallMods(ctx, Selector{All: true, Installed: true}, ReinstallOptions{}); err != nil || len(all) != 3 || all[2].DeployedPath == "" {
+		t.Fatalf("reinstall --all: %+v, %v", all, err)
+	}
+
+	// A deployed folder name resolves to its mod; the store goes to the trash.
+	res, err := m.Uninstall(ctx, filepath.Base(a.DeployedPath), UninstallOptions{})
+	if err != nil || res.ID != "a" {
+		t.Fatalf("uninstall by folder: %+v, %v", res, err)
+	}
+	if fileExists(a.DeployedPath) || fileExists(a.StorePath) {
+		t.Fatal("uninstalled mod left its folders")
+	}
+
+	// An untracked folder goes to the trash too.
+	manual := filepath.Join(filepath.Dir(a.DeployedPath), "manual")
+	if err := os.MkdirAll(manual, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if res, err = m.Uninstall(ctx, "manual", UninstallOptions{}); err != nil || res.ID != "" || res.TrashID == "" || fileExists(manual) {
+		t.Fatalf("uninstall untracked: %+v, %v", res, err)
+	}
+
+	if dry, err := m.UninstallMods(ctx, Selector{Args: []string{"b", "c"}, Installed: true}, UninstallOptions{DryRun: true}); err != nil || len(dry) != 2 || !fileExists(dry[0].StorePath) {
+		t.Fatalf("dry-run batch: %+v, %v", dry, err)
+	}
+	if _, err := m.UninstallMods(ctx, Selector{Args: []string{"b", "a"}, Installed: true}, UninstallOptions{}); !errors.As(err, new(*NotInstalledError)) {
+		t.Fatalf("batch with a mod that is not installed: %v", err)
+	}
+	out, err := m.UninstallMods(ctx, Selector{All: true, Installed: true}, UninstallOptions{})
+	if err != nil || len(out) != 2 {
+		t.Fatalf("uninstall --all: %+v, %v", out, err)
+	}
+	st, _ := m.LoadState()
+	for id, me := range st.Mods {
+		if me.Installations["default"].Installed {
+			t.Errorf("%s still installed", id)
+		}
+	}
+}
+
+func TestManager_ReinstallRenamedFolder(t *testing.T) {
+	ctx := context.Background()
+	m, _ := newTestManager(t)
+	a := installZip(t, m, "a", "amod")
+	b := installZip(t, m, "b", "bmod")
+	st, err := m.LoadState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// New versions of both downloads unpack under another top-level folder.
+	writeModZip(t, m.abs(st.Mods["a"].ZIP), "a", "amod-v2")
+	writeModZip(t, m.abs(st.Mods["b"].ZIP), "b", "bmod-v2")
+
+	re, err := m.Reinstall(ctx, "a", ReinstallOptions{})
+	if err != nil || re.Folder != "amod-v2" || !fileExists(re.DeployedPath) {
+		t.Fatalf("reinstall: %+v, %v", re, err)
+	}
+	if fileExists(a.DeployedPath) {
+		t.Fatalf("old deployed folder left behind: %s", a.DeployedPath)
+	}
+
+	all, err := m.ReinstallMods(ctx, Selector{Args: []string{"b"}, Installed: true}, ReinstallOptions{})
+	if err != nil || len(all) != 1 || all[0].Folder != "bmod-v2" || !fileExists(all[0].DeployedPath) {
+		t.Fatalf("batch reinstall: %+v, %v", all, err)
+	}
+	if fileExists(b.DeployedPath) {
+		t.Fatalf("old deployed folder left behind: %s", b.DeployedPath)
+	}
+}
@@ -0,0 +1,199 @@
+package nmsmods
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+
+	"nmsmods/internal/app"
+	"nmsmods/internal/mods"
+)
+
+// orderPrefix matches the numeric prefix MoveInLoadOrder puts in front of folder names.
+var orderPrefix = regexp.MustCompile(`^[0-9]{2,4}-`)
+
+// LoadOrder returns the ids installed in profile, in the order the game loads their folders.
+func LoadOrder(st State, profile string) []string {
+	ids := []string{}
+	for _, id := range SortedModIDs(st) {
+		pi, ok := st.Mods[id].Installations[profile]
+		if ok && pi.Installed && pi.Folder != "" {
+			ids = append(ids, id)
+		}
+	}
+	sort.SliceStable(ids, func(i, j int) bool {
+		return st.Mods[ids[i]].Installations[profile].Folder < st.Mods[ids[j]].Installations[profile].Folder
+	})
+	return ids
+}
+
+// OrderResult describes a MoveInLoadOrder.
+type OrderResult struct {
+	ID       string `json:"id"`
+	Profile  string `json:"profile"`
+	Position int    `json:"position"`
+	// Renamed lists the mods whose folder got a new NN- prefix, in load order.
+	Renamed []string `json:"renamed"`
+}
+
+// MoveInLoadOrder moves an installed mod to a 1-based position of its profile's load order
+// (positions past the end mean last). The game loads GAMEDATA/MODS folders in name order, so
+// the mods whose position changes get folder names with a numeric prefix (01-, 02-, ...); the
+// others keep their folder unless the new names would not sort in order without renaming them
+// too. Each renamed mod is saved as soon as its store and deployment are moved, so a failure
+// part way leaves every mod's state matching its folders. Old stores go through the trash.
+func (m *Manager) MoveInLoadOrder(ctx context.Context, mod string, pos int) (OrderResult, error) {
+	var res OrderResult
+	if pos <= 0 {
+		return res, &SelectorError{Err: fmt.Errorf("invalid position: %d", pos)}
+	}
+	err := m.Update(m.command("order", mod, strconv.Itoa(pos)), func(tx *Tx) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		cfg, game, err := m.RequireGame()
+		if err != nil {
+			return err
+		}
+		if err := CheckGameNotRunning(); err != nil {
+			return err
+		}
+		profile, err := m.EnsureProfileDirs(*cfg)
+		if err != nil {
+			return err
+		}
+		st, err := m.LoadState()
+		if err != nil {
+			return err
+		}
+		id, err := m.Resolve(st, mod)
+		if err != nil {
+			return err
+		}
+
+		old := LoadOrder(st, profile)
+		from := slices.Index(old, id)
+		if from < 0 {
+			return &NotInstalledError{ID: id, Profile: profile}
+		}
+		if pos > len(old) {
+			pos = len(old)
+		}
+		res = OrderResult{ID: id, Profile: profile, Position: pos, Renamed: []string{}}
+		ids := append([]string{}, old[:from]...)
+		ids = append(ids, old[from+1:]...)
+		ids = append(ids[:pos-1], append([]string{id}, ids[pos-1:]...)...)
+
+		renames, err := m.orderRenames(st, profile, old, ids)
+		if err != nil {
+			return err
+		}
+		deploys := m.ProfileDeploys(*cfg)
+		for _, rid := range ids {
+			folder, ok := renames[rid]
+			if !ok {
+				continue
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			me := st.Mods[rid]
+			pi := me.Installations[profile]
+			oldStore := m.abs(pi.Store)
+			newStore := filepath.Join(app.ProfileModsDir(m.paths, profile), folder)
+			if err := mods.CopyDir(oldStore, newStore); err != nil {
+				_ = os.RemoveAll(newStore)
+				return err
+			}
+			// Put the mod back as it was when its move fails: state still names the old folder.
+			rollback := func(err error) error {
+				if pi.Enabled && deploys {
+					_ = mods.Undeploy(game.ModsDir, folder, rid, profile)
+				}
+				if pi.Enabled && pi.DeployedPath != "" && !fileExists(pi.DeployedPath) {
+					_, _ = mods.Deploy(oldStore, filepath.Dir(pi.DeployedPath), filepath.Base(pi.DeployedPath), rid, profile, m.DeployOptions(profile, game))
+				}
+				_ = os.RemoveAll(newStore)
+				return fmt.Errorf("failed to rename %s to %s: %w", rid, folder, err)
+			}
+
+			moved := pi
+			moved.Folder = folder
+			moved.Store = app.RelToRoot(m.paths, newStore)
+			moved.DeployedPath = ""
+			if pi.Enabled {
+				if err := m.UndeployInstall(*cfg, game, rid, profile, pi); err != nil {
+					return rollback(err)
+				}
+				if deploys {
+					if moved.DeployedPath, err = mods.Deploy(newStore, game.ModsDir, folder, rid, profile, m.DeployOptions(profile, game)); err != nil {
+						return rollback(err)
+					}
+				}
+			}
+			if err := tx.TrashStore(oldStore, rid); err != nil {
+				return rollback(err)
+			}
+			me.Installations[profile] = moved
+			st.Mods[rid] = me
+			if err := m.SaveState(st); err != nil {
+				return err
+			}
+			res.Renamed = append(res.Renamed, rid)
+		}
+		return nil
+	})
+	return res, err
+}
+
+// orderRenames returns the new folder of each mod that has to be renamed for the profile to
+// load in the order of ids. Only mods whose position changed are renamed, unless the folders
+// left alone would then sort out of order; then every folder gets its position prefix.
+func (m *Manager) orderRenames(st State, profile string, old, ids []string) (map[string]string, error) {
+	width := len(strconv.Itoa(len(ids)))
+	if width < 2 {
+		width = 2
+	}
+	prefixed := map[string]string{} // id -> NN-<folder>
+	for i, id := range ids {
+		pi := st.Mods[id].Installations[profile]
+		folder, err := mods.SanitizeFolderName(fmt.Sprintf("%0*d-%s", width, i+1, orderPrefix.ReplaceAllString(pi.Folder, "")), id)
+		if err != nil {
+			return nil, err
+		}
+		prefixed[id] = folder
+	}
+
+	folderOf := func(renames map[string]string, id string) string {
+		if f, ok := renames[id]; ok {
+			return f
+		}
+		return st.Mods[id].Installations[profile].Folder
+	}
+	renames := map[string]string{}
+	for i, id := range ids {
+		if old[i] != id && prefixed[id] != st.Mods[id].Installations[profile].Folder {
+			renames[id] = prefixed[id]
+		}
+	}
+	if !sort.SliceIsSorted(ids, func(i, j int) bool { return folderOf(renames, ids[i]) < folderOf(renames, ids[j]) }) {
+		renames = map[string]string{}
+		for _, id := range ids {
+			if prefixed[id] != st.Mods[id].Installations[profile].Folder {
+				renames[id] = prefixed[id]
+			}
+		}
+	}
+
+	for id, folder := range renames {
+		if fileExists(filepath.Join(app.ProfileModsDir(m.paths, profile), folder)) {
+			return nil, fmt.Errorf("cannot rename %s: %s already exists in the profile store", id, folder)
+		}
+	}
+	return renames, nil
+}
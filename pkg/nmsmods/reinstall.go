@@ -0,0 +1,232 @@
+package nmsmods
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"nmsmods/internal/app"
+	"nmsmods/internal/mods"
+)
+
+// ReinstallOptions adjusts Reinstall and ReinstallMods.
+type ReinstallOptions struct {
+	// NoOverwrite fails instead of replacing the existing store folder.
+	NoOverwrite bool
+	// DryRun only plans the reinstall: nothing is extracted, copied or deployed.
+	DryRun bool
+}
+
+// Reinstall replaces an installed mod's store folder from its download (the old folder goes
+// to the trash) and redeploys it when it is enabled and the profile is active. A mod that is
+// not installed in the profile yet is installed enabled.
+func (m *Manager) Reinstall(ctx context.Context, mod string, opts ReinstallOptions) (InstallResult, error) {
+	var res InstallResult
+	err := m.Update(m.command("reinstall", mod), func(tx *Tx) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		cfg, game, err := m.RequireGame()
+		if err != nil {
+			return err
+		}
+		if err := CheckGameNotRunning(); err != nil {
+			return err
+		}
+		profile, err := m.EnsureProfileDirs(*cfg)
+		if err != nil {
+			return err
+		}
+		st, err := m.LoadState()
+		if err != nil {
+			return err
+		}
+		id, err := m.Resolve(st, mod)
+		if err != nil {
+			return err
+		}
+		res, err = m.reinstallOne(tx, *cfg, game, profile, &st, id, opts, true)
+		return err
+	})
+	return res, err
+}
+
+// ReinstallMods reinstalls the mods sel selects as one operation with one redeploy of the
+// profile at the end. Mods without a recorded download are skipped.
+func (m *Manager) ReinstallMods(ctx context.Context, sel Selector, opts ReinstallOptions) ([]InstallResult, error) {
+	out := []InstallResult{}
+	err := m.Update(m.command("reinstall", sel.Args...), func(tx *Tx) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		cfg, game, err := m.RequireGame()
+		if err != nil {
+			return err
+		}
+		if err := CheckGameNotRunning(); err != nil {
+			return err
+		}
+		profile, err := m.EnsureProfileDirs(*cfg)
+		if err != nil {
+			return err
+		}
+		st, err := m.LoadState()
+		if err != nil {
+			return err
+		}
+		ids, err := m.Select(st, sel)
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			if st.Mods[id].ZIP == "" {
+				m.progressf("Skipped (no zip recorded): %s", id)
+				continue
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			res, err := m.reinstallOne(tx, *cfg, game, profile, &st, id, opts, false)
+			if err != nil {
+				return fmt.Errorf("%s: %w", id, err)
+			}
+			out = append(out, res)
+		}
+		if opts.DryRun || len(out) == 0 || !m.ProfileDeploys(*cfg) {
+			return nil
+		}
+		// One redeploy for the whole batch.
+		if err := m.DeployProfile(*cfg, game); err != nil {
+			return err
+		}
+		if st, err = m.LoadState(); err != nil {
+			return err
+		}
+		for i, r := range out {
+			out[i].DeployedPath = st.Mods[r.ID].Installations[profile].DeployedPath
+		}
+		return nil
+	})
+	return out, err
+}
+
+// reinstallOne replaces id's store folder in profile from its zip and saves st. With deploy
+// it also redeploys the mod; otherwise the caller redeploys the profile once for a batch (the
+// old deployment is removed here so a renamed folder does not linger).
+func (m *Manager) reinstallOne(tx *Tx, cfg Config, game *Game, profile string, st *State, id string, opts ReinstallOptions, deploy bool) (InstallResult, error) {
+	me := st.Mods[id]
+	if me.ZIP == "" {
+		return InstallResult{}, &NoDownloadError{ID: id}
+	}
+	zipAbs := m.abs(me.ZIP)
+
+	folderGuess, err := mods.ProposedInstallFolderFromZip(zipAbs, id)
+	if err != nil {
+		return InstallResult{}, err
+	}
+
+	if me.Installations == nil {
+		me.Installations = map[string]ProfileInstall{}
+	}
+	pi := me.Installations[profile]
+	destFolder := pi.Folder
+	if destFolder == "" {
+		destFolder = folderGuess
+	}
+	destFolder, _ = mods.ResolveFolderCollision(id, destFolder, profile, *st)
+
+	storeAbs := filepath.Join(app.ProfileModsDir(m.paths, profile), destFolder)
+	res := InstallResult{
+		ID: id, Profile: profile, ZIPPath: zipAbs, Folder: destFolder, StorePath: storeAbs,
+		StoreExisted: fileExists(storeAbs), DryRun: opts.DryRun, Game: game,
+	}
+	if m.ProfileDeploys(cfg) {
+		res.DeployPath = filepath.Join(game.ModsDir, destFolder)
+	}
+	if opts.DryRun {
+		return res, nil
+	}
+
+	if fileExists(storeAbs) {
+		if opts.NoOverwrite {
+			return res, fmt.Errorf("store exists: %s (use without --no-overwrite to replace it)", storeAbs)
+		}
+		m.progressf("Moving existing store to trash: %s", storeAbs)
+		if err := tx.TrashStore(storeAbs, id); err != nil {
+			return res, err
+		}
+	}
+
+	stageDir := filepath.Join(m.paths.Staging, id)
+	_ = os.RemoveAll(stageDir)
+	if err := os.MkdirAll(stageDir, 0o755); err != nil {
+		return res, err
+	}
+
+	m.progressf("Extracting to: %s", stageDir)
+	if err := mods.ExtractZip(zipAbs, stageDir); err != nil {
+		return res, err
+	}
+
+	folder, srcPath, err := mods.ChooseInstallFolder(stageDir, id)
+	if err != nil {
+		return res, err
+	}
+	folder, _ = mods.ResolveFolderCollision(id, folder, profile, *st)
+	storeAbs = filepath.Join(app.ProfileModsDir(m.paths, profile), folder)
+
+	m.progressf("Installing into profile store: %s", storeAbs)
+	if err := mods.CopyDir(srcPath, storeAbs); err != nil {
+		return res, err
+	}
+
+	// The old deployment goes by the old folder name: remove it before the new one is recorded,
+	// whenever the caller redeploys the profile or the zip now unpacks under another name.
+	prev := pi
+	if prev.Installed && prev.Folder != "" && (!deploy || prev.Folder != folder) {
+		if err := m.UndeployInstall(cfg, game, id, profile, prev); err != nil {
+			return res, err
+		}
+		pi.DeployedPath = ""
+	}
+
+	// Redeploy if enabled (default to enabled).
+	enabled := true
+	if prev.Installed {
+		enabled = prev.Enabled
+	}
+	pi.Installed = true
+	pi.Enabled = enabled
+	pi.Folder = folder
+	pi.Store = filepath.ToSlash(filepath.Join("profiles", profile, "mods", folder))
+	pi.InstalledAt = app.NowRFC3339()
+	if deploy && enabled && m.ProfileDeploys(cfg) {
+		deployed, err := mods.Deploy(storeAbs, game.ModsDir, folder, id, profile, m.DeployOptions(profile, game))
+		if err != nil {
+			return res, err
+		}
+		pi.DeployedPath = deployed
+	}
+
+	ok, verr := mods.HasRelevantFiles(storeAbs)
+	if verr != nil || !ok {
+		m.progressf("Warning: installed folder contains no .EXML/.MBIN files (or health check failed)")
+		me.Health = "warning"
+	} else {
+		me.Health = "ok"
+	}
+
+	me.Installations[profile] = pi
+	st.Mods[id] = me
+	if err := m.SaveState(*st); err != nil {
+		return res, err
+	}
+
+	res.Folder, res.StorePath = folder, storeAbs
+	res.DeployedPath, res.Enabled, res.Health = pi.DeployedPath, enabled, me.Health
+	if res.DeployPath != "" {
+		res.DeployPath = filepath.Join(game.ModsDir, folder)
+	}
+	return res, nil
+}
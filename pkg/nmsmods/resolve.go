@@ -0,0 +1,148 @@
+package nmsmods
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"nmsmods/internal/app"
+)
+
+// ConfirmFunc asks whether the mod whose display name loosely matched arg is the one meant.
+type ConfirmFunc func(arg, id, displayName string) bool
+
+// ResolveMod resolves a mod argument like ResolveModConfirm without confirming loose
+// display-name matches: they fail with *UnknownModError listing them as suggestions.
+func ResolveMod(st State, arg string) (string, error) {
+	return ResolveModConfirm(st, arg, nil)
+}
+
+// ResolveModConfirm resolves a mod argument to an id, trying in order:
+//
+//   - an exact id (even a numeric one);
+//   - a handle ("7"), the permanent number shown by nmsmods downloads;
+//   - "nx:<mod id>", the entry tracking that Nexus mod;
+//   - a prefix of exactly one id ("better-fl");
+//   - a display name, ignoring case, spaces and punctuation, as a whole, a prefix or a part,
+//     when confirm accepts the single best match.
+//
+// Several matches fail with *AmbiguousModError, none with *UnknownModError.
+func ResolveModConfirm(st State, arg string, confirm ConfirmFunc) (string, error) {
+	if _, ok := st.Mods[arg]; ok {
+		return arg, nil
+	}
+	if h, err := strconv.Atoi(arg); err == nil {
+		if id, ok := app.ModByHandle(st, h); ok {
+			return id, nil
+		}
+		return "", &UnknownModError{ID: arg}
+	}
+	if rest, ok := cutPrefixFold(arg, "nx:"); ok {
+		n, err := strconv.Atoi(rest)
+		if err != nil || n <= 0 {
+			return "", &SelectorError{Err: fmt.Errorf("invalid Nexus reference %q (use nx:<mod id>)", arg)}
+		}
+		return oneMatch(arg, matchIDs(st, func(_ string, me ModEntry) bool { return me.Nexus != nil && me.Nexus.ModID == n }))
+	}
+	if ids := matchIDs(st, func(id string, _ ModEntry) bool { return strings.HasPrefix(id, arg) }); len(ids) > 0 {
+		return oneMatch(arg, ids)
+	}
+
+	fuzzy := FuzzyMatches(st, arg)
+	switch {
+	case len(fuzzy) == 0:
+		return "", &UnknownModError{ID: arg}
+	case len(fuzzy) > 1:
+		return "", &AmbiguousModError{Arg: arg, Candidates: fuzzy}
+	}
+	id := fuzzy[0]
+	if confirm != nil && confirm(arg, id, st.Mods[id].DisplayName) {
+		return id, nil
+	}
+	return "", &UnknownModError{ID: arg, Suggestions: fuzzy}
+}
+
+// Resolve is ResolveModConfirm with the Manager's WithConfirm function.
+func (m *Manager) Resolve(st State, arg string) (string, error) {
+	return ResolveModConfirm(st, arg, m.confirm)
+}
+
+// FuzzyMatches returns the ids whose display name (or id, or Nexus mod name) best matches
+// arg with case, spaces and punctuation ignored: whole-name matches beat prefix matches, which
+// beat substring matches (arguments of 3+ characters only).
+func FuzzyMatches(st State, arg string) []string {
+	want := fold(arg)
+	if len(want) < 2 {
+		return nil
+	}
+	best, out := 0, []string{}
+	for _, id := range SortedModIDs(st) {
+		me := st.Mods[id]
+		score := 0
+		names := []string{me.DisplayName, id}
+		if me.Nexus != nil {
+			names = append(names, me.Nexus.ModName)
+		}
+		for _, name := range names {
+			got := fold(name)
+			switch {
+			case got == "":
+			case got == want:
+				score = max(score, 3)
+			case strings.HasPrefix(got, want):
+				score = max(score, 2)
+			case len(want) >= 3 && strings.Contains(got, want):
+				score = max(score, 1)
+			}
+		}
+		switch {
+		case score == 0 || score < best:
+		case score > best:
+			best, out = score, []string{id}
+		default:
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// fold keeps only lower-cased letters and digits.
+func fold(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func matchIDs(st State, match func(id string, me ModEntry) bool) []string {
+	ids := []string{}
+	for id, me := range st.Mods {
+		if match(id, me) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func oneMatch(arg string, ids []string) (string, error) {
+	switch len(ids) {
+	case 0:
+		return "", &UnknownModError{ID: arg}
+	case 1:
+		return ids[0], nil
+	}
+	return "", &AmbiguousModError{Arg: arg, Candidates: ids}
+}
+
+func cutPrefixFold(s, prefix string) (string, bool) {
+	if len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix) {
+		return s[len(prefix):], true
+	}
+	return s, false
+}
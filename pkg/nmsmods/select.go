@@ -0,0 +1,141 @@
+package nmsmods
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"nmsmods/internal/app"
+)
+
+// Selector picks mods for batch operations. Args are mod arguments (see ResolveModConfirm) or
+// glob patterns on ids ("better-*"); the filters narrow them down, or select among all mods
+// when Args is empty.
+type Selector struct {
+	Args     []string
+	All      bool     // every tracked mod (instead of Args)
+	Source   string   // "nexus", "url" or "local"
+	Health   string   // "ok" or "warning"
+	Tags     []string // any of these tags
+	Category string   // Nexus mod category, case-insensitive
+	Outdated bool     // the last update check (nexus check-updates) found a newer file
+	// Installed drops mods not installed in the Manager's profile from --all, glob and filter
+	// matches. Mods named by id or handle are kept, so the operation can report them.
+	Installed bool
+}
+
+// HasFilters reports whether any filter flag is set.
+func (s Selector) HasFilters() bool {
+	return s.Source != "" || s.Health != "" || len(s.Tags) > 0 || s.Category != "" || s.Outdated
+}
+
+// IsGlob reports whether a mod argument is a glob pattern rather than an id or handle.
+func IsGlob(arg string) bool { return strings.ContainsAny(arg, "*?[") }
+
+// Select resolves sel against st and returns the matching ids in id order. A glob or
+// filter that matches nothing is not an error; the result may be empty. Unusable selectors
+// fail with *SelectorError, unknown ids or handles with *UnknownModError.
+func (m *Manager) Select(st State, sel Selector) ([]string, error) {
+	if sel.All && len(sel.Args) > 0 {
+		return nil, &SelectorError{Err: fmt.Errorf("use either mod arguments or --all, not both")}
+	}
+	if !sel.All && len(sel.Args) == 0 && !sel.HasFilters() {
+		return nil, &SelectorError{Err: fmt.Errorf("no mods selected (give ids, handles or globs, --all, or a filter)")}
+	}
+	switch sel.Source {
+	case "", "nexus", "url", "local":
+	default:
+		return nil, &SelectorError{Err: fmt.Errorf("invalid --source %q (use nexus, url or local)", sel.Source)}
+	}
+	switch sel.Health {
+	case "", "ok", "warning":
+	default:
+		return nil, &SelectorError{Err: fmt.Errorf("invalid --health %q (use ok or warning)", sel.Health)}
+	}
+
+	picked := map[string]bool{} // id -> named explicitly
+	if len(sel.Args) == 0 {
+		for id := range st.Mods {
+			picked[id] = false
+		}
+	}
+	for _, a := range sel.Args {
+		if IsGlob(a) {
+			if _, err := path.Match(a, ""); err != nil {
+				return nil, &SelectorError{Err: fmt.Errorf("invalid pattern %q: %w", a, err)}
+			}
+			for id := range st.Mods {
+				if ok, _ := path.Match(a, id); ok && !picked[id] {
+					picked[id] = false
+				}
+			}
+			continue
+		}
+		id, err := m.Resolve(st, a)
+		if err != nil {
+			return nil, err
+		}
+		picked[id] = true
+	}
+
+	profile := ""
+	if sel.Installed {
+		cfg, err := m.LoadConfig()
+		if err != nil {
+			return nil, err
+		}
+		profile = m.Profile(cfg)
+	}
+	var uc app.UpdateCheck
+	if sel.Outdated {
+		var err error
+		if uc, err = app.LoadUpdateCheck(m.paths); err != nil {
+			return nil, err
+		}
+	}
+	out := []string{}
+	for _, id := range SortedModIDs(st) {
+		explicit, ok := picked[id]
+		if !ok {
+			continue
+		}
+		me := st.Mods[id]
+		if sel.Installed && !explicit && !me.Installations[profile].Installed {
+			continue
+		}
+		if !sel.Match(me) {
+			continue
+		}
+		if sel.Outdated && !uc.Outdated(id, me) {
+			continue
+		}
+		out = append(out, id)
+	}
+	return out, nil
+}
+
+// Match reports whether me passes the Source, Health, Tags and Category filters. Outdated
+// needs the last update check and is applied by Select only.
+func (s Selector) Match(me ModEntry) bool {
+	if s.Source != "" && me.Source != s.Source {
+		return false
+	}
+	if s.Health != "" && me.Health != s.Health {
+		return false
+	}
+	if len(s.Tags) > 0 && !hasAnyTag(me, s.Tags) {
+		return false
+	}
+	return s.Category == "" || strings.EqualFold(me.Category(), s.Category)
+}
+
+func hasAnyTag(me ModEntry, tags []string) bool {
+	for _, want := range tags {
+		for _, t := range me.Tags {
+			if strings.EqualFold(t, want) {
+				return true
+			}
+		}
+	}
+	return false
+}
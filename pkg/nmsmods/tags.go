@@ -0,0 +1,118 @@
+package nmsmods
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MetaResult describes a mod's tags and notes after AddTags, RemoveTags or SetNote.
+type MetaResult struct {
+	ID        string   `json:"id"`
+	Tags      []string `json:"tags"`
+	Notes     string   `json:"notes,omitempty"`
+	Unchanged bool     `json:"unchanged,omitempty"`
+}
+
+// NormalizeTag lower-cases a tag and checks it is usable in --tag filters: letters, digits and
+// . _ - : / only.
+func NormalizeTag(tag string) (string, error) {
+	t := strings.ToLower(strings.TrimSpace(tag))
+	if t == "" {
+		return "", &SelectorError{Err: fmt.Errorf("empty tag")}
+	}
+	for _, r := range t {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || strings.ContainsRune("._-:/", r) {
+			continue
+		}
+		return "", &SelectorError{Err: fmt.Errorf("invalid tag %q (use letters, digits and . _ - : /)", tag)}
+	}
+	return t, nil
+}
+
+// AddTags adds tags to a mod (id or handle). Tags are stored lower case, sorted and once each.
+func (m *Manager) AddTags(ctx context.Context, mod string, tags ...string) (MetaResult, error) {
+	return m.editTags(ctx, "tag add", mod, tags, true)
+}
+
+// RemoveTags removes tags from a mod. Tags the mod does not have are ignored.
+func (m *Manager) RemoveTags(ctx context.Context, mod string, tags ...string) (MetaResult, error) {
+	return m.editTags(ctx, "tag rm", mod, tags, false)
+}
+
+func (m *Manager) editTags(ctx context.Context, op, mod string, tags []string, add bool) (MetaResult, error) {
+	norm := make([]string, 0, len(tags))
+	for _, t := range tags {
+		n, err := NormalizeTag(t)
+		if err != nil {
+			return MetaResult{}, err
+		}
+		norm = append(norm, n)
+	}
+	return m.editMeta(ctx, m.command(op, append([]string{mod}, norm...)...), mod, func(me *ModEntry) {
+		set := map[string]bool{}
+		for _, t := range me.Tags {
+			set[t] = true
+		}
+		for _, t := range norm {
+			set[t] = add
+		}
+		me.Tags = nil
+		for t, ok := range set {
+			if ok {
+				me.Tags = append(me.Tags, t)
+			}
+		}
+		sort.Strings(me.Tags)
+	})
+}
+
+// SetNote replaces a mod's notes; "" clears them.
+func (m *Manager) SetNote(ctx context.Context, mod, text string) (MetaResult, error) {
+	text = strings.TrimSpace(text)
+	return m.editMeta(ctx, m.command("note", mod), mod, func(me *ModEntry) { me.Notes = text })
+}
+
+// editMeta applies edit to a mod's entry as one history operation, so undo reverts it.
+func (m *Manager) editMeta(ctx context.Context, command, mod string, edit func(me *ModEntry)) (MetaResult, error) {
+	var res MetaResult
+	err := m.Update(command, func(tx *Tx) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		st, err := m.LoadState()
+		if err != nil {
+			return err
+		}
+		id, err := m.Resolve(st, mod)
+		if err != nil {
+			return err
+		}
+		me := st.Mods[id]
+		before := strings.Join(me.Tags, ",") + "\x00" + me.Notes
+		edit(&me)
+		res = MetaResult{ID: id, Tags: me.Tags, Notes: me.Notes}
+		if res.Tags == nil {
+			res.Tags = []string{}
+		}
+		if strings.Join(me.Tags, ",")+"\x00"+me.Notes == before {
+			res.Unchanged = true
+			return nil
+		}
+		st.Mods[id] = me
+		return m.SaveState(st)
+	})
+	return res, err
+}
+
+// TagCounts returns how many mods carry each tag.
+func TagCounts(st State) map[string]int {
+	out := map[string]int{}
+	for _, me := range st.Mods {
+		for _, t := range me.Tags {
+			out[t]++
+		}
+	}
+	return out
+}
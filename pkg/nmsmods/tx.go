@@ -0,0 +1,136 @@
+package nmsmods
+
+import (
+	"time"
+
+	"nmsmods/internal/app"
+)
+
+// Tx is one locked operation. It collects store folders the operation moves to the trash
+// instead of deleting, so undo can bring them back.
+type Tx struct {
+	m       *Manager
+	command string
+	stores  []app.StoreChange
+}
+
+// Manager returns the Manager the transaction runs on.
+func (tx *Tx) Manager() *Manager { return tx.m }
+
+// Command is the text history records for the operation.
+func (tx *Tx) Command() string { return tx.command }
+
+// TrashStore moves a profile store folder into the trash and records it so undo can restore
+// it. A missing folder is not an error.
+func (tx *Tx) TrashStore(storeAbs, modID string) error {
+	if !fileExists(storeAbs) {
+		return nil
+	}
+	it, err := tx.m.Trash(storeAbs, app.TrashStore, modID, tx.command)
+	if err != nil {
+		return err
+	}
+	p := tx.m.paths
+	tx.stores = append(tx.stores, app.StoreChange{Store: app.RelToRoot(p, storeAbs), Trash: app.RelToRoot(p, it.PayloadPath(p))})
+	return nil
+}
+
+// Locked runs fn under the data directory's lock. It fails with ErrLockBusy instead of waiting.
+func (m *Manager) Locked(fn func() error) error {
+	l, err := app.AcquireLock(m.paths.Root)
+	if err != nil {
+		return err
+	}
+	defer l.Release()
+	return fn()
+}
+
+// Update runs fn under the lock and appends an operation to history.jsonl with before/after
+// snapshots of every mod entry fn changed. command is the text history shows for it.
+func (m *Manager) Update(command string, fn func(tx *Tx) error) error {
+	return m.Locked(func() error {
+		cfgBefore, _ := m.LoadConfig()
+		stBefore, err := m.LoadState()
+		if err != nil {
+			return err
+		}
+
+		tx := &Tx{m: m, command: command}
+		runErr := fn(tx)
+
+		cfgAfter, _ := m.LoadConfig()
+		stAfter, err := m.LoadState()
+		if err != nil {
+			if runErr != nil {
+				return runErr
+			}
+			return err
+		}
+
+		op := app.HistoryEntry{
+			Kind:          app.HistoryOp,
+			Command:       tx.command,
+			ProfileBefore: app.ActiveProfile(cfgBefore),
+			ProfileAfter:  app.ActiveProfile(cfgAfter),
+			Changes:       app.DiffModEntries(stBefore, stAfter),
+			Stores:        tx.stores,
+		}
+
+		// Stores created or replaced without going through TrashStore (fresh installs).
+		recorded := map[string]bool{}
+		for _, sc := range op.Stores {
+			recorded[sc.Store] = true
+		}
+		for _, c := range op.Changes {
+			if c.After == nil {
+				continue
+			}
+			for prof, pi := range c.After.Installations {
+				if !pi.Installed || pi.Store == "" || recorded[pi.Store] {
+					continue
+				}
+				var before app.ProfileInstall
+				if c.Before != nil {
+					before = c.Before.Installations[prof]
+				}
+				if before.Store == pi.Store && before.InstalledAt == pi.InstalledAt {
+					continue
+				}
+				recorded[pi.Store] = true
+				op.Stores = append(op.Stores, app.StoreChange{Store: pi.Store})
+			}
+		}
+
+		if len(op.Changes) == 0 && len(op.Stores) == 0 && op.ProfileBefore == op.ProfileAfter {
+			return runErr
+		}
+		if _, err := app.AppendHistory(m.paths, op); err != nil {
+			m.warnf("failed to record history: %v", err)
+		}
+		return runErr
+	})
+}
+
+// Kinds of trash items (TrashItem.Kind).
+const (
+	TrashKindStore    = app.TrashStore    // profile store folder
+	TrashKindDownload = app.TrashDownload // downloaded archive
+	TrashKindState    = app.TrashState    // state.json
+	TrashKindOther    = app.TrashOther
+)
+
+// Trash moves origin into the trash with metadata, then applies the configured retention.
+// Use it instead of os.RemoveAll for anything a user may want back (stores, downloads, state).
+// kind is one of the TrashKind constants.
+func (m *Manager) Trash(origin, kind, modID, command string) (TrashItem, error) {
+	it, err := app.MoveToTrash(m.paths, origin, app.TrashItem{Kind: kind, ModID: modID, Command: command})
+	if err != nil {
+		return it, err
+	}
+	if cfg, cerr := m.LoadConfig(); cerr == nil {
+		if _, perr := app.PruneTrash(m.paths, cfg.Trash, time.Now()); perr != nil {
+			m.warnf("trash retention failed: %v", perr)
+		}
+	}
+	return it, nil
+}
@@ -0,0 +1,223 @@
+package nmsmods
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"nmsmods/internal/app"
+	"nmsmods/internal/mods"
+)
+
+// UninstallOptions adjusts Uninstall and UninstallMods.
+type UninstallOptions struct {
+	// DryRun only plans the uninstall: nothing is undeployed, trashed or saved.
+	DryRun bool
+}
+
+// UninstallResult describes an Uninstall (or, with DryRun, what it would do). Untracked
+// folder removals only set Folder, RemovedPath and TrashID.
+type UninstallResult struct {
+	ID          string `json:"id,omitempty"`
+	Profile     string `json:"profile,omitempty"`
+	Folder      string `json:"folder"`
+	StorePath   string `json:"store_path,omitempty"` // moved to the trash
+	RemovedPath string `json:"removed_path,omitempty"`
+	TrashID     string `json:"trash_id,omitempty"` // trash item of an untracked folder
+	DryRun      bool   `json:"dry_run,omitempty"`
+	Game        *Game  `json:"-"`
+}
+
+// Uninstall removes a mod from the Manager's profile: the store folder goes to the trash and
+// the deployed folder leaves the game. target is a mod argument (see ResolveMod) or a folder
+// name in GAMEDATA/MODS; an untracked folder of that name goes to the trash too. A folder that
+// exists is never shadowed by a loose name match.
+func (m *Manager) Uninstall(ctx context.Context, target string, opts UninstallOptions) (UninstallResult, error) {
+	var res UninstallResult
+	err := m.Update(m.command("uninstall", target), func(tx *Tx) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		cfg, game, err := m.RequireGame()
+		if err != nil {
+			return err
+		}
+		if err := CheckGameNotRunning(); err != nil {
+			return err
+		}
+		profile, err := m.EnsureProfileDirs(*cfg)
+		if err != nil {
+			return err
+		}
+		st, err := m.LoadState()
+		if err != nil {
+			return err
+		}
+
+		trackedID := ""
+		if _, aerr := strconv.Atoi(target); aerr == nil {
+			if id, rerr := m.Resolve(st, target); rerr == nil {
+				trackedID = id
+			}
+		} else if _, ok := st.Mods[target]; ok {
+			trackedID = target
+		} else if ids, _ := m.modsInFolder(profile, target); len(ids) == 1 {
+			// A deployed folder name that belongs to a tracked mod.
+			trackedID = ids[0]
+		} else if !untrackedFolderExists(game, target) {
+			// nx:<mod id>, an id prefix or a display name.
+			if trackedID, err = m.Resolve(st, target); err != nil {
+				return err
+			}
+		}
+
+		if trackedID != "" {
+			if res, err = m.uninstallTracked(tx, *cfg, game, profile, &st, trackedID, opts.DryRun); err != nil {
+				return err
+			}
+			if opts.DryRun {
+				return nil
+			}
+			return m.SaveState(st)
+		}
+
+		// Otherwise the argument is an untracked folder name. It must still be a single segment.
+		folder, err := mods.SanitizeFolderName(target, target)
+		if err != nil {
+			return err
+		}
+		dest, err := mods.SafeJoinUnder(game.ModsDir, folder)
+		if err != nil {
+			return err
+		}
+		res = UninstallResult{Folder: folder, RemovedPath: dest, DryRun: opts.DryRun, Game: game}
+		if opts.DryRun {
+			return nil
+		}
+		if _, err := os.Stat(dest); os.IsNotExist(err) {
+			return fmt.Errorf("not found: %s", dest)
+		}
+		it, err := m.Trash(dest, app.TrashOther, "", tx.command)
+		if err != nil {
+			return err
+		}
+		res.TrashID = it.ID
+		return nil
+	})
+	return res, err
+}
+
+// UninstallMods uninstalls the mods sel selects as one operation with one history entry.
+// Nothing changes if a selected mod is not installed in the profile. When one mod fails, the
+// mods already uninstalled are saved and returned with the error.
+func (m *Manager) UninstallMods(ctx context.Context, sel Selector, opts UninstallOptions) ([]UninstallResult, error) {
+	out := []UninstallResult{}
+	err := m.Update(m.command("uninstall", sel.Args...), func(tx *Tx) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		cfg, game, err := m.RequireGame()
+		if err != nil {
+			return err
+		}
+		if err := CheckGameNotRunning(); err != nil {
+			return err
+		}
+		profile, err := m.EnsureProfileDirs(*cfg)
+		if err != nil {
+			return err
+		}
+		st, err := m.LoadState()
+		if err != nil {
+			return err
+		}
+		ids, err := m.Select(st, sel)
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			if pi := st.Mods[id].Installations[profile]; !pi.Installed || pi.Folder == "" {
+				return &NotInstalledError{ID: id, Profile: profile}
+			}
+		}
+
+		var runErr error
+		for _, id := range ids {
+			res, err := m.uninstallTracked(tx, *cfg, game, profile, &st, id, opts.DryRun)
+			if err != nil {
+				// Keep what was already done consistent in state (and in history for undo).
+				runErr = fmt.Errorf("%s: %w", id, err)
+				break
+			}
+			out = append(out, res)
+		}
+		if opts.DryRun || len(out) == 0 {
+			return runErr
+		}
+		if err := m.SaveState(st); err != nil {
+			return err
+		}
+		return runErr
+	})
+	return out, err
+}
+
+// uninstallTracked uninstalls a tracked mod from profile, updating st (the caller saves it).
+// With dryRun it only fills in the result.
+func (m *Manager) uninstallTracked(tx *Tx, cfg Config, game *Game, profile string, st *State, id string, dryRun bool) (UninstallResult, error) {
+	me := st.Mods[id]
+	pi, ok := me.Installations[profile]
+	if !ok || !pi.Installed || pi.Folder == "" {
+		return UninstallResult{}, &NotInstalledError{ID: id, Profile: profile}
+	}
+
+	storeAbs := m.abs(pi.Store)
+	res := UninstallResult{ID: id, Profile: profile, Folder: pi.Folder, StorePath: storeAbs, DryRun: dryRun, Game: game}
+	if dryRun {
+		return res, nil
+	}
+
+	// Undeploy first (so game state is clean even if store removal fails).
+	if err := m.UndeployInstall(cfg, game, id, profile, pi); err != nil {
+		return res, err
+	}
+
+	// Keep the store in the trash so `nmsmods undo` can bring it back.
+	if pi.Store != "" {
+		if err := tx.TrashStore(storeAbs, id); err != nil {
+			return res, err
+		}
+	}
+
+	pi.Installed = false
+	pi.Enabled = false
+	pi.DeployedPath = ""
+	pi.InstalledAt = ""
+	me.Installations[profile] = pi
+	if !app.IsInstalledInAnyProfile(me) {
+		me.Health = ""
+	}
+	st.Mods[id] = me
+	return res, nil
+}
+
+// modsInFolder returns ids installed into folder in a profile (indexed lookup on state.db).
+func (m *Manager) modsInFolder(profile, folder string) ([]string, error) {
+	s, err := app.OpenStateStore(m.paths)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Close()
+	return s.ByFolder(profile, folder)
+}
+
+// untrackedFolderExists reports whether target names a folder in GAMEDATA/MODS.
+func untrackedFolderExists(game *Game, target string) bool {
+	folder, err := mods.SanitizeFolderName(target, target)
+	if err != nil {
+		return false
+	}
+	dest, err := mods.SafeJoinUnder(game.ModsDir, folder)
+	return err == nil && fileExists(dest)
+}
@@ -0,0 +1,219 @@
+package nmsmods
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"nmsmods/internal/app"
+	"nmsmods/internal/nexus"
+)
+
+// UpdateStatus is the result of CheckUpdates for one Nexus-tracked mod.
+type UpdateStatus struct {
+	ID         string     `json:"id"`
+	Pinned     bool       `json:"pinned,omitempty"`
+	Current    *NexusInfo `json:"current,omitempty"`
+	Latest     *NexusInfo `json:"latest,omitempty"`
+	HasUpdate  bool       `json:"has_update"`
+	Reason     string     `json:"reason,omitempty"`
+	ModUpdated string     `json:"mod_updated_time,omitempty"`
+}
+
+// nexusClient returns a Nexus API client using the key in config.json (ErrNoAPIKey when unset).
+func (m *Manager) nexusClient() (*nexus.Client, error) {
+	cfg, err := m.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Nexus.APIKey == "" {
+		return nil, ErrNoAPIKey
+	}
+	return nexus.NewClient(cfg.Nexus.APIKey, "nmsmods", app.Version, nexus.WithLogger(m.log())), nil
+}
+
+// CheckUpdates compares Nexus-tracked mods (all, or the given ids/handles) with the files on
+// Nexus. It only reads metadata: Nexus downloads need a fresh nxm:// link. Per-mod API failures
+// are reported in Reason rather than failing the whole check.
+func (m *Manager) CheckUpdates(ctx context.Context, modArgs ...string) ([]UpdateStatus, error) {
+	client, err := m.nexusClient()
+	if err != nil {
+		return nil, err
+	}
+	st, err := m.LoadState()
+	if err != nil {
+		return nil, err
+	}
+
+	ids := SortedModIDs(st)
+	if len(modArgs) > 0 {
+		ids = ids[:0:0]
+		for _, a := range modArgs {
+			id, err := m.Resolve(st, a)
+			if err != nil {
+				return nil, err
+			}
+			ids = append(ids, id)
+		}
+	}
+
+	out := make([]UpdateStatus, 0, len(ids))
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		me := st.Mods[id]
+		if me.Nexus == nil {
+			continue
+		}
+		out = append(out, checkUpdate(ctx, client, id, me.Nexus))
+	}
+	m.recordUpdateCheck(out)
+	m.recordCategories(out)
+	return out, nil
+}
+
+// recordCategories fills in the Nexus mod category of mods that lack it (tracked before
+// categories were recorded). It is best-effort: a busy lock skips it until the next check.
+func (m *Manager) recordCategories(rows []UpdateStatus) {
+	err := m.Locked(func() error {
+		st, err := m.LoadState()
+		if err != nil {
+			return err
+		}
+		changed := false
+		for _, r := range rows {
+			me, ok := st.Mods[r.ID]
+			if !ok || me.Nexus == nil || r.Latest == nil || r.Latest.ModCategory == "" || me.Nexus.ModCategory != "" {
+				continue
+			}
+			ni := *me.Nexus
+			ni.ModCategoryID, ni.ModCategory = r.Latest.ModCategoryID, r.Latest.ModCategory
+			me.Nexus = &ni
+			st.Mods[r.ID] = me
+			changed = true
+		}
+		if !changed {
+			return nil
+		}
+		return m.SaveState(st)
+	})
+	if err != nil {
+		m.warnf("failed to record mod categories: %v", err)
+	}
+}
+
+// recordUpdateCheck keeps the results for --outdated selectors. Failed checks keep the
+// previous result.
+func (m *Manager) recordUpdateCheck(rows []UpdateStatus) {
+	uc, err := app.LoadUpdateCheck(m.paths)
+	if err != nil {
+		m.warnf("ignoring unreadable %s: %v", app.UpdateCheckPath(m.paths), err)
+	}
+	now := app.NowRFC3339()
+	for _, r := range rows {
+		if r.Latest == nil && !r.Pinned {
+			continue
+		}
+		e := app.UpdateCheckEntry{CheckedAt: now, HasUpdate: r.HasUpdate, Reason: r.Reason}
+		if r.Current != nil {
+			e.CurrentFileID = r.Current.FileID
+		}
+		if r.Latest != nil {
+			e.LatestFileID, e.LatestVersion = r.Latest.FileID, r.Latest.Version
+		}
+		uc.Mods[r.ID] = e
+	}
+	if err := app.SaveUpdateCheck(m.paths, uc); err != nil {
+		m.warnf("failed to record update check: %v", err)
+	}
+}
+
+func checkUpdate(ctx context.Context, client *nexus.Client, id string, cur *NexusInfo) UpdateStatus {
+	row := UpdateStatus{ID: id, Current: cur, Pinned: cur.Pinned}
+	if cur.Pinned {
+		row.Reason = "pinned"
+		return row
+	}
+
+	// Fetch latest mod + files (best-effort).
+	mod, err := client.GetMod(ctx, cur.GameDomain, cur.ModID)
+	category := ""
+	if err == nil && mod != nil {
+		row.ModUpdated = mod.UpdatedTime
+		if mod.CategoryID != 0 {
+			category, _ = client.ModCategory(ctx, cur.GameDomain, mod.CategoryID)
+		}
+	}
+
+	files, err := client.ListFiles(ctx, cur.GameDomain, cur.ModID)
+	if err != nil {
+		row.Reason = fmt.Sprintf("list_files_failed: %v", err)
+		return row
+	}
+	if len(files) == 0 {
+		row.Reason = "no_files"
+		return row
+	}
+
+	// Choose "latest" file heuristic:
+	// 1) Prefer IsPrimary true
+	// 2) Else prefer category MAIN (common Nexus convention)
+	// 3) Else newest uploaded_timestamp
+	// 4) Tiebreak: highest file_id
+	sort.Slice(files, func(i, j int) bool {
+		ai := files[i]
+		aj := files[j]
+		if ai.IsPrimary != aj.IsPrimary {
+			return ai.IsPrimary
+		}
+		aMain := ai.CategoryName == "MAIN"
+		bMain := aj.CategoryName == "MAIN"
+		if aMain != bMain {
+			return aMain
+		}
+		if ai.UploadedTimestamp != aj.UploadedTimestamp {
+			return ai.UploadedTimestamp > aj.UploadedTimestamp
+		}
+		return ai.FileID > aj.FileID
+	})
+	latest := files[0]
+
+	row.Latest = &NexusInfo{
+		GameDomain:        cur.GameDomain,
+		ModID:             cur.ModID,
+		FileID:            latest.FileID,
+		ModName:           cur.ModName,
+		FileName:          latest.FileName,
+		Version:           latest.Version,
+		CategoryName:      latest.CategoryName,
+		UploadedTimestamp: latest.UploadedTimestamp,
+		UploadedTime:      latest.UploadedTime,
+		ModUpdatedTime:    row.ModUpdated,
+		ModCategoryID:     cur.ModCategoryID,
+		ModCategory:       category,
+	}
+	if mod != nil && mod.CategoryID != 0 {
+		row.Latest.ModCategoryID = mod.CategoryID
+	}
+
+	// Determine update: file_id differs OR uploaded_timestamp newer.
+	if cur.FileID != 0 && latest.FileID != 0 && latest.FileID != cur.FileID {
+		row.HasUpdate = true
+		row.Reason = "file_id_changed"
+	}
+	if latest.UploadedTimestamp != 0 && cur.UploadedTimestamp != 0 && latest.UploadedTimestamp > cur.UploadedTimestamp {
+		row.HasUpdate = true
+		if row.Reason == "" {
+			row.Reason = "uploaded_timestamp_newer"
+		}
+	}
+	if latest.Version != "" && cur.Version != "" && latest.Version != cur.Version {
+		// Keep as supplementary signal
+		if !row.HasUpdate {
+			row.HasUpdate = true
+			row.Reason = "version_changed"
+		}
+	}
+	return row
+}